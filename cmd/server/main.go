@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
+	"os"
+	grpcapi "scam-detection-backend/internal/api/grpc"
+	"scam-detection-backend/internal/api/middleware"
 	routes "scam-detection-backend/internal/api/routers"
+	"scam-detection-backend/internal/cache"
 	"scam-detection-backend/internal/config"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
 	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/oidc"
+	"scam-detection-backend/internal/passwordpolicy"
 	"scam-detection-backend/internal/repository"
 	"scam-detection-backend/internal/services"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -34,27 +45,62 @@ import (
 // @securityDefinitions.apikey CookieAuth
 // @in cookie
 // @name access_token
-// @description JWT токен в HttpOnly cookie
+// @description JWT токен в HttpOnly cookie. Для POST/PUT/DELETE нужен заголовок X-CSRF-Token со значением cookie csrf_token
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT токен в формате "Bearer <token>", CSRF токен не требуется
 
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+
 	db, err := config.Connect(&cfg.Database)
 	if err != nil {
 		log.Fatal("Не удалось подключиться к БД:", err)
 	}
 
-	if err := db.AutoMigrate(&models.User{}, &models.Check{}, &models.CheckDetail{}, &models.UserSessions{}); err != nil {
-		log.Fatal("Ошибка миграций:", err)
+	// Реплики, стартующие одновременно, применяют миграции по очереди под
+	// advisory lock; остальные увидят их уже применёнными
+	if cfg.Database.MigrateOnStart {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			log.Fatal("Ошибка миграций:", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatal("Ошибка миграций:", err)
+		}
 	}
 
 	userRepo := repository.NewUserRepository(db)
 	sessionRepo := repository.NewSessionRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	oidcStateRepo := repository.NewOIDCStateRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	checkRepo := repository.NewCheckRepository(db)
+	evaluationRepo := repository.NewModelEvaluationRepository(db)
+	reanalysisJobRepo := repository.NewReanalysisJobRepository(db)
+	batchJobRepo := repository.NewBatchJobRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+
+	for _, username := range cfg.Server.AdminUsernames {
+		if err := userRepo.SetRoleByUsername(username, models.RoleAdmin); err != nil {
+			log.Printf("Не удалось назначить администратора %s: %v", username, err)
+		}
+	}
 
-	userService := services.NewUserService(userRepo)
+	auditService := services.NewAuditService(auditRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, auditService)
 
 	sessionService, err := services.NewSessionService(
 		sessionRepo,
+		auditService,
 		cfg.JWT.Secret,
 		cfg.JWT.AccessTokenDuration,
 		cfg.JWT.RefreshTokenDuration,
@@ -63,7 +109,151 @@ func main() {
 		log.Fatal("Не удалось создать session service:", err)
 	}
 
-	authService := services.NewAuthService(userRepo, sessionService)
+	accountGuard := services.NewAccountGuard(userRepo)
+
+	userService, err := services.NewUserService(userRepo, sessionService, accountGuard, cfg.Account.DeletionGracePeriod)
+	if err != nil {
+		log.Fatal("Не удалось создать user service:", err)
+	}
+
+	purgeJob, err := services.NewAccountPurgeJob(userRepo, auditService, cfg.Account.PurgeInterval)
+	if err != nil {
+		log.Fatal("Не удалось создать задачу удаления аккаунтов:", err)
+	}
+	go purgeJob.Run(context.Background())
+
+	var breachedList *passwordpolicy.BreachedList
+	if cfg.Password.BreachedListPath != "" {
+		breachedList, err = passwordpolicy.LoadBreachedList(cfg.Password.BreachedListPath)
+		if err != nil {
+			log.Fatal("Не удалось загрузить список утёкших паролей:", err)
+		}
+		log.Printf("Загружено %d хешей утёкших паролей", breachedList.Size())
+	}
+
+	passwordPolicy := passwordpolicy.New(passwordpolicy.Config{
+		MinLength:      cfg.Password.MinLength,
+		MaxLength:      cfg.Password.MaxLength,
+		RequireLower:   cfg.Password.RequireLower,
+		RequireUpper:   cfg.Password.RequireUpper,
+		RequireDigit:   cfg.Password.RequireDigit,
+		RequireSymbol:  cfg.Password.RequireSymbol,
+		MinStrength:    cfg.Password.MinStrength,
+		ForbidIdentity: cfg.Password.ForbidIdentity,
+	}, breachedList)
+
+	authService := services.NewAuthService(userRepo, sessionService, accountGuard, passwordPolicy, auditService)
+
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDC.Providers))
+	for _, p := range cfg.OIDC.Providers {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}))
+	}
+
+	oidcService := services.NewOIDCService(oidcProviders, userRepo, identityRepo, oidcStateRepo, sessionService, auditService)
+
+	mlClientConfig := func(baseURL string) mlclient.Config {
+		return mlclient.Config{
+			BaseURL:            baseURL,
+			TextTimeout:        cfg.ML.TextTimeout,
+			BatchTimeout:       cfg.ML.BatchTimeout,
+			HealthTimeout:      cfg.ML.HealthTimeout,
+			MaxRetries:         cfg.ML.MaxRetries,
+			RetryBaseDelay:     cfg.ML.RetryBaseDelay,
+			BreakerThreshold:   cfg.ML.BreakerFailureThreshold,
+			BreakerOpenTimeout: cfg.ML.BreakerOpenTimeout,
+			MaxIdleConns:       cfg.ML.MaxIdleConns,
+			MaxConnsPerHost:    cfg.ML.MaxConnsPerHost,
+		}
+	}
+
+	modelEndpoints := make([]mlclient.ModelEndpoint, 0, len(cfg.ML.Models))
+	modelNames := make([]string, 0, len(cfg.ML.Models))
+	for _, m := range cfg.ML.Models {
+		modelEndpoints = append(modelEndpoints, mlclient.ModelEndpoint{
+			Name:      m.Name,
+			Weight:    m.Weight,
+			Languages: m.Languages,
+			Analyzer:  mlclient.NewMLClient(mlClientConfig(m.URL)),
+		})
+		modelNames = append(modelNames, m.Name)
+	}
+	analyzer := mlclient.NewEnsemble(modelEndpoints)
+
+	var candidate mlclient.Analyzer
+	if cfg.ML.Candidate.URL != "" {
+		candidate = mlclient.NewMLClient(mlClientConfig(cfg.ML.Candidate.URL))
+	}
+
+	// Кэш используется только для пользовательских проверок: повторный
+	// анализ и пересчёт деградировавших проверок всегда обращаются к модели
+	var resultCache cache.Cache
+	switch cfg.Cache.Backend {
+	case "memory":
+		resultCache = cache.NewMemory(cfg.Cache.MemorySize)
+	case "redis":
+		resultCache = cache.NewRedis(cache.RedisConfig{
+			Addr:     cfg.Cache.RedisAddr,
+			Password: cfg.Cache.RedisPassword,
+			DB:       cfg.Cache.RedisDB,
+			Prefix:   cfg.Cache.KeyPrefix,
+		})
+	case "none", "":
+	default:
+		log.Printf("Неизвестный CACHE_BACKEND=%s, кэш результатов отключён", cfg.Cache.Backend)
+	}
+
+	liveAnalyzer := mlclient.Analyzer(analyzer)
+	if resultCache != nil {
+		liveAnalyzer = services.NewCachedAnalyzer(analyzer, resultCache, cfg.Cache.TTL)
+		if candidate != nil {
+			candidate = services.NewCachedAnalyzer(candidate, resultCache, cfg.Cache.TTL)
+		}
+	}
+
+	experiment := services.NewModelExperiment(evaluationRepo, liveAnalyzer, candidate, services.ExperimentConfig{
+		LiveModel:      strings.Join(modelNames, "+"),
+		CandidateModel: cfg.ML.Candidate.Name,
+		TrafficPercent: cfg.ML.Candidate.TrafficPercent,
+		Shadow:         cfg.ML.Candidate.Shadow,
+		ShadowTimeout:  cfg.ML.BatchTimeout,
+	})
+
+	// Уведомления пользователей (WebSocket) в пределах одного экземпляра
+	eventHub := events.NewHub(0)
+
+	webhookService := services.NewWebhookService(webhookRepo, services.WebhookConfig{
+		Timeout:              cfg.Webhook.Timeout,
+		MaxAttempts:          cfg.Webhook.MaxAttempts,
+		RetryBaseDelay:       cfg.Webhook.RetryBaseDelay,
+		RetryMaxDelay:        cfg.Webhook.RetryMaxDelay,
+		PollInterval:         cfg.Webhook.PollInterval,
+		AllowPrivateNetworks: cfg.Webhook.AllowPrivateNetworks,
+	})
+	eventHub.AddListener(webhookService)
+	go webhookService.Run(context.Background())
+
+	batchService := services.NewBatchService(batchJobRepo, experiment, eventHub, services.BatchConfig{
+		MaxTexts:         cfg.Batch.MaxTexts,
+		ChunkSize:        cfg.Batch.ChunkSize,
+		Workers:          cfg.Batch.Workers,
+		DegradeOnFailure: cfg.ML.DegradeOnFailure,
+	})
+	batchService.RecoverInterrupted(context.Background())
+
+	analysisService := services.NewAnalysisService(checkRepo, experiment, batchService, eventHub, cfg.ML.DegradeOnFailure)
+
+	reanalysisService := services.NewReanalysisService(checkRepo, reanalysisJobRepo, analyzer, eventHub)
+	reanalysisService.RecoverInterrupted(context.Background())
+
+	rescoreJob := services.NewRescoreJob(checkRepo, analyzer, eventHub, cfg.ML.RescoreInterval)
+	go rescoreJob.Run(context.Background())
 
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -72,17 +262,30 @@ func main() {
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"},
+		AllowOrigins:     cfg.Server.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.CSRFHeaderName},
 		AllowCredentials: true,
 		MaxAge:           12 * 3600,
 	}))
 
-	routes.SetupRoutes(r, db, authService, userService)
+	routes.SetupRoutes(r, cfg, analyzer, experiment, analysisService, batchService, reanalysisService, authService, userService, oidcService, auditService, eventHub, webhookService, apiKeyService)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	if cfg.Server.GRPCEnabled {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Server.GRPCPort))
+		if err != nil {
+			log.Fatal("Не удалось запустить gRPC сервер:", err)
+		}
+		grpcServer := grpcapi.NewServer(authService, apiKeyService, analysisService)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal("gRPC сервер остановлен:", err)
+			}
+		}()
+	}
+
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	if err := r.Run(addr); err != nil {
 		log.Fatal("Не удалось запустить сервер:", err)
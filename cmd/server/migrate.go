@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"scam-detection-backend/internal/config"
+	"scam-detection-backend/internal/migrations"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Использование: server migrate <команда>
+
+  up             применить все новые миграции
+  down [N]       откатить N последних миграций (по умолчанию 1)
+  status         показать применённые и ожидающие миграции
+  create <имя>   создать пустую пару up/down файлов в ` + migrations.Dir + `
+`
+
+// runMigrate выполняет подкоманду migrate и завершает процесс при ошибке.
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+		up, down, err := migrations.Create(migrations.Dir, args[1])
+		if err != nil {
+			log.Fatal("Не удалось создать миграцию:", err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	db, err := config.Connect(&cfg.Database)
+	if err != nil {
+		log.Fatal("Не удалось подключиться к БД:", err)
+	}
+	migrator, err := newMigrator(db)
+	if err != nil {
+		log.Fatal("Ошибка миграций:", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("применена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal("Ошибка миграций:", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("новых миграций нет")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatal("Некорректное число миграций: ", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("откачена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal("Ошибка отката:", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("Ошибка миграций:", err)
+		}
+		for _, s := range statuses {
+			applied := "ожидает"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+func newMigrator(db *gorm.DB) (*migrations.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(sqlDB)
+}
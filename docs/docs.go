@@ -30,7 +30,7 @@ const docTemplate = `{
                         "CookieAuth": []
                     }
                 ],
-                "description": "Полностью удаляет аккаунт текущего пользователя",
+                "description": "Помечает аккаунт на удаление и отзывает все сессии. До указанной даты аккаунт можно восстановить, просто войдя в систему; после неё все данные пользователя удаляются безвозвратно",
                 "produces": [
                     "application/json"
                 ],
@@ -42,10 +42,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.DeleteAccountResponse"
                         }
                     },
                     "401": {
@@ -69,98 +66,196 @@ const docTemplate = `{
                 }
             }
         },
-        "/analysis/batch": {
-            "post": {
+        "/admin/audit-events": {
+            "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "CookieAuth": []
                     }
                 ],
-                "description": "Отправляет несколько текстов в ML сервис для анализа",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает события безопасности всех пользователей с фильтрами и пагинацией",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "analysis"
+                    "admin"
                 ],
-                "summary": "Пакетный анализ текстов",
+                "summary": "Журнал аудита (админ)",
                 "parameters": [
                     {
-                        "description": "Список текстов для анализа",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.AnalyzeBatchRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип события",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP адрес",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный анализ",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/mlclient.BatchTextAnalysisResponse"
+                            "$ref": "#/definitions/handlers.AuditEventsResponse"
                         }
                     },
                     "400": {
-                        "description": "Невалидный запрос",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Ошибка ML сервиса",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/analysis/health": {
+        "/admin/models/comparison": {
             "get": {
-                "description": "Возвращает статус ML сервиса и информацию о модели",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Отчёт по теневому прогону и A/B тесту: доля совпадений вердиктов, распределения оценок и точность по отзывам пользователей для каждой модели",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "analysis"
+                    "admin"
+                ],
+                "summary": "Сравнение live и candidate моделей (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339), по умолчанию 7 дней назад",
+                        "name": "since",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Проверка здоровья ML сервиса",
                 "responses": {
                     "200": {
-                        "description": "ML сервис здоров",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ModelComparisonReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/mlclient.HealthResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "ML сервис недоступен",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/analysis/history": {
+        "/admin/reanalysis": {
             "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "CookieAuth": []
                     }
                 ],
-                "description": "Возвращает список всех проверок текущего пользователя с пагинацией",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "analysis"
+                    "admin"
                 ],
-                "summary": "История проверок пользователя",
+                "summary": "Задачи повторного анализа (админ)",
                 "parameters": [
                     {
                         "type": "integer",
@@ -179,50 +274,77 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список проверок",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.CheckHistoryResponse"
+                            "$ref": "#/definitions/handlers.ReanalysisJobsResponse"
                         }
                     },
                     "401": {
-                        "description": "Не авторизован",
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Ошибка БД",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/analysis/history/{id}": {
-            "delete": {
+            },
+            "post": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "CookieAuth": []
                     }
                 ],
-                "description": "Удаляет проверку из истории пользователя",
+                "description": "Пересчитывает отобранные фильтром исторические проверки текущей моделью. Новая оценка сохраняется как следующая ревизия проверки, прежние остаются в истории. Задача выполняется в фоне, прогресс доступен по GET /admin/reanalysis/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "analysis"
+                    "admin"
                 ],
-                "summary": "Удалить проверку",
+                "summary": "Запустить повторный анализ (админ)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID проверки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Фильтр проверок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckFilter"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Успешно удалено",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReanalysisJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -231,72 +353,121 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Не авторизован",
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "403": {
-                        "description": "Нет доступа",
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Ошибка БД",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/analysis/stats": {
+        "/admin/reanalysis/{id}": {
             "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "CookieAuth": []
                     }
                 ],
-                "description": "Возвращает агрегированную статистику по проверкам пользователя",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "analysis"
+                    "admin"
+                ],
+                "summary": "Статус повторного анализа (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Статистика пользователя",
                 "responses": {
                     "200": {
-                        "description": "Статистика",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReanalysisJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "401": {
-                        "description": "Не авторизован",
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Ошибка БД",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
-                        }
-                    }
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "/analysis/text": {
+        "/analysis/batch": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Отправляет текст в ML сервис для определения, является ли он фишинговым/мошенническим",
+                "description": "Анализирует пакет текстов (до BATCH_MAX_TEXTS). Пакет не больше BATCH_CHUNK_SIZE обрабатывается сразу и возвращает результат для каждого текста; если часть текстов не обработана, ответ имеет статус 207. Пакет большего размера разбивается на части, которые обрабатываются в фоне, а ответ 202 содержит задачу, прогресс которой доступен через /analysis/batch/{id}. При недоступности ML сервиса части пакета оцениваются правилами, как /analysis/text в деградированном режиме",
                 "consumes": [
                     "application/json"
                 ],
@@ -306,23 +477,35 @@ const docTemplate = `{
                 "tags": [
                     "analysis"
                 ],
-                "summary": "Анализ текста на мошенничество",
+                "summary": "Пакетный анализ текстов",
                 "parameters": [
                     {
-                        "description": "Текст для анализа",
+                        "description": "Список текстов для анализа",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.AnalyzeTextRequest"
+                            "$ref": "#/definitions/handlers.AnalyzeBatchRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный анализ",
+                        "description": "Все тексты обработаны",
                         "schema": {
-                            "$ref": "#/definitions/mlclient.TextAnalysisResponse"
+                            "$ref": "#/definitions/handlers.BatchAnalysisResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Пакет принят в обработку",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchJob"
+                        }
+                    },
+                    "207": {
+                        "description": "Часть текстов не обработана, причины в items",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchAnalysisResponse"
                         }
                     },
                     "400": {
@@ -332,179 +515,444 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Ошибка ML сервиса",
+                        "description": "Ошибка ML сервиса, не обработан ни один текст",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.BatchAnalysisResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "ML сервис временно недоступен (circuit breaker разомкнут)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchAnalysisResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Таймаут ML сервиса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchAnalysisResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Аутентификация пользователя и возврат JWT токенов в cookies",
-                "consumes": [
-                    "application/json"
+        "/analysis/batch/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает статус пакета и число обработанных, неудавшихся и оценённых только правилами текстов. Проверки пакета появляются в истории по мере обработки частей",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "analysis"
                 ],
-                "summary": "Вход в систему",
+                "summary": "Прогресс пакетного анализа",
                 "parameters": [
                     {
-                        "description": "Логин и пароль",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID пакета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Пакет",
                         "schema": {
-                            "$ref": "#/definitions/handlers.AuthResponse"
+                            "$ref": "#/definitions/models.BatchJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Не авторизован",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пакет не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/logout": {
-            "post": {
-                "description": "Удаляет все сессии пользователя и очищает cookies",
+        "/analysis/batch/{id}/items": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает результат каждого текста пакета, включая неудавшиеся (status failed и error_code), в порядке записи. Для получения новых результатов передайте after из next_after предыдущего ответа",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "analysis"
+                ],
+                "summary": "Результаты пакетного анализа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пакета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "seq последнего полученного результата",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Количество результатов (максимум 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Выход из системы",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Результаты",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.BatchItemsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пакет не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "Обновляет access и refresh токены используя refresh токен из cookie",
+        "/analysis/batch/{id}/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events: событие item с результатом каждого текста по мере обработки (id события - seq результата), progress при изменении счётчиков пакета и summary с итоговым состоянием пакета, после которого поток закрывается. При переподключении с заголовком Last-Event-ID (или параметром last_event_id) поток продолжается с первого не полученного результата",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "auth"
+                    "analysis"
+                ],
+                "summary": "Поток результатов пакетного анализа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пакета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "seq последнего полученного результата",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "То же, что Last-Event-ID, для клиентов без доступа к заголовкам",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Обновление токенов",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Поток событий item, progress и summary",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.BatchItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Не авторизован",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пакет не найден",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Создаёт нового пользователя и возвращает JWT токены в cookies",
-                "consumes": [
-                    "application/json"
-                ],
+        "/analysis/health": {
+            "get": {
+                "description": "Возвращает статус ML сервиса, информацию о модели и состояние circuit breaker клиента. Для ансамбля моделей в models возвращается состояние каждой модели, а общий статус degraded означает, что отвечает только часть моделей",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
-                ],
-                "summary": "Регистрация нового пользователя",
-                "parameters": [
-                    {
-                        "description": "Данные для регистрации",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.RegisterRequest"
-                        }
-                    }
+                    "analysis"
                 ],
+                "summary": "Проверка здоровья ML сервиса",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "ML сервис здоров",
                         "schema": {
-                            "$ref": "#/definitions/handlers.AuthResponse"
+                            "$ref": "#/definitions/handlers.MLHealthResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "503": {
+                        "description": "ML сервис недоступен",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.MLHealthResponse"
                         }
                     }
                 }
             }
         },
-        "/profile": {
+        "/analysis/history": {
             "get": {
                 "security": [
                     {
-                        "CookieAuth": []
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает данные авторизованного пользователя",
+                "description": "Возвращает проверки текущего пользователя с фильтрами, сортировкой и пагинацией. При сортировке по created_at ответ содержит next_cursor и prev_cursor: курсор передаётся в cursor вместо page, и страницы не сдвигаются при появлении новых проверок. Параметр q включает полнотекстовый поиск (русский и английский): в snippet возвращается фрагмент текста с найденными словами в \u003cmark\u003e, остальной текст экранирован",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "analysis"
                 ],
-                "summary": "Получить профиль текущего пользователя",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
+                "summary": "История проверок пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Строка поиска (синтаксис websearch: фразы в кавычках, -исключение, or)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "critical"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Уровни опасности",
+                        "name": "danger_level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "processing",
+                                "completed",
+                                "failed"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Статусы",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип содержимого",
+                        "name": "content_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Минимальная оценка опасности",
+                        "name": "min_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Максимальная оценка опасности",
+                        "name": "max_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверки с отзывом (true) или без него (false)",
+                        "name": "has_feedback",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "danger_score",
+                            "processing_time",
+                            "relevance"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка; по умолчанию relevance при поиске, иначе created_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор next_cursor или prev_cursor из предыдущего ответа; заменяет page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Считать общее число проверок (total)",
+                        "name": "with_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список проверок",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analysis/history/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет проверку из истории пользователя вместе с её деталями и ревизиями",
+                "tags": [
+                    "analysis"
+                ],
+                "summary": "Удалить проверку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешно удалено",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -512,24 +960,41 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Проверка не найдена",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/analysis/history/{id}/feedback": {
+            "post": {
                 "security": [
                     {
-                        "CookieAuth": []
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет username и/или email текущего пользователя",
+                "description": "Сохраняет мнение пользователя о том, было ли сообщение мошенническим. Используется для оценки точности моделей",
                 "consumes": [
                     "application/json"
                 ],
@@ -537,29 +1002,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "analysis"
                 ],
-                "summary": "Обновить профиль",
+                "summary": "Оценка результата проверки",
                 "parameters": [
                     {
-                        "description": "Данные для обновления",
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Оценка пользователя",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateProfileRequest"
+                            "$ref": "#/definitions/handlers.CheckFeedbackRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "Оценка сохранена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -567,277 +1033,2800 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Не авторизован",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Проверка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.AnalyzeBatchRequest": {
-            "type": "object",
-            "required": [
-                "texts"
-            ],
-            "properties": {
-                "texts": {
-                    "type": "array",
-                    "maxItems": 100,
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
+        },
+        "/analysis/history/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все ревизии оценки проверки: исходную и полученные при повторном анализе новыми версиями модели",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analysis"
+                ],
+                "summary": "История оценок проверки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ревизии",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CheckRevision"
+                            }
+                        }
                     },
-                    "example": [
-                        "[\"Вы выиграли приз\"",
-                        " \"Привет",
-                        " как дела?\"]"
-                    ]
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Проверка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handlers.AnalyzeTextRequest": {
-            "type": "object",
-            "required": [
-                "text"
-            ],
-            "properties": {
-                "text": {
-                    "type": "string",
-                    "maxLength": 5000,
-                    "minLength": 1,
-                    "example": "Срочно! Ваш аккаунт заблокирован"
+        "/analysis/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает агрегированную статистику по проверкам пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analysis"
+                ],
+                "summary": "Статистика пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Статистика",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analysis/stats/timeseries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число проверок по уровням опасности, средний риск и среднее время обработки для каждого интервала периода, а также самые частые правила и домены ссылок в опасных проверках",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analysis"
+                ],
+                "summary": "Статистика по интервалам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339), по умолчанию 30 дней назад",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339), по умолчанию сейчас",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Интервал",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "Часовой пояс IANA, в котором начинаются интервалы",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика",
+                        "schema": {
+                            "$ref": "#/definitions/models.StatsTimeSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analysis/text": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправляет текст в ML сервис для определения, является ли он фишинговым/мошенническим. Если ML сервис недоступен и включён ML_DEGRADE_ON_FAILURE, текст оценивается только правилами: ответ содержит \"degraded\": true и заниженную уверенность, а проверка позже пересчитывается моделью",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analysis"
+                ],
+                "summary": "Анализ текста на мошенничество",
+                "parameters": [
+                    {
+                        "description": "Текст для анализа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AnalyzeTextRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный анализ",
+                        "schema": {
+                            "$ref": "#/definitions/mlclient.TextAnalysisResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка ML сервиса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "ML сервис временно недоступен (circuit breaker разомкнут)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Таймаут ML сервиса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает ключи текущего пользователя без секретов: только начало ключа и время последнего использования",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Список ключей API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Выпускает ключ для gRPC API: клиент передаёт его в метаданных x-api-key вместо токена доступа. Ключ действует от имени пользователя и возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Создать ключ API",
+                "parameters": [
+                    {
+                        "description": "Название ключа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Удаляет ключ: запросы с ним сразу перестают проходить",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Отозвать ключ API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/csrf": {
+            "get": {
+                "description": "Выдаёт новый double-submit токен в cookie csrf_token; его значение нужно передавать в заголовке X-CSRF-Token для POST/PUT/DELETE запросов с cookie-аутентификацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Получить CSRF токен",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CSRFTokenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Аутентификация пользователя и возврат JWT токенов в cookies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход в систему",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Удаляет все сессии пользователя и очищает cookies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход из системы",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/providers": {
+            "get": {
+                "description": "Возвращает имена настроенных внешних провайдеров входа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Список OIDC провайдеров",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OIDCProvidersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "Обменивает code на токены, проверяет id_token, находит/привязывает/создаёт пользователя и выдаёт JWT токены в cookies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Callback OIDC провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/login": {
+            "get": {
+                "description": "Перенаправляет на страницу авторизации OIDC провайдера (authorization code + PKCE)",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход через внешнего провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Обновляет access и refresh токены используя refresh токен из cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токенов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Создаёт нового пользователя и возвращает JWT токены в cookies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация нового пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Пароль не соответствует политике",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasswordPolicyErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Открывает WebSocket, в который сервер отправляет JSON события {type, data, time}: check.status (проверка перешла в processing/completed/failed), check.feedback (сохранена оценка пользователя), check.revised (новая ревизия после повторного анализа администратором), check.rescored (проверка, оценённая только правилами, пересчитана моделью), batch.progress и batch.completed (прогресс пакетного анализа), а также ping раз в 30 секунд. Браузер аутентифицируется cookie access_token, соединение принимается только с доверенного Origin. Если клиент не успевает читать события, сервер закрывает соединение; после переподключения стоит перечитать историю",
+                "tags": [
+                    "events"
+                ],
+                "summary": "События пользователя (WebSocket)",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Недоверенный Origin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает данные авторизованного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Получить профиль текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Обновляет username и/или email текущего пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Обновить профиль",
+                "parameters": [
+                    {
+                        "description": "Данные для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/identities": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает внешние OIDC аккаунты текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Привязанные внешние аккаунты",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserIdentity"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/identities/{provider}": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает URL авторизации у провайдера; после callback внешний аккаунт привязывается к текущему пользователю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Привязать внешний аккаунт",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OIDCAuthURLResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Удаляет привязку внешнего OIDC аккаунта от текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Отвязать внешний аккаунт",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/password": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Проверяет текущий пароль, применяет политику паролей к новому, отзывает все сессии и выдаёт новые токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Смена пароля",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Пароль не соответствует политике",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasswordPolicyErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает события безопасности текущего пользователя (входы, обновления токенов, изменения профиля) с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Журнал безопасности",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuditEventsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает вебхуки текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Список вебхуков",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Подписывает URL на события проверок: check.completed, check.high_risk (завершённая проверка с уровнем high или critical), check.feedback. Запросы подписываются заголовком X-Webhook-Signature: sha256=HMAC-SHA256(secret, \"\u003cX-Webhook-Timestamp\u003e.\u003cтело\u003e\") в hex. Секрет возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Создать вебхук",
+                "parameters": [
+                    {
+                        "description": "URL и события",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Удаляет вебхук вместе с журналом доставок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Возвращает доставки вебхука, новые первыми. Статусы: pending - ждёт отправки или повтора, succeeded - получатель ответил 2xx, dead - попытки исчерпаны",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Сбрасывает счётчик попыток и ставит доставку в очередь, в том числе из состояния dead",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Повторить доставку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID доставки",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Сразу отправляет на URL вебхука событие webhook.test и возвращает результат попытки. При неудаче доставка повторяется по обычному расписанию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Отправить тестовое событие",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.AnalyzeBatchRequest": {
+            "type": "object",
+            "required": [
+                "texts"
+            ],
+            "properties": {
+                "texts": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"Вы выиграли приз\"",
+                        " \"Привет",
+                        " как дела?\"]"
+                    ]
+                }
+            }
+        },
+        "handlers.AnalyzeTextRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "maxLength": 5000,
+                    "minLength": 1,
+                    "example": "Срочно! Ваш аккаунт заблокирован"
+                }
+            }
+        },
+        "handlers.AuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "handlers.BatchAnalysisResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "check_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "degraded": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItemResult"
+                    }
+                },
+                "partial": {
+                    "type": "boolean"
+                },
+                "predictions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlclient.PredictionResult"
+                    }
+                },
+                "processing_time": {
+                    "type": "number"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode: ml_unavailable, ml_timeout, ml_error, missing_prediction,\nstorage_error или canceled",
+                    "type": "string",
+                    "example": "missing_prediction"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "prediction": {
+                    "$ref": "#/definitions/mlclient.PredictionResult"
+                },
+                "status": {
+                    "description": "Status: ok, degraded (оценено только правилами) или failed",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "handlers.BatchItemsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItem"
+                    }
+                },
+                "next_after": {
+                    "description": "NextAfter - значение after для следующей страницы",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CSRFTokenResponse": {
+            "type": "object",
+            "properties": {
+                "csrf_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password"
+            ],
+            "properties": {
+                "cur_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CheckFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "is_scam"
+            ],
+            "properties": {
+                "is_scam": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.CheckHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Check"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "key": {
+                    "description": "Key показывается только один раз",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Secret показывается только один раз",
+                    "type": "string"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/models.Webhook"
+                }
+            }
+        },
+        "handlers.DeleteAccountResponse": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MLHealthResponse": {
+            "type": "object",
+            "properties": {
+                "circuit_breaker": {
+                    "$ref": "#/definitions/mlclient.BreakerStats"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "model_loaded": {
+                    "type": "boolean"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlclient.ModelHealth"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.OIDCAuthURLResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.OIDCProvidersResponse": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.PasswordPolicyErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/passwordpolicy.Violation"
+                    }
+                }
+            }
+        },
+        "handlers.ReanalysisJobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReanalysisJob"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "minLength": 3
+                }
+            }
+        },
+        "handlers.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "minLength": 3
+                }
+            }
+        },
+        "handlers.WebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "mlclient.BreakerStats": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "retry_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlclient.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "model_loaded": {
+                    "type": "boolean"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlclient.ModelHealth": {
+            "type": "object",
+            "properties": {
+                "circuit_breaker": {
+                    "$ref": "#/definitions/mlclient.BreakerStats"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "health": {
+                    "$ref": "#/definitions/mlclient.HealthResponse"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "mlclient.ModelPrediction": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "is_scam": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "mlclient.PredictionResult": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "Cached - результат взят из кэша, ML сервис не вызывался",
+                    "type": "boolean"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "is_scam": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "model_name": {
+                    "description": "Модель, выдавшая предсказание. Если ML сервис не возвращает их в ответе,\nклиент подставляет данные из /health",
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "models": {
+                    "description": "Models заполняет Ensemble: ответы отдельных моделей",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlclient.ModelPrediction"
+                    }
+                }
+            }
+        },
+        "mlclient.TextAnalysisResponse": {
+            "type": "object",
+            "properties": {
+                "model_name": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "prediction": {
+                    "$ref": "#/definitions/mlclient.PredictionResult"
+                },
+                "processing_time": {
+                    "type": "number"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchItem": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "check_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "danger_level": {
+                    "type": "string"
+                },
+                "danger_score": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "seq": {
+                    "description": "Seq - номер результата в пакете в порядке записи, с 1. Части пакета\nполучают номера под блокировкой задачи, поэтому результат с большим\nSeq никогда не становится виден раньше результата с меньшим: Seq\nслужит курсором для получения результатов по мере обработки. ID\nвыдаются до фиксации транзакции и таким курсором быть не могут.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "degraded": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Check": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "batch_index": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "danger_level": {
+                    "type": "string"
+                },
+                "danger_score": {
+                    "type": "number"
+                },
+                "degraded": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "processing_time_ms": {
+                    "description": "ProcessingTime у проверок пакета - оценка: время запроса части к ML\nсервису, делённое на число её текстов",
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "snippet": {
+                    "description": "Snippet - фрагмент текста с найденными словами в \u003cmark\u003e, заполняется\nтолько при полнотекстовом поиске",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_verdict": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CheckFilter": {
+            "type": "object",
+            "properties": {
+                "danger_level": {
+                    "description": "DangerLevel - только проверки с указанным уровнем опасности",
+                    "type": "string"
+                },
+                "exclude_model_version": {
+                    "description": "ExcludeModelVersion - пропустить проверки, уже оценённые этой версией",
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "model_version": {
+                    "description": "ModelVersion - только проверки, оценённые этой версией модели",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CheckRevision": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "danger_level": {
+                    "type": "string"
+                },
+                "danger_score": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "integer"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.AuthResponse": {
+        "models.ModelComparisonReport": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string"
+                "agreement_rate": {
+                    "type": "number"
                 },
-                "refresh_token": {
+                "agreements": {
+                    "type": "integer"
+                },
+                "paired_checks": {
+                    "type": "integer"
+                },
+                "since": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.VariantReport"
+                    }
                 }
             }
         },
-        "handlers.CheckHistoryResponse": {
+        "models.ReanalysisJob": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Check"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "limit": {
+                "created_by": {
                     "type": "integer"
                 },
-                "page": {
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model_name": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "processed": {
                     "type": "integer"
                 },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
                 "total": {
                     "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.ErrorResponse": {
+        "models.ScoreBucket": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "number"
                 }
             }
         },
-        "handlers.LoginRequest": {
+        "models.StatsBucket": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "average_processing_time": {
+                    "type": "number"
                 },
-                "username": {
+                "average_risk_score": {
+                    "type": "number"
+                },
+                "by_level": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "start": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.RegisterRequest": {
+        "models.StatsCount": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "count": {
+                    "type": "integer"
                 },
-                "username": {
-                    "type": "string",
-                    "minLength": 3
+                "value": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.UpdateProfileRequest": {
+        "models.StatsTimeSeries": {
             "type": "object",
             "properties": {
-                "email": {
+                "bucket": {
                     "type": "string"
                 },
-                "username": {
-                    "type": "string",
-                    "minLength": 3
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatsBucket"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "top_indicators": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatsCount"
+                    }
+                },
+                "top_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatsCount"
+                    }
                 }
             }
         },
-        "mlclient.BatchTextAnalysisResponse": {
+        "models.User": {
             "type": "object",
             "properties": {
-                "predictions": {
+                "checks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/mlclient.PredictionResult"
+                        "$ref": "#/definitions/models.Check"
                     }
                 },
-                "processing_time": {
-                    "type": "number"
+                "created_at": {
+                    "type": "string"
                 },
-                "success": {
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "has_password": {
                     "type": "boolean"
-                }
-            }
-        },
-        "mlclient.HealthResponse": {
-            "type": "object",
-            "properties": {
-                "model_loaded": {
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
                     "type": "boolean"
                 },
-                "model_name": {
+                "role": {
                     "type": "string"
                 },
-                "status": {
+                "updated_at": {
                     "type": "string"
                 },
-                "version": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "mlclient.PredictionResult": {
+        "models.UserIdentity": {
             "type": "object",
             "properties": {
-                "confidence": {
-                    "type": "number"
+                "created_at": {
+                    "type": "string"
                 },
-                "is_scam": {
-                    "type": "boolean"
+                "email": {
+                    "type": "string"
                 },
-                "label": {
+                "id": {
+                    "type": "integer"
+                },
+                "provider": {
                     "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "mlclient.TextAnalysisResponse": {
+        "models.UserStats": {
             "type": "object",
             "properties": {
-                "prediction": {
-                    "$ref": "#/definitions/mlclient.PredictionResult"
+                "average_processing_time": {
+                    "type": "integer"
                 },
-                "processing_time": {
+                "average_risk_score": {
                     "type": "number"
                 },
-                "success": {
-                    "type": "boolean"
+                "by_level": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "dangerous_count": {
+                    "type": "integer"
+                },
+                "safe_count": {
+                    "type": "integer"
+                },
+                "suspicious_count": {
+                    "type": "integer"
+                },
+                "total_analyses": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Check": {
+        "models.VariantReport": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
+                "avg_danger_score": {
+                    "type": "number"
                 },
-                "content_type": {
-                    "type": "string"
+                "avg_latency_ms": {
+                    "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
+                "errors": {
+                    "type": "integer"
                 },
-                "danger_level": {
-                    "type": "string"
+                "evaluations": {
+                    "type": "integer"
                 },
-                "danger_score": {
+                "feedback_accuracy": {
                     "type": "number"
                 },
-                "id": {
+                "feedback_count": {
                     "type": "integer"
                 },
-                "processing_time_ms": {
+                "model": {
+                    "type": "string"
+                },
+                "scam_rate": {
+                    "type": "number"
+                },
+                "score_histogram": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScoreBucket"
+                    }
+                },
+                "served": {
                     "type": "integer"
                 },
-                "status": {
+                "variant": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "title": {
+                "events": {
+                    "description": "Events - события через запятую",
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 },
+                "url": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.User": {
+        "models.WebhookDelivery": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Check"
-                    }
+                "attempts": {
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "email": {
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_type": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "username": {
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "passwordpolicy.Violation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
                     "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT токен в формате \"Bearer \u003ctoken\u003e\", CSRF токен не требуется",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "CookieAuth": {
-            "description": "JWT токен в HttpOnly cookie",
+            "description": "JWT токен в HttpOnly cookie. Для POST/PUT/DELETE нужен заголовок X-CSRF-Token со значением cookie csrf_token",
             "type": "apiKey",
             "name": "access_token",
             "in": "cookie"
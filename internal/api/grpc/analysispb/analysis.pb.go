@@ -0,0 +1,1024 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: analysis/v1/analysis.proto
+
+package analysispb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Prediction struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Label        string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Confidence   float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	IsScam       bool                   `protobuf:"varint,3,opt,name=is_scam,json=isScam,proto3" json:"is_scam,omitempty"`
+	ModelName    string                 `protobuf:"bytes,4,opt,name=model_name,json=modelName,proto3" json:"model_name,omitempty"`
+	ModelVersion string                 `protobuf:"bytes,5,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	// cached - результат взят из кэша, ML сервис не вызывался
+	Cached        bool `protobuf:"varint,6,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prediction) Reset() {
+	*x = Prediction{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prediction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prediction) ProtoMessage() {}
+
+func (x *Prediction) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prediction.ProtoReflect.Descriptor instead.
+func (*Prediction) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Prediction) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Prediction) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Prediction) GetIsScam() bool {
+	if x != nil {
+		return x.IsScam
+	}
+	return false
+}
+
+func (x *Prediction) GetModelName() string {
+	if x != nil {
+		return x.ModelName
+	}
+	return ""
+}
+
+func (x *Prediction) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+func (x *Prediction) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type AnalyzeTextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeTextRequest) Reset() {
+	*x = AnalyzeTextRequest{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeTextRequest) ProtoMessage() {}
+
+func (x *AnalyzeTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeTextRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeTextRequest) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AnalyzeTextRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type AnalyzeTextResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CheckId    uint64                 `protobuf:"varint,1,opt,name=check_id,json=checkId,proto3" json:"check_id,omitempty"`
+	Success    bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Degraded   bool                   `protobuf:"varint,3,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	Prediction *Prediction            `protobuf:"bytes,4,opt,name=prediction,proto3" json:"prediction,omitempty"`
+	// processing_time - время анализа в секундах
+	ProcessingTime float64 `protobuf:"fixed64,5,opt,name=processing_time,json=processingTime,proto3" json:"processing_time,omitempty"`
+	DangerScore    float64 `protobuf:"fixed64,6,opt,name=danger_score,json=dangerScore,proto3" json:"danger_score,omitempty"`
+	DangerLevel    string  `protobuf:"bytes,7,opt,name=danger_level,json=dangerLevel,proto3" json:"danger_level,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AnalyzeTextResponse) Reset() {
+	*x = AnalyzeTextResponse{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeTextResponse) ProtoMessage() {}
+
+func (x *AnalyzeTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeTextResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeTextResponse) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AnalyzeTextResponse) GetCheckId() uint64 {
+	if x != nil {
+		return x.CheckId
+	}
+	return 0
+}
+
+func (x *AnalyzeTextResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AnalyzeTextResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *AnalyzeTextResponse) GetPrediction() *Prediction {
+	if x != nil {
+		return x.Prediction
+	}
+	return nil
+}
+
+func (x *AnalyzeTextResponse) GetProcessingTime() float64 {
+	if x != nil {
+		return x.ProcessingTime
+	}
+	return 0
+}
+
+func (x *AnalyzeTextResponse) GetDangerScore() float64 {
+	if x != nil {
+		return x.DangerScore
+	}
+	return 0
+}
+
+func (x *AnalyzeTextResponse) GetDangerLevel() string {
+	if x != nil {
+		return x.DangerLevel
+	}
+	return ""
+}
+
+type AnalyzeBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Texts         []string               `protobuf:"bytes,1,rep,name=texts,proto3" json:"texts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeBatchRequest) Reset() {
+	*x = AnalyzeBatchRequest{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeBatchRequest) ProtoMessage() {}
+
+func (x *AnalyzeBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeBatchRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeBatchRequest) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnalyzeBatchRequest) GetTexts() []string {
+	if x != nil {
+		return x.Texts
+	}
+	return nil
+}
+
+type BatchItemResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// status: ok, degraded (оценено только правилами) или failed
+	Status     string      `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	CheckId    uint64      `protobuf:"varint,3,opt,name=check_id,json=checkId,proto3" json:"check_id,omitempty"`
+	Prediction *Prediction `protobuf:"bytes,4,opt,name=prediction,proto3" json:"prediction,omitempty"`
+	// error_code: ml_unavailable, ml_timeout, ml_error, missing_prediction,
+	// storage_error или canceled
+	ErrorCode     string `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItemResult) Reset() {
+	*x = BatchItemResult{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItemResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItemResult) ProtoMessage() {}
+
+func (x *BatchItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItemResult.ProtoReflect.Descriptor instead.
+func (*BatchItemResult) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchItemResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchItemResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BatchItemResult) GetCheckId() uint64 {
+	if x != nil {
+		return x.CheckId
+	}
+	return 0
+}
+
+func (x *BatchItemResult) GetPrediction() *Prediction {
+	if x != nil {
+		return x.Prediction
+	}
+	return nil
+}
+
+func (x *BatchItemResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *BatchItemResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type AnalyzeBatchResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	BatchId        uint64                 `protobuf:"varint,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Success        bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Partial        bool                   `protobuf:"varint,3,opt,name=partial,proto3" json:"partial,omitempty"`
+	Degraded       bool                   `protobuf:"varint,4,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	Items          []*BatchItemResult     `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	ProcessingTime float64                `protobuf:"fixed64,6,opt,name=processing_time,json=processingTime,proto3" json:"processing_time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AnalyzeBatchResponse) Reset() {
+	*x = AnalyzeBatchResponse{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeBatchResponse) ProtoMessage() {}
+
+func (x *AnalyzeBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeBatchResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeBatchResponse) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AnalyzeBatchResponse) GetBatchId() uint64 {
+	if x != nil {
+		return x.BatchId
+	}
+	return 0
+}
+
+func (x *AnalyzeBatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AnalyzeBatchResponse) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *AnalyzeBatchResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *AnalyzeBatchResponse) GetItems() []*BatchItemResult {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *AnalyzeBatchResponse) GetProcessingTime() float64 {
+	if x != nil {
+		return x.ProcessingTime
+	}
+	return 0
+}
+
+type ListHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHistoryRequest) Reset() {
+	*x = ListHistoryRequest{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHistoryRequest) ProtoMessage() {}
+
+func (x *ListHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ListHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListHistoryRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Check struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title            string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	ContentType      string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Content          string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	DangerScore      float64                `protobuf:"fixed64,5,opt,name=danger_score,json=dangerScore,proto3" json:"danger_score,omitempty"`
+	DangerLevel      string                 `protobuf:"bytes,6,opt,name=danger_level,json=dangerLevel,proto3" json:"danger_level,omitempty"`
+	Status           string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Degraded         bool                   `protobuf:"varint,8,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	UserVerdict      *bool                  `protobuf:"varint,9,opt,name=user_verdict,json=userVerdict,proto3,oneof" json:"user_verdict,omitempty"`
+	ModelName        string                 `protobuf:"bytes,10,opt,name=model_name,json=modelName,proto3" json:"model_name,omitempty"`
+	ModelVersion     string                 `protobuf:"bytes,11,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	Revision         int32                  `protobuf:"varint,12,opt,name=revision,proto3" json:"revision,omitempty"`
+	ProcessingTimeMs int32                  `protobuf:"varint,13,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Check) Reset() {
+	*x = Check{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Check) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Check) ProtoMessage() {}
+
+func (x *Check) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Check.ProtoReflect.Descriptor instead.
+func (*Check) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Check) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Check) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Check) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Check) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Check) GetDangerScore() float64 {
+	if x != nil {
+		return x.DangerScore
+	}
+	return 0
+}
+
+func (x *Check) GetDangerLevel() string {
+	if x != nil {
+		return x.DangerLevel
+	}
+	return ""
+}
+
+func (x *Check) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Check) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *Check) GetUserVerdict() bool {
+	if x != nil && x.UserVerdict != nil {
+		return *x.UserVerdict
+	}
+	return false
+}
+
+func (x *Check) GetModelName() string {
+	if x != nil {
+		return x.ModelName
+	}
+	return ""
+}
+
+func (x *Check) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+func (x *Check) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Check) GetProcessingTimeMs() int32 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *Check) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Check) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*Check               `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHistoryResponse) Reset() {
+	*x = ListHistoryResponse{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHistoryResponse) ProtoMessage() {}
+
+func (x *ListHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHistoryResponse.ProtoReflect.Descriptor instead.
+func (*ListHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListHistoryResponse) GetChecks() []*Check {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *ListHistoryResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListHistoryResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListHistoryResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{9}
+}
+
+type Stats struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	TotalAnalyses           int64                  `protobuf:"varint,1,opt,name=total_analyses,json=totalAnalyses,proto3" json:"total_analyses,omitempty"`
+	SafeCount               int64                  `protobuf:"varint,2,opt,name=safe_count,json=safeCount,proto3" json:"safe_count,omitempty"`
+	SuspiciousCount         int64                  `protobuf:"varint,3,opt,name=suspicious_count,json=suspiciousCount,proto3" json:"suspicious_count,omitempty"`
+	DangerousCount          int64                  `protobuf:"varint,4,opt,name=dangerous_count,json=dangerousCount,proto3" json:"dangerous_count,omitempty"`
+	AverageRiskScore        float64                `protobuf:"fixed64,5,opt,name=average_risk_score,json=averageRiskScore,proto3" json:"average_risk_score,omitempty"`
+	AverageProcessingTimeMs int64                  `protobuf:"varint,6,opt,name=average_processing_time_ms,json=averageProcessingTimeMs,proto3" json:"average_processing_time_ms,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_analysis_v1_analysis_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_analysis_v1_analysis_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_analysis_v1_analysis_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Stats) GetTotalAnalyses() int64 {
+	if x != nil {
+		return x.TotalAnalyses
+	}
+	return 0
+}
+
+func (x *Stats) GetSafeCount() int64 {
+	if x != nil {
+		return x.SafeCount
+	}
+	return 0
+}
+
+func (x *Stats) GetSuspiciousCount() int64 {
+	if x != nil {
+		return x.SuspiciousCount
+	}
+	return 0
+}
+
+func (x *Stats) GetDangerousCount() int64 {
+	if x != nil {
+		return x.DangerousCount
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageRiskScore() float64 {
+	if x != nil {
+		return x.AverageRiskScore
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageProcessingTimeMs() int64 {
+	if x != nil {
+		return x.AverageProcessingTimeMs
+	}
+	return 0
+}
+
+var File_analysis_v1_analysis_proto protoreflect.FileDescriptor
+
+const file_analysis_v1_analysis_proto_rawDesc = "" +
+	"\n" +
+	"\x1aanalysis/v1/analysis.proto\x12\x19scamdetection.analysis.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb7\x01\n" +
+	"\n" +
+	"Prediction\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\x12\x17\n" +
+	"\ais_scam\x18\x03 \x01(\bR\x06isScam\x12\x1d\n" +
+	"\n" +
+	"model_name\x18\x04 \x01(\tR\tmodelName\x12#\n" +
+	"\rmodel_version\x18\x05 \x01(\tR\fmodelVersion\x12\x16\n" +
+	"\x06cached\x18\x06 \x01(\bR\x06cached\"(\n" +
+	"\x12AnalyzeTextRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"\x9c\x02\n" +
+	"\x13AnalyzeTextResponse\x12\x19\n" +
+	"\bcheck_id\x18\x01 \x01(\x04R\acheckId\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x1a\n" +
+	"\bdegraded\x18\x03 \x01(\bR\bdegraded\x12E\n" +
+	"\n" +
+	"prediction\x18\x04 \x01(\v2%.scamdetection.analysis.v1.PredictionR\n" +
+	"prediction\x12'\n" +
+	"\x0fprocessing_time\x18\x05 \x01(\x01R\x0eprocessingTime\x12!\n" +
+	"\fdanger_score\x18\x06 \x01(\x01R\vdangerScore\x12!\n" +
+	"\fdanger_level\x18\a \x01(\tR\vdangerLevel\"+\n" +
+	"\x13AnalyzeBatchRequest\x12\x14\n" +
+	"\x05texts\x18\x01 \x03(\tR\x05texts\"\xd6\x01\n" +
+	"\x0fBatchItemResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x19\n" +
+	"\bcheck_id\x18\x03 \x01(\x04R\acheckId\x12E\n" +
+	"\n" +
+	"prediction\x18\x04 \x01(\v2%.scamdetection.analysis.v1.PredictionR\n" +
+	"prediction\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xec\x01\n" +
+	"\x14AnalyzeBatchResponse\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\x04R\abatchId\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\apartial\x18\x03 \x01(\bR\apartial\x12\x1a\n" +
+	"\bdegraded\x18\x04 \x01(\bR\bdegraded\x12@\n" +
+	"\x05items\x18\x05 \x03(\v2*.scamdetection.analysis.v1.BatchItemResultR\x05items\x12'\n" +
+	"\x0fprocessing_time\x18\x06 \x01(\x01R\x0eprocessingTime\">\n" +
+	"\x12ListHistoryRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xa1\x04\n" +
+	"\x05Check\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12!\n" +
+	"\fdanger_score\x18\x05 \x01(\x01R\vdangerScore\x12!\n" +
+	"\fdanger_level\x18\x06 \x01(\tR\vdangerLevel\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1a\n" +
+	"\bdegraded\x18\b \x01(\bR\bdegraded\x12&\n" +
+	"\fuser_verdict\x18\t \x01(\bH\x00R\vuserVerdict\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"model_name\x18\n" +
+	" \x01(\tR\tmodelName\x12#\n" +
+	"\rmodel_version\x18\v \x01(\tR\fmodelVersion\x12\x1a\n" +
+	"\brevision\x18\f \x01(\x05R\brevision\x12,\n" +
+	"\x12processing_time_ms\x18\r \x01(\x05R\x10processingTimeMs\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\x0f\n" +
+	"\r_user_verdict\"\x8f\x01\n" +
+	"\x13ListHistoryResponse\x128\n" +
+	"\x06checks\x18\x01 \x03(\v2 .scamdetection.analysis.v1.CheckR\x06checks\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x11\n" +
+	"\x0fGetStatsRequest\"\x8c\x02\n" +
+	"\x05Stats\x12%\n" +
+	"\x0etotal_analyses\x18\x01 \x01(\x03R\rtotalAnalyses\x12\x1d\n" +
+	"\n" +
+	"safe_count\x18\x02 \x01(\x03R\tsafeCount\x12)\n" +
+	"\x10suspicious_count\x18\x03 \x01(\x03R\x0fsuspiciousCount\x12'\n" +
+	"\x0fdangerous_count\x18\x04 \x01(\x03R\x0edangerousCount\x12,\n" +
+	"\x12average_risk_score\x18\x05 \x01(\x01R\x10averageRiskScore\x12;\n" +
+	"\x1aaverage_processing_time_ms\x18\x06 \x01(\x03R\x17averageProcessingTimeMs2\xa8\x04\n" +
+	"\x0fAnalysisService\x12l\n" +
+	"\vAnalyzeText\x12-.scamdetection.analysis.v1.AnalyzeTextRequest\x1a..scamdetection.analysis.v1.AnalyzeTextResponse\x12o\n" +
+	"\fAnalyzeBatch\x12..scamdetection.analysis.v1.AnalyzeBatchRequest\x1a/.scamdetection.analysis.v1.AnalyzeBatchResponse\x12n\n" +
+	"\rAnalyzeStream\x12-.scamdetection.analysis.v1.AnalyzeTextRequest\x1a*.scamdetection.analysis.v1.BatchItemResult(\x010\x01\x12l\n" +
+	"\vListHistory\x12-.scamdetection.analysis.v1.ListHistoryRequest\x1a..scamdetection.analysis.v1.ListHistoryResponse\x12X\n" +
+	"\bGetStats\x12*.scamdetection.analysis.v1.GetStatsRequest\x1a .scamdetection.analysis.v1.StatsB@Z>scam-detection-backend/internal/api/grpc/analysispb;analysispbb\x06proto3"
+
+var (
+	file_analysis_v1_analysis_proto_rawDescOnce sync.Once
+	file_analysis_v1_analysis_proto_rawDescData []byte
+)
+
+func file_analysis_v1_analysis_proto_rawDescGZIP() []byte {
+	file_analysis_v1_analysis_proto_rawDescOnce.Do(func() {
+		file_analysis_v1_analysis_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_analysis_v1_analysis_proto_rawDesc), len(file_analysis_v1_analysis_proto_rawDesc)))
+	})
+	return file_analysis_v1_analysis_proto_rawDescData
+}
+
+var file_analysis_v1_analysis_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_analysis_v1_analysis_proto_goTypes = []any{
+	(*Prediction)(nil),            // 0: scamdetection.analysis.v1.Prediction
+	(*AnalyzeTextRequest)(nil),    // 1: scamdetection.analysis.v1.AnalyzeTextRequest
+	(*AnalyzeTextResponse)(nil),   // 2: scamdetection.analysis.v1.AnalyzeTextResponse
+	(*AnalyzeBatchRequest)(nil),   // 3: scamdetection.analysis.v1.AnalyzeBatchRequest
+	(*BatchItemResult)(nil),       // 4: scamdetection.analysis.v1.BatchItemResult
+	(*AnalyzeBatchResponse)(nil),  // 5: scamdetection.analysis.v1.AnalyzeBatchResponse
+	(*ListHistoryRequest)(nil),    // 6: scamdetection.analysis.v1.ListHistoryRequest
+	(*Check)(nil),                 // 7: scamdetection.analysis.v1.Check
+	(*ListHistoryResponse)(nil),   // 8: scamdetection.analysis.v1.ListHistoryResponse
+	(*GetStatsRequest)(nil),       // 9: scamdetection.analysis.v1.GetStatsRequest
+	(*Stats)(nil),                 // 10: scamdetection.analysis.v1.Stats
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_analysis_v1_analysis_proto_depIdxs = []int32{
+	0,  // 0: scamdetection.analysis.v1.AnalyzeTextResponse.prediction:type_name -> scamdetection.analysis.v1.Prediction
+	0,  // 1: scamdetection.analysis.v1.BatchItemResult.prediction:type_name -> scamdetection.analysis.v1.Prediction
+	4,  // 2: scamdetection.analysis.v1.AnalyzeBatchResponse.items:type_name -> scamdetection.analysis.v1.BatchItemResult
+	11, // 3: scamdetection.analysis.v1.Check.created_at:type_name -> google.protobuf.Timestamp
+	11, // 4: scamdetection.analysis.v1.Check.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 5: scamdetection.analysis.v1.ListHistoryResponse.checks:type_name -> scamdetection.analysis.v1.Check
+	1,  // 6: scamdetection.analysis.v1.AnalysisService.AnalyzeText:input_type -> scamdetection.analysis.v1.AnalyzeTextRequest
+	3,  // 7: scamdetection.analysis.v1.AnalysisService.AnalyzeBatch:input_type -> scamdetection.analysis.v1.AnalyzeBatchRequest
+	1,  // 8: scamdetection.analysis.v1.AnalysisService.AnalyzeStream:input_type -> scamdetection.analysis.v1.AnalyzeTextRequest
+	6,  // 9: scamdetection.analysis.v1.AnalysisService.ListHistory:input_type -> scamdetection.analysis.v1.ListHistoryRequest
+	9,  // 10: scamdetection.analysis.v1.AnalysisService.GetStats:input_type -> scamdetection.analysis.v1.GetStatsRequest
+	2,  // 11: scamdetection.analysis.v1.AnalysisService.AnalyzeText:output_type -> scamdetection.analysis.v1.AnalyzeTextResponse
+	5,  // 12: scamdetection.analysis.v1.AnalysisService.AnalyzeBatch:output_type -> scamdetection.analysis.v1.AnalyzeBatchResponse
+	4,  // 13: scamdetection.analysis.v1.AnalysisService.AnalyzeStream:output_type -> scamdetection.analysis.v1.BatchItemResult
+	8,  // 14: scamdetection.analysis.v1.AnalysisService.ListHistory:output_type -> scamdetection.analysis.v1.ListHistoryResponse
+	10, // 15: scamdetection.analysis.v1.AnalysisService.GetStats:output_type -> scamdetection.analysis.v1.Stats
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_analysis_v1_analysis_proto_init() }
+func file_analysis_v1_analysis_proto_init() {
+	if File_analysis_v1_analysis_proto != nil {
+		return
+	}
+	file_analysis_v1_analysis_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_analysis_v1_analysis_proto_rawDesc), len(file_analysis_v1_analysis_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_analysis_v1_analysis_proto_goTypes,
+		DependencyIndexes: file_analysis_v1_analysis_proto_depIdxs,
+		MessageInfos:      file_analysis_v1_analysis_proto_msgTypes,
+	}.Build()
+	File_analysis_v1_analysis_proto = out.File
+	file_analysis_v1_analysis_proto_goTypes = nil
+	file_analysis_v1_analysis_proto_depIdxs = nil
+}
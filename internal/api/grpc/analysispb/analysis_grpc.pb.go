@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: analysis/v1/analysis.proto
+
+package analysispb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AnalysisService_AnalyzeText_FullMethodName   = "/scamdetection.analysis.v1.AnalysisService/AnalyzeText"
+	AnalysisService_AnalyzeBatch_FullMethodName  = "/scamdetection.analysis.v1.AnalysisService/AnalyzeBatch"
+	AnalysisService_AnalyzeStream_FullMethodName = "/scamdetection.analysis.v1.AnalysisService/AnalyzeStream"
+	AnalysisService_ListHistory_FullMethodName   = "/scamdetection.analysis.v1.AnalysisService/ListHistory"
+	AnalysisService_GetStats_FullMethodName      = "/scamdetection.analysis.v1.AnalysisService/GetStats"
+)
+
+// AnalysisServiceClient is the client API for AnalysisService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AnalysisService - анализ текстов, история и статистика проверок текущего
+// пользователя. Пользователь определяется по access токену в metadata
+// "authorization: Bearer <token>".
+type AnalysisServiceClient interface {
+	// AnalyzeText анализирует один текст. Ошибки ML сервиса возвращаются
+	// статусами UNAVAILABLE (circuit breaker разомкнут), DEADLINE_EXCEEDED и
+	// INTERNAL; при включённом ML_DEGRADE_ON_FAILURE вместо ошибки
+	// возвращается оценка правилами с degraded = true.
+	AnalyzeText(ctx context.Context, in *AnalyzeTextRequest, opts ...grpc.CallOption) (*AnalyzeTextResponse, error)
+	// AnalyzeBatch синхронно анализирует пакет (до BATCH_MAX_TEXTS текстов) и
+	// возвращает результат для каждого текста, включая неудавшиеся.
+	AnalyzeBatch(ctx context.Context, in *AnalyzeBatchRequest, opts ...grpc.CallOption) (*AnalyzeBatchResponse, error)
+	// AnalyzeStream анализирует тексты по мере поступления и отвечает на
+	// каждый в порядке получения. Ошибка анализа одного текста не закрывает
+	// поток: результат приходит со status = "failed" и error_code.
+	AnalyzeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AnalyzeTextRequest, BatchItemResult], error)
+	ListHistory(ctx context.Context, in *ListHistoryRequest, opts ...grpc.CallOption) (*ListHistoryResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+}
+
+type analysisServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAnalysisServiceClient(cc grpc.ClientConnInterface) AnalysisServiceClient {
+	return &analysisServiceClient{cc}
+}
+
+func (c *analysisServiceClient) AnalyzeText(ctx context.Context, in *AnalyzeTextRequest, opts ...grpc.CallOption) (*AnalyzeTextResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeTextResponse)
+	err := c.cc.Invoke(ctx, AnalysisService_AnalyzeText_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analysisServiceClient) AnalyzeBatch(ctx context.Context, in *AnalyzeBatchRequest, opts ...grpc.CallOption) (*AnalyzeBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeBatchResponse)
+	err := c.cc.Invoke(ctx, AnalysisService_AnalyzeBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analysisServiceClient) AnalyzeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AnalyzeTextRequest, BatchItemResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AnalysisService_ServiceDesc.Streams[0], AnalysisService_AnalyzeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AnalyzeTextRequest, BatchItemResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AnalysisService_AnalyzeStreamClient = grpc.BidiStreamingClient[AnalyzeTextRequest, BatchItemResult]
+
+func (c *analysisServiceClient) ListHistory(ctx context.Context, in *ListHistoryRequest, opts ...grpc.CallOption) (*ListHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHistoryResponse)
+	err := c.cc.Invoke(ctx, AnalysisService_ListHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analysisServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, AnalysisService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AnalysisServiceServer is the server API for AnalysisService service.
+// All implementations must embed UnimplementedAnalysisServiceServer
+// for forward compatibility.
+//
+// AnalysisService - анализ текстов, история и статистика проверок текущего
+// пользователя. Пользователь определяется по access токену в metadata
+// "authorization: Bearer <token>".
+type AnalysisServiceServer interface {
+	// AnalyzeText анализирует один текст. Ошибки ML сервиса возвращаются
+	// статусами UNAVAILABLE (circuit breaker разомкнут), DEADLINE_EXCEEDED и
+	// INTERNAL; при включённом ML_DEGRADE_ON_FAILURE вместо ошибки
+	// возвращается оценка правилами с degraded = true.
+	AnalyzeText(context.Context, *AnalyzeTextRequest) (*AnalyzeTextResponse, error)
+	// AnalyzeBatch синхронно анализирует пакет (до BATCH_MAX_TEXTS текстов) и
+	// возвращает результат для каждого текста, включая неудавшиеся.
+	AnalyzeBatch(context.Context, *AnalyzeBatchRequest) (*AnalyzeBatchResponse, error)
+	// AnalyzeStream анализирует тексты по мере поступления и отвечает на
+	// каждый в порядке получения. Ошибка анализа одного текста не закрывает
+	// поток: результат приходит со status = "failed" и error_code.
+	AnalyzeStream(grpc.BidiStreamingServer[AnalyzeTextRequest, BatchItemResult]) error
+	ListHistory(context.Context, *ListHistoryRequest) (*ListHistoryResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	mustEmbedUnimplementedAnalysisServiceServer()
+}
+
+// UnimplementedAnalysisServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAnalysisServiceServer struct{}
+
+func (UnimplementedAnalysisServiceServer) AnalyzeText(context.Context, *AnalyzeTextRequest) (*AnalyzeTextResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeText not implemented")
+}
+func (UnimplementedAnalysisServiceServer) AnalyzeBatch(context.Context, *AnalyzeBatchRequest) (*AnalyzeBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeBatch not implemented")
+}
+func (UnimplementedAnalysisServiceServer) AnalyzeStream(grpc.BidiStreamingServer[AnalyzeTextRequest, BatchItemResult]) error {
+	return status.Errorf(codes.Unimplemented, "method AnalyzeStream not implemented")
+}
+func (UnimplementedAnalysisServiceServer) ListHistory(context.Context, *ListHistoryRequest) (*ListHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHistory not implemented")
+}
+func (UnimplementedAnalysisServiceServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedAnalysisServiceServer) mustEmbedUnimplementedAnalysisServiceServer() {}
+func (UnimplementedAnalysisServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeAnalysisServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AnalysisServiceServer will
+// result in compilation errors.
+type UnsafeAnalysisServiceServer interface {
+	mustEmbedUnimplementedAnalysisServiceServer()
+}
+
+func RegisterAnalysisServiceServer(s grpc.ServiceRegistrar, srv AnalysisServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAnalysisServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AnalysisService_ServiceDesc, srv)
+}
+
+func _AnalysisService_AnalyzeText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeTextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).AnalyzeText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalysisService_AnalyzeText_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).AnalyzeText(ctx, req.(*AnalyzeTextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalysisService_AnalyzeBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).AnalyzeBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalysisService_AnalyzeBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).AnalyzeBatch(ctx, req.(*AnalyzeBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalysisService_AnalyzeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AnalysisServiceServer).AnalyzeStream(&grpc.GenericServerStream[AnalyzeTextRequest, BatchItemResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AnalysisService_AnalyzeStreamServer = grpc.BidiStreamingServer[AnalyzeTextRequest, BatchItemResult]
+
+func _AnalysisService_ListHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).ListHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalysisService_ListHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).ListHistory(ctx, req.(*ListHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalysisService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalysisService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AnalysisService_ServiceDesc is the grpc.ServiceDesc for AnalysisService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AnalysisService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scamdetection.analysis.v1.AnalysisService",
+	HandlerType: (*AnalysisServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeText",
+			Handler:    _AnalysisService_AnalyzeText_Handler,
+		},
+		{
+			MethodName: "AnalyzeBatch",
+			Handler:    _AnalysisService_AnalyzeBatch_Handler,
+		},
+		{
+			MethodName: "ListHistory",
+			Handler:    _AnalysisService_ListHistory_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _AnalysisService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AnalyzeStream",
+			Handler:       _AnalysisService_AnalyzeStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "analysis/v1/analysis.proto",
+}
@@ -0,0 +1,120 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/services"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userIDKey struct{}
+
+// Health проверяется балансировщиками без токена
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// apiKeyMetadata - metadata с ключом доступа
+const apiKeyMetadata = "x-api-key"
+
+type tokenValidator interface {
+	ValidateToken(token string) (uint, error)
+}
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (uint, error)
+}
+
+// authenticator проверяет ключ доступа или access токен вызова.
+type authenticator struct {
+	tokens  tokenValidator
+	apiKeys apiKeyAuthenticator
+}
+
+func unaryAuthInterceptor(auth authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := auth.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(auth authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := auth.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate проверяет ключ доступа из metadata "x-api-key" или access
+// токен из "authorization: Bearer <token>" так же, как AuthMiddleware
+// проверяет заголовок Authorization.
+func (a authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if keys := md.Get(apiKeyMetadata); len(keys) > 0 {
+		userID, err := a.apiKeys.Authenticate(ctx, strings.TrimSpace(keys[0]))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				return nil, status.Error(codes.Unauthenticated, "невалидный ключ")
+			}
+			return nil, status.Error(codes.Unavailable, "не удалось проверить ключ")
+		}
+		return context.WithValue(ctx, userIDKey{}, userID), nil
+	}
+
+	var token string
+	for _, value := range md.Get("authorization") {
+		scheme, rest, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(rest) != "" {
+			token = strings.TrimSpace(rest)
+			break
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "токен не найден")
+	}
+
+	userID, err := a.tokens.ValidateToken(token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrAccountPendingDeletion):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, services.ErrAccountCheckFailed):
+			return nil, status.Error(codes.Unavailable, services.ErrAccountCheckFailed.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, "невалидный токен")
+	}
+
+	return context.WithValue(ctx, userIDKey{}, userID), nil
+}
+
+func userIDFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(userIDKey{}).(uint)
+	return userID
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,88 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/services"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type stubTokens map[string]uint
+
+func (s stubTokens) ValidateToken(token string) (uint, error) {
+	if userID, ok := s[token]; ok {
+		return userID, nil
+	}
+	return 0, errors.New("invalid token")
+}
+
+type stubAPIKeys struct {
+	keys map[string]uint
+	err  error
+}
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, secret string) (uint, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if userID, ok := s.keys[secret]; ok {
+		return userID, nil
+	}
+	return 0, services.ErrInvalidAPIKey
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth := authenticator{
+		tokens:  stubTokens{"access": 1},
+		apiKeys: stubAPIKeys{keys: map[string]uint{"sdk_valid": 2}},
+	}
+
+	tests := []struct {
+		name     string
+		auth     authenticator
+		md       metadata.MD
+		wantUser uint
+		wantCode codes.Code
+	}{
+		{name: "bearer token", auth: auth, md: metadata.Pairs("authorization", "Bearer access"), wantUser: 1},
+		{name: "api key", auth: auth, md: metadata.Pairs("x-api-key", "sdk_valid"), wantUser: 2},
+		{
+			name:     "api key takes precedence",
+			auth:     auth,
+			md:       metadata.Pairs("x-api-key", "sdk_valid", "authorization", "Bearer access"),
+			wantUser: 2,
+		},
+		{name: "invalid api key", auth: auth, md: metadata.Pairs("x-api-key", "sdk_revoked"), wantCode: codes.Unauthenticated},
+		{
+			name:     "api key storage failure",
+			auth:     authenticator{tokens: auth.tokens, apiKeys: stubAPIKeys{err: errors.New("connection reset")}},
+			md:       metadata.Pairs("x-api-key", "sdk_valid"),
+			wantCode: codes.Unavailable,
+		},
+		{name: "invalid token", auth: auth, md: metadata.Pairs("authorization", "Bearer expired"), wantCode: codes.Unauthenticated},
+		{name: "basic scheme", auth: auth, md: metadata.Pairs("authorization", "Basic access"), wantCode: codes.Unauthenticated},
+		{name: "no credentials", auth: auth, md: metadata.MD{}, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := tt.auth.authenticate(metadata.NewIncomingContext(context.Background(), tt.md))
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("authenticate error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if got := userIDFromContext(ctx); got != tt.wantUser {
+				t.Fatalf("user = %d, want %d", got, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package grpcapi - gRPC API анализа для внутренних сервисов. Использует те же
+// сервисы и ту же аутентификацию, что и HTTP API.
+package grpcapi
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=scam-detection-backend --go-grpc_out=../../.. --go-grpc_opt=module=scam-detection-backend analysis/v1/analysis.proto
+
+import (
+	"context"
+	"errors"
+	"io"
+	"scam-detection-backend/internal/api/grpc/analysispb"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxHistoryLimit = 100
+
+type analysisServer struct {
+	analysispb.UnimplementedAnalysisServiceServer
+
+	analysis *services.AnalysisService
+}
+
+// NewServer создаёт gRPC сервер с AnalysisService и стандартным health
+// сервисом. Все методы, кроме health, требуют ключ доступа или access токен.
+func NewServer(authService *services.AuthService, apiKeys *services.APIKeyService, analysis *services.AnalysisService) *grpc.Server {
+	auth := authenticator{tokens: authService, apiKeys: apiKeys}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryAuthInterceptor(auth)),
+		grpc.ChainStreamInterceptor(streamAuthInterceptor(auth)),
+	)
+
+	analysispb.RegisterAnalysisServiceServer(server, &analysisServer{analysis: analysis})
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	return server
+}
+
+func (s *analysisServer) AnalyzeText(ctx context.Context, req *analysispb.AnalyzeTextRequest) (*analysispb.AnalyzeTextResponse, error) {
+	result, err := s.analysis.AnalyzeText(ctx, userIDFromContext(ctx), req.GetText())
+	if err != nil {
+		return nil, analysisError(err)
+	}
+
+	return &analysispb.AnalyzeTextResponse{
+		CheckId:        uint64(result.Check.ID),
+		Success:        result.Success,
+		Degraded:       result.Degraded,
+		Prediction:     toPrediction(result.Prediction),
+		ProcessingTime: result.ProcessingTime,
+		DangerScore:    result.Check.DangerScore,
+		DangerLevel:    result.Check.DangerLevel,
+	}, nil
+}
+
+// AnalyzeBatch обрабатывает пакет синхронно при любом размере: клиент сам
+// ограничивает ожидание дедлайном вызова. Если не обработан ни один текст,
+// возвращается ошибка первого из них.
+func (s *analysisServer) AnalyzeBatch(ctx context.Context, req *analysispb.AnalyzeBatchRequest) (*analysispb.AnalyzeBatchResponse, error) {
+	result, err := s.analysis.AnalyzeBatch(ctx, userIDFromContext(ctx), req.GetTexts())
+	if err != nil {
+		return nil, analysisError(err)
+	}
+	if result.Err != nil && !result.Partial() {
+		return nil, analysisError(result.Err)
+	}
+
+	response := &analysispb.AnalyzeBatchResponse{
+		BatchId:        uint64(result.Job.ID),
+		Success:        result.Err == nil,
+		Partial:        result.Partial(),
+		Degraded:       result.Degraded,
+		Items:          make([]*analysispb.BatchItemResult, len(result.Items)),
+		ProcessingTime: result.ProcessingTime,
+	}
+	for i, item := range result.Items {
+		response.Items[i] = toBatchItemResult(item)
+	}
+
+	return response, nil
+}
+
+// AnalyzeStream отвечает на тексты в порядке получения. Ошибка проверки или
+// анализа одного текста передаётся в его результате и не закрывает поток.
+func (s *analysisServer) AnalyzeStream(stream grpc.BidiStreamingServer[analysispb.AnalyzeTextRequest, analysispb.BatchItemResult]) error {
+	ctx := stream.Context()
+	userID := userIDFromContext(ctx)
+
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := &analysispb.BatchItemResult{Index: index}
+		if err := services.ValidateText(req.GetText()); err != nil {
+			result.Status = models.BatchItemFailed
+			result.ErrorCode = models.BatchErrorInvalidText
+			result.Error = err.Error()
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		analysis, err := s.analysis.AnalyzeText(ctx, userID, req.GetText())
+		switch {
+		case err != nil:
+			result.Status = models.BatchItemFailed
+			result.ErrorCode = services.AnalysisErrorCode(err)
+			result.Error = err.Error()
+		case analysis.Degraded:
+			result.Status = models.BatchItemDegraded
+		default:
+			result.Status = models.BatchItemOK
+		}
+		if analysis != nil {
+			result.CheckId = uint64(analysis.Check.ID)
+			result.Prediction = toPrediction(analysis.Prediction)
+		}
+
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *analysisServer) ListHistory(ctx context.Context, req *analysispb.ListHistoryRequest) (*analysispb.ListHistoryResponse, error) {
+	page := max(int(req.GetPage()), 1)
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > maxHistoryLimit {
+		limit = 20
+	}
+
+	result, err := s.analysis.History(ctx, userIDFromContext(ctx), models.CheckHistoryFilter{}, services.HistoryQuery{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get check history: %v", err)
+	}
+
+	response := &analysispb.ListHistoryResponse{
+		Checks: make([]*analysispb.Check, len(result.Checks)),
+		Total:  *result.Total,
+		Page:   int32(page),
+		Limit:  int32(limit),
+	}
+	for i := range result.Checks {
+		response.Checks[i] = toCheck(&result.Checks[i])
+	}
+
+	return response, nil
+}
+
+func (s *analysisServer) GetStats(ctx context.Context, _ *analysispb.GetStatsRequest) (*analysispb.Stats, error) {
+	stats, err := s.analysis.Stats(ctx, userIDFromContext(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get stats: %v", err)
+	}
+
+	return &analysispb.Stats{
+		TotalAnalyses:           stats.TotalAnalyses,
+		SafeCount:               stats.SafeCount,
+		SuspiciousCount:         stats.SuspiciousCount,
+		DangerousCount:          stats.DangerousCount,
+		AverageRiskScore:        stats.AverageRiskScore,
+		AverageProcessingTimeMs: stats.AverageProcessingTime,
+	}, nil
+}
+
+// analysisError переводит ошибку AnalysisService в статус gRPC так же, как
+// HTTP API выбирает код ответа.
+func analysisError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, services.ErrEmptyText),
+		errors.Is(err, services.ErrTextTooLong),
+		errors.Is(err, services.ErrEmptyBatch),
+		errors.Is(err, services.ErrTooManyTexts):
+		code = codes.InvalidArgument
+	case errors.Is(err, services.ErrCheckNotFound):
+		code = codes.NotFound
+	case errors.Is(err, services.ErrMLUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, services.ErrMLTimeout):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+func toPrediction(pred mlclient.PredictionResult) *analysispb.Prediction {
+	return &analysispb.Prediction{
+		Label:        pred.Label,
+		Confidence:   pred.Confidence,
+		IsScam:       pred.IsScam,
+		ModelName:    pred.ModelName,
+		ModelVersion: pred.ModelVersion,
+		Cached:       pred.Cached,
+	}
+}
+
+func toBatchItemResult(item services.BatchItem) *analysispb.BatchItemResult {
+	result := &analysispb.BatchItemResult{
+		Index:     int32(item.Index),
+		Status:    item.Status,
+		ErrorCode: item.ErrorCode,
+	}
+	if item.Status == models.BatchItemFailed {
+		result.Error = item.Err.Error()
+		return result
+	}
+
+	result.CheckId = uint64(item.CheckID)
+	result.Prediction = toPrediction(item.Prediction)
+	return result
+}
+
+func toCheck(check *models.Check) *analysispb.Check {
+	return &analysispb.Check{
+		Id:               uint64(check.ID),
+		Title:            check.Title,
+		ContentType:      check.ContentType,
+		Content:          check.Content,
+		DangerScore:      check.DangerScore,
+		DangerLevel:      check.DangerLevel,
+		Status:           check.Status,
+		Degraded:         check.Degraded,
+		UserVerdict:      check.UserVerdict,
+		ModelName:        check.ModelName,
+		ModelVersion:     check.ModelVersion,
+		Revision:         int32(check.Revision),
+		ProcessingTimeMs: int32(check.ProcessingTime),
+		CreatedAt:        timestamppb.New(check.CreatedAt),
+		UpdatedAt:        timestamppb.New(check.UpdatedAt),
+	}
+}
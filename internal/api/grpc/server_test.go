@@ -0,0 +1,95 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"scam-detection-backend/internal/api/grpc/analysispb"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"scam-detection-backend/internal/services"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// memoryChecks - хранилище проверок в памяти для AnalyzeText.
+type memoryChecks struct {
+	repository.CheckRepository
+	nextID uint
+}
+
+func (m *memoryChecks) CreateCheck(check *models.Check) error {
+	m.nextID++
+	check.ID = m.nextID
+	return nil
+}
+
+func (m *memoryChecks) CompleteCheck(ctx context.Context, check *models.Check, details []*models.CheckDetail) error {
+	return nil
+}
+
+// fakeStream отдаёт заранее заданные тексты и запоминает ответы.
+type fakeStream struct {
+	grpc.ServerStream
+	texts []string
+	sent  []*analysispb.BatchItemResult
+}
+
+func (s *fakeStream) Context() context.Context {
+	return context.WithValue(context.Background(), userIDKey{}, uint(1))
+}
+
+func (s *fakeStream) Recv() (*analysispb.AnalyzeTextRequest, error) {
+	if len(s.texts) == 0 {
+		return nil, io.EOF
+	}
+	text := s.texts[0]
+	s.texts = s.texts[1:]
+	return &analysispb.AnalyzeTextRequest{Text: text}, nil
+}
+
+func (s *fakeStream) Send(result *analysispb.BatchItemResult) error {
+	s.sent = append(s.sent, result)
+	return nil
+}
+
+func TestAnalyzeStreamContinuesAfterInvalidText(t *testing.T) {
+	experiment := services.NewModelExperiment(nil, mlclient.NewFakeAnalyzer(), nil, services.ExperimentConfig{})
+	analysis := services.NewAnalysisService(&memoryChecks{}, experiment, nil, events.NewHub(0), false)
+	server := &analysisServer{analysis: analysis}
+
+	stream := &fakeStream{texts: []string{
+		"Привет, как дела?",
+		"",
+		strings.Repeat("а", services.MaxTextLength+1),
+		"Ваша карта заблокирована, срочно переведите деньги",
+	}}
+	if err := server.AnalyzeStream(stream); err != nil {
+		t.Fatalf("AnalyzeStream: %v", err)
+	}
+
+	want := []struct {
+		status    string
+		errorCode string
+	}{
+		{status: models.BatchItemOK},
+		{status: models.BatchItemFailed, errorCode: models.BatchErrorInvalidText},
+		{status: models.BatchItemFailed, errorCode: models.BatchErrorInvalidText},
+		{status: models.BatchItemOK},
+	}
+	if len(stream.sent) != len(want) {
+		t.Fatalf("sent %d results, want %d", len(stream.sent), len(want))
+	}
+	for i, result := range stream.sent {
+		if result.Index != int32(i) || result.Status != want[i].status || result.ErrorCode != want[i].errorCode {
+			t.Errorf("result %d = {index %d, status %q, code %q}, want {index %d, status %q, code %q}",
+				i, result.Index, result.Status, result.ErrorCode, i, want[i].status, want[i].errorCode)
+		}
+		if result.ErrorCode == models.BatchErrorInvalidText && (result.CheckId != 0 || result.Error == "") {
+			t.Errorf("result %d: invalid text stored as check %d, error %q", i, result.CheckId, result.Error)
+		}
+	}
+}
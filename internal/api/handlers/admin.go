@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AdminHandler struct {
+	auditService      services.AuditService
+	experiment        *services.ModelExperiment
+	reanalysisService *services.ReanalysisService
+}
+
+func NewAdminHandler(auditService services.AuditService, experiment *services.ModelExperiment, reanalysisService *services.ReanalysisService) *AdminHandler {
+	return &AdminHandler{
+		auditService:      auditService,
+		experiment:        experiment,
+		reanalysisService: reanalysisService,
+	}
+}
+
+type ReanalysisJobsResponse struct {
+	Jobs  []models.ReanalysisJob `json:"jobs"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+}
+
+// ListAuditEvents godoc
+// @Summary      Журнал аудита (админ)
+// @Description  Возвращает события безопасности всех пользователей с фильтрами и пагинацией
+// @Tags         admin
+// @Produce      json
+// @Security     CookieAuth
+// @Param        user_id query int false "ID пользователя"
+// @Param        event_type query string false "Тип события"
+// @Param        ip query string false "IP адрес"
+// @Param        from query string false "Начало периода (RFC3339)"
+// @Param        to query string false "Конец периода (RFC3339)"
+// @Param        page query int false "Номер страницы" default(1)
+// @Param        limit query int false "Количество записей на странице" default(20)
+// @Success      200 {object} AuditEventsResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/audit-events [get]
+func (h *AdminHandler) ListAuditEvents(c *gin.Context) {
+	filter := models.AuditEventFilter{
+		EventType: c.Query("event_type"),
+		IPAddress: c.Query("ip"),
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := stringToInt(raw)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный user_id"})
+			return
+		}
+		userID := uint(id)
+		filter.UserID = &userID
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный параметр from, ожидается RFC3339"})
+			return
+		}
+		filter.From = &from
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный параметр to, ожидается RFC3339"})
+			return
+		}
+		filter.To = &to
+	}
+
+	page, limit := parsePagination(c)
+
+	events, total, err := h.auditService.List(c.Request.Context(), filter, limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить журнал аудита"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditEventsResponse{
+		Events: events,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	})
+}
+
+// CompareModels godoc
+// @Summary      Сравнение live и candidate моделей (админ)
+// @Description  Отчёт по теневому прогону и A/B тесту: доля совпадений вердиктов, распределения оценок и точность по отзывам пользователей для каждой модели
+// @Tags         admin
+// @Produce      json
+// @Security     CookieAuth
+// @Param        since query string false "Начало периода (RFC3339), по умолчанию 7 дней назад"
+// @Success      200 {object} models.ModelComparisonReport
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/models/comparison [get]
+func (h *AdminHandler) CompareModels(c *gin.Context) {
+	since := time.Now().AddDate(0, 0, -7)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный параметр since, ожидается RFC3339"})
+			return
+		}
+		since = parsed
+	}
+
+	report, err := h.experiment.Report(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось построить отчёт сравнения моделей"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StartReanalysis godoc
+// @Summary      Запустить повторный анализ (админ)
+// @Description  Пересчитывает отобранные фильтром исторические проверки текущей моделью. Новая оценка сохраняется как следующая ревизия проверки, прежние остаются в истории. Задача выполняется в фоне, прогресс доступен по GET /admin/reanalysis/{id}
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        request body models.CheckFilter true "Фильтр проверок"
+// @Success      202 {object} models.ReanalysisJob
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/reanalysis [post]
+func (h *AdminHandler) StartReanalysis(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	var filter models.CheckFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit не может быть отрицательным"})
+		return
+	}
+
+	job, err := h.reanalysisService.Start(c.Request.Context(), adminID, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrReanalysisInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось запустить повторный анализ"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListReanalysisJobs godoc
+// @Summary      Задачи повторного анализа (админ)
+// @Tags         admin
+// @Produce      json
+// @Security     CookieAuth
+// @Param        page query int false "Номер страницы" default(1)
+// @Param        limit query int false "Количество записей на странице" default(20)
+// @Success      200 {object} ReanalysisJobsResponse
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/reanalysis [get]
+func (h *AdminHandler) ListReanalysisJobs(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	jobs, total, err := h.reanalysisService.List(c.Request.Context(), limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить задачи повторного анализа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReanalysisJobsResponse{
+		Jobs:  jobs,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+// GetReanalysisJob godoc
+// @Summary      Статус повторного анализа (админ)
+// @Tags         admin
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID задачи"
+// @Success      200 {object} models.ReanalysisJob
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /admin/reanalysis/{id} [get]
+func (h *AdminHandler) GetReanalysisJob(c *gin.Context) {
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный id задачи"})
+		return
+	}
+
+	job, err := h.reanalysisService.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "задача не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить задачу"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
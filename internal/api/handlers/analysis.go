@@ -1,27 +1,32 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"scam-detection-backend/internal/api/middleware"
 	"scam-detection-backend/internal/mlclient"
 	"scam-detection-backend/internal/models"
-	"scam-detection-backend/internal/repository"
+	"scam-detection-backend/internal/services"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 )
 
 type AnalysisHandler struct {
-	mlClient  *mlclient.MLClient
-	checkRepo repository.CheckRepository
+	analysis *services.AnalysisService
+	mlClient mlclient.Analyzer
+	batches  *services.BatchService
 }
 
-func NewAnalysisHandler(checkRepo repository.CheckRepository) *AnalysisHandler {
+func NewAnalysisHandler(analysis *services.AnalysisService, analyzer mlclient.Analyzer, batches *services.BatchService) *AnalysisHandler {
 	return &AnalysisHandler{
-		mlClient:  mlclient.NewMLClient(),
-		checkRepo: checkRepo,
+		analysis: analysis,
+		mlClient: analyzer,
+		batches:  batches,
 	}
 }
 
@@ -34,12 +39,12 @@ type AnalyzeTextRequest struct {
 }
 
 type AnalyzeBatchRequest struct {
-	Texts []string `json:"texts" binding:"required,min=1,max=100,dive,min=1,max=5000" example:"[\"Вы выиграли приз\", \"Привет, как дела?\"]"`
+	Texts []string `json:"texts" binding:"required,min=1,dive,min=1,max=5000" example:"[\"Вы выиграли приз\", \"Привет, как дела?\"]"`
 }
 
 // AnalyzeText godoc
 // @Summary      Анализ текста на мошенничество
-// @Description  Отправляет текст в ML сервис для определения, является ли он фишинговым/мошенническим
+// @Description  Отправляет текст в ML сервис для определения, является ли он фишинговым/мошенническим. Если ML сервис недоступен и включён ML_DEGRADE_ON_FAILURE, текст оценивается только правилами: ответ содержит "degraded": true и заниженную уверенность, а проверка позже пересчитывается моделью
 // @Tags         analysis
 // @Accept       json
 // @Produce      json
@@ -47,6 +52,8 @@ type AnalyzeBatchRequest struct {
 // @Success      200 {object} mlclient.TextAnalysisResponse "Успешный анализ"
 // @Failure      400 {object} ErrorResponse "Невалидный запрос"
 // @Failure      500 {object} ErrorResponse "Ошибка ML сервиса"
+// @Failure      503 {object} ErrorResponse "ML сервис временно недоступен (circuit breaker разомкнут)"
+// @Failure      504 {object} ErrorResponse "Таймаут ML сервиса"
 // @Security     BearerAuth
 // @Router       /analysis/text [post]
 func (h *AnalysisHandler) AnalyzeText(c *gin.Context) {
@@ -62,146 +69,21 @@ func (h *AnalysisHandler) AnalyzeText(c *gin.Context) {
 		return
 	}
 
-	title := req.Text
-	if len([]rune(req.Text)) > 50 {
-		title = string([]rune(req.Text)[:50])
-	}
-
-	check := &models.Check{
-		Title:       title,
-		ContentType: "text",
-		Content:     req.Text,
-		Status:      "processing",
-		UserID:      userID,
-	}
-
-	if err := h.checkRepo.CreateCheck(check); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save check: " + err.Error()})
-		return
-	}
-
-	startTime := time.Now()
-	result, err := h.mlClient.AnalyzeText(req.Text)
-	processingTime := int(time.Since(startTime).Milliseconds())
-
+	result, err := h.analysis.AnalyzeText(c.Request.Context(), userID, req.Text)
 	if err != nil {
-		h.checkRepo.UpdateCheckStatus(check.ID, "failed", 0, "", processingTime)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to analyze text: " + err.Error()})
-		return
-	}
-
-	var dangerScore float64
-	if result.Prediction.IsScam {
-		dangerScore = result.Prediction.Confidence
-	} else {
-		dangerScore = 1.0 - result.Prediction.Confidence
-	}
-
-	keywordScore := detectPhishingPatterns(req.Text)
-	dangerScore = dangerScore*0.7 + keywordScore*0.3
-	if dangerScore > 1.0 {
-		dangerScore = 1.0
-	}
-
-	dangerLevel := calculateDangerLevel(dangerScore)
-	if err := h.checkRepo.UpdateCheckStatus(
-		check.ID,
-		"completed",
-		dangerScore,
-		dangerLevel,
-		processingTime,
-	); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update check: " + err.Error()})
-		return
-	}
-
-	detailValue, _ := json.Marshal(map[string]interface{}{
-		"label":   result.Prediction.Label,
-		"is_scam": result.Prediction.IsScam,
-	})
-
-	detail := &models.CheckDetail{
-		CheckID:         check.ID,
-		FeatureName:     "ml_prediction",
-		FeatureValue:    string(detailValue),
-		ConfidenceScore: result.Prediction.Confidence,
-	}
-
-	if err := h.checkRepo.AddCheckDetail(detail); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save check detail: " + err.Error()})
+		c.JSON(analysisErrorStatus(err), ErrorResponse{Error: "Failed to analyze text: " + err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"check_id":        check.ID,
+		"check_id":        result.Check.ID,
 		"success":         result.Success,
+		"degraded":        result.Degraded,
 		"prediction":      result.Prediction,
 		"processing_time": result.ProcessingTime,
 	})
 }
 
-func calculateDangerLevel(confidence float64) string {
-	if confidence < 0.3 {
-		return "low"
-	} else if confidence < 0.6 {
-		return "medium"
-	} else if confidence < 0.85 {
-		return "high"
-	}
-	return "critical"
-}
-
-func detectPhishingPatterns(text string) float64 {
-	textLower := strings.ToLower(text)
-	score := 0.0
-
-	criticalPatterns := map[string]float64{
-		"cvv":                      0.4,
-		"код из смс":               0.4,
-		"код из сообщения":         0.4,
-		"назовите пароль":          0.4,
-		"введите пароль":           0.4,
-		"данные карты":             0.35,
-		"номер карты":              0.35,
-		"срок действия карты":      0.4,
-		"служба безопасности банк": 0.3,
-		"техподдержка банк":        0.3,
-		"администратор банк":       0.3,
-	}
-
-	highRiskPatterns := map[string]float64{
-		"перейдите по ссылке": 0.25,
-		"подтвердите данные":  0.25,
-		"заблокирован":        0.2,
-		"восстановление":      0.15,
-		"отправьте код":       0.25,
-		"назовите код":        0.25,
-		"переведите":          0.2,
-		"выиграли":            0.2,
-		"приз":                0.15,
-		"срочно обновить":     0.2,
-		"аккаунт удален":      0.2,
-	}
-
-	for pattern, weight := range criticalPatterns {
-		if strings.Contains(textLower, pattern) {
-			score += weight
-		}
-	}
-
-	for pattern, weight := range highRiskPatterns {
-		if strings.Contains(textLower, pattern) {
-			score += weight
-		}
-	}
-
-	if score > 1.0 {
-		score = 1.0
-	}
-
-	return score
-}
-
 func detectPhishingKeywords(text string) float64 {
 	text = strings.ToLower(text)
 
@@ -226,16 +108,47 @@ func detectPhishingKeywords(text string) float64 {
 	return score
 }
 
+type BatchItemResult struct {
+	Index int `json:"index" example:"0"`
+	// Status: ok, degraded (оценено только правилами) или failed
+	Status     string                     `json:"status" example:"ok"`
+	CheckID    uint                       `json:"check_id,omitempty" example:"42"`
+	Prediction *mlclient.PredictionResult `json:"prediction,omitempty"`
+	// ErrorCode: ml_unavailable, ml_timeout, ml_error, missing_prediction,
+	// storage_error или canceled
+	ErrorCode string `json:"error_code,omitempty" example:"missing_prediction"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchAnalysisResponse - результат синхронного пакета. Items содержит
+// результат для каждого входного текста по его индексу; CheckIDs и
+// Predictions - только для успешно обработанных текстов.
+type BatchAnalysisResponse struct {
+	BatchID        uint                        `json:"batch_id"`
+	Success        bool                        `json:"success"`
+	Partial        bool                        `json:"partial"`
+	Degraded       bool                        `json:"degraded"`
+	Items          []BatchItemResult           `json:"items"`
+	CheckIDs       []uint                      `json:"check_ids"`
+	Predictions    []mlclient.PredictionResult `json:"predictions"`
+	ProcessingTime float64                     `json:"processing_time"`
+	Error          string                      `json:"error,omitempty"`
+}
+
 // AnalyzeBatch godoc
 // @Summary      Пакетный анализ текстов
-// @Description  Отправляет несколько текстов в ML сервис для анализа
+// @Description  Анализирует пакет текстов (до BATCH_MAX_TEXTS). Пакет не больше BATCH_CHUNK_SIZE обрабатывается сразу и возвращает результат для каждого текста; если часть текстов не обработана, ответ имеет статус 207. Пакет большего размера разбивается на части, которые обрабатываются в фоне, а ответ 202 содержит задачу, прогресс которой доступен через /analysis/batch/{id}. При недоступности ML сервиса части пакета оцениваются правилами, как /analysis/text в деградированном режиме
 // @Tags         analysis
 // @Accept       json
 // @Produce      json
 // @Param        request body AnalyzeBatchRequest true "Список текстов для анализа"
-// @Success      200 {object} mlclient.BatchTextAnalysisResponse "Успешный анализ"
+// @Success      200 {object} BatchAnalysisResponse "Все тексты обработаны"
+// @Success      202 {object} models.BatchJob "Пакет принят в обработку"
+// @Success      207 {object} BatchAnalysisResponse "Часть текстов не обработана, причины в items"
 // @Failure      400 {object} ErrorResponse "Невалидный запрос"
-// @Failure      500 {object} ErrorResponse "Ошибка ML сервиса"
+// @Failure      500 {object} BatchAnalysisResponse "Ошибка ML сервиса, не обработан ни один текст"
+// @Failure      503 {object} BatchAnalysisResponse "ML сервис временно недоступен (circuit breaker разомкнут)"
+// @Failure      504 {object} BatchAnalysisResponse "Таймаут ML сервиса"
 // @Security     BearerAuth
 // @Router       /analysis/batch [post]
 func (h *AnalysisHandler) AnalyzeBatch(c *gin.Context) {
@@ -251,114 +164,409 @@ func (h *AnalysisHandler) AnalyzeBatch(c *gin.Context) {
 		return
 	}
 
-	startTime := time.Now()
-	result, err := h.mlClient.AnalyzeBatch(req.Texts)
-	processingTime := int(time.Since(startTime).Milliseconds())
+	if len(req.Texts) > h.batches.ChunkSize() {
+		job, err := h.analysis.StartBatch(c.Request.Context(), userID, req.Texts)
+		if err != nil {
+			c.JSON(analysisErrorStatus(err), ErrorResponse{Error: "Failed to create batch: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
 
+	result, err := h.analysis.AnalyzeBatch(c.Request.Context(), userID, req.Texts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to analyze texts: " + err.Error()})
+		c.JSON(analysisErrorStatus(err), ErrorResponse{Error: "Failed to create batch: " + err.Error()})
 		return
 	}
 
-	checkIDs := make([]uint, 0, len(req.Texts))
-	for i, text := range req.Texts {
-		if i >= len(result.Predictions) {
-			break
-		}
-
-		pred := result.Predictions[i]
+	response := BatchAnalysisResponse{
+		BatchID:        result.Job.ID,
+		Degraded:       result.Degraded,
+		Items:          make([]BatchItemResult, len(result.Items)),
+		CheckIDs:       make([]uint, 0, result.Succeeded),
+		Predictions:    make([]mlclient.PredictionResult, 0, result.Succeeded),
+		ProcessingTime: result.ProcessingTime,
+	}
 
-		title := text
-		if len([]rune(text)) > 50 {
-			title = string([]rune(text)[:50])
+	for i, item := range result.Items {
+		itemResult := BatchItemResult{
+			Index:     item.Index,
+			Status:    item.Status,
+			ErrorCode: item.ErrorCode,
 		}
 
-		var dangerScore float64
-		if pred.IsScam {
-			dangerScore = pred.Confidence
+		if item.Status == models.BatchItemFailed {
+			itemResult.Error = item.Err.Error()
 		} else {
-			dangerScore = 1.0 - pred.Confidence
+			prediction := item.Prediction
+			itemResult.CheckID = item.CheckID
+			itemResult.Prediction = &prediction
+			response.CheckIDs = append(response.CheckIDs, item.CheckID)
+			response.Predictions = append(response.Predictions, item.Prediction)
 		}
 
-		keywordScore := detectPhishingPatterns(text)
-		dangerScore = dangerScore*0.7 + keywordScore*0.3
-		if dangerScore > 1.0 {
-			dangerScore = 1.0
+		response.Items[i] = itemResult
+	}
+
+	status := batchAnalysisStatus(result)
+	switch status {
+	case http.StatusOK:
+		response.Success = true
+	case http.StatusMultiStatus:
+		response.Partial = true
+	default:
+		response.Error = "Failed to analyze texts: " + result.Err.Error()
+	}
+	c.JSON(status, response)
+}
+
+// batchAnalysisStatus: 200 - обработаны все тексты, 207 - часть, иначе
+// статус ошибки первого текста.
+func batchAnalysisStatus(result *services.BatchAnalysis) int {
+	switch {
+	case result.Err == nil:
+		return http.StatusOK
+	case result.Partial():
+		return http.StatusMultiStatus
+	default:
+		return analysisErrorStatus(result.Err)
+	}
+}
+
+// GetBatch godoc
+// @Summary      Прогресс пакетного анализа
+// @Description  Возвращает статус пакета и число обработанных, неудавшихся и оценённых только правилами текстов. Проверки пакета появляются в истории по мере обработки частей
+// @Tags         analysis
+// @Produce      json
+// @Param        id path int true "ID пакета"
+// @Success      200 {object} models.BatchJob "Пакет"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      404 {object} ErrorResponse "Пакет не найден"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/batch/{id} [get]
+func (h *AnalysisHandler) GetBatch(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid batch id"})
+		return
+	}
+
+	job, err := h.batches.Get(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrBatchNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "batch not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get batch: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+type BatchItemsResponse struct {
+	Items []models.BatchItem `json:"items"`
+	// NextAfter - значение after для следующей страницы
+	NextAfter uint `json:"next_after"`
+}
+
+// GetBatchItems godoc
+// @Summary      Результаты пакетного анализа
+// @Description  Возвращает результат каждого текста пакета, включая неудавшиеся (status failed и error_code), в порядке записи. Для получения новых результатов передайте after из next_after предыдущего ответа
+// @Tags         analysis
+// @Produce      json
+// @Param        id path int true "ID пакета"
+// @Param        after query int false "seq последнего полученного результата" default(0)
+// @Param        limit query int false "Количество результатов (максимум 1000)" default(100)
+// @Success      200 {object} BatchItemsResponse "Результаты"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      404 {object} ErrorResponse "Пакет не найден"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/batch/{id}/items [get]
+func (h *AnalysisHandler) GetBatchItems(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid batch id"})
+		return
+	}
+
+	after, err := stringToInt(c.DefaultQuery("after", "0"))
+	if err != nil || after < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid after"})
+		return
+	}
+
+	limit := 100
+	if l, exists := c.GetQuery("limit"); exists {
+		if val, err := stringToInt(l); err == nil && val > 0 && val <= 1000 {
+			limit = val
 		}
+	}
 
-		check := &models.Check{
-			Title:          title,
-			ContentType:    "text",
-			Content:        text,
-			Status:         "completed",
-			UserID:         userID,
-			DangerScore:    dangerScore,
-			DangerLevel:    calculateDangerLevel(dangerScore),
-			ProcessingTime: processingTime / len(req.Texts),
+	items, err := h.batches.ListItems(c.Request.Context(), userID, uint(id), uint(after), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrBatchNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "batch not found"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get batch items: " + err.Error()})
+		return
+	}
+
+	response := BatchItemsResponse{Items: items, NextAfter: uint(after)}
+	if len(items) > 0 {
+		response.NextAfter = items[len(items)-1].Seq
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+const (
+	batchStreamPageSize     = 500
+	batchStreamPollInterval = 2 * time.Second
+)
+
+type BatchProgressEvent struct {
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	Degraded  int    `json:"degraded"`
+}
+
+// StreamBatch godoc
+// @Summary      Поток результатов пакетного анализа
+// @Description  Server-Sent Events: событие item с результатом каждого текста по мере обработки (id события - seq результата), progress при изменении счётчиков пакета и summary с итоговым состоянием пакета, после которого поток закрывается. При переподключении с заголовком Last-Event-ID (или параметром last_event_id) поток продолжается с первого не полученного результата
+// @Tags         analysis
+// @Produce      text/event-stream
+// @Param        id path int true "ID пакета"
+// @Param        Last-Event-ID header int false "seq последнего полученного результата"
+// @Param        last_event_id query int false "То же, что Last-Event-ID, для клиентов без доступа к заголовкам"
+// @Success      200 {object} models.BatchItem "Поток событий item, progress и summary"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      404 {object} ErrorResponse "Пакет не найден"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/batch/{id}/stream [get]
+func (h *AnalysisHandler) StreamBatch(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid batch id"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.DefaultQuery("last_event_id", "0")
+	}
+	after, err := stringToInt(lastEventID)
+	if err != nil || after < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid Last-Event-ID"})
+		return
+	}
 
-		if err := h.checkRepo.CreateCheck(check); err != nil {
-			continue
+	ctx := c.Request.Context()
+	job, err := h.batches.Get(ctx, userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrBatchNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "batch not found"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get batch: " + err.Error()})
+		return
+	}
 
-		checkIDs = append(checkIDs, check.ID)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
 
-		detailValue, _ := json.Marshal(map[string]interface{}{
-			"label":   pred.Label,
-			"is_scam": pred.IsScam,
-		})
+	cursor := uint(after)
+	var progress BatchProgressEvent
+	ticker := time.NewTicker(batchStreamPollInterval)
+	defer ticker.Stop()
 
-		detail := &models.CheckDetail{
-			CheckID:         check.ID,
-			FeatureName:     "ml_prediction",
-			FeatureValue:    string(detailValue),
-			ConfidenceScore: pred.Confidence,
+	for {
+		// Канал берётся до чтения, чтобы не пропустить результаты, записанные
+		// между чтением и ожиданием
+		changed := h.batches.Changes(job.ID)
+
+		// Пакет читается раньше результатов: если он уже завершён, все его
+		// результаты записаны и будут прочитаны ниже
+		if job, err = h.batches.Get(ctx, userID, job.ID); err != nil {
+			c.Render(-1, sse.Event{Event: "error", Data: ErrorResponse{Error: "Failed to get batch: " + err.Error()}})
+			return
+		}
+
+		for {
+			items, err := h.batches.ListItems(ctx, userID, job.ID, cursor, batchStreamPageSize)
+			if err != nil {
+				c.Render(-1, sse.Event{Event: "error", Data: ErrorResponse{Error: "Failed to get batch items: " + err.Error()}})
+				return
+			}
+			for _, item := range items {
+				c.Render(-1, sse.Event{Id: strconv.FormatUint(uint64(item.Seq), 10), Event: "item", Data: item})
+				cursor = item.Seq
+			}
+			if len(items) < batchStreamPageSize {
+				break
+			}
+		}
+
+		current := BatchProgressEvent{
+			Status:    job.Status,
+			Total:     job.Total,
+			Processed: job.Processed,
+			Failed:    job.Failed,
+			Degraded:  job.Degraded,
+		}
+		if current != progress {
+			c.Render(-1, sse.Event{Event: "progress", Data: current})
+			progress = current
 		}
 
-		h.checkRepo.AddCheckDetail(detail)
+		if job.Done() {
+			c.Render(-1, sse.Event{Event: "summary", Data: job})
+			c.Writer.Flush()
+			return
+		}
+		c.Writer.Flush()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-ticker.C:
+			c.Writer.WriteString(": keepalive\n\n")
+		}
 	}
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"check_ids":       checkIDs,
-		"success":         result.Success,
-		"predictions":     result.Predictions,
-		"processing_time": result.ProcessingTime,
-	})
+type MLHealthResponse struct {
+	*mlclient.HealthResponse
+	Error          string                 `json:"error,omitempty"`
+	CircuitBreaker *mlclient.BreakerStats `json:"circuit_breaker,omitempty"`
+	Models         []mlclient.ModelHealth `json:"models,omitempty"`
 }
 
 // MLHealthCheck godoc
 // @Summary      Проверка здоровья ML сервиса
-// @Description  Возвращает статус ML сервиса и информацию о модели
+// @Description  Возвращает статус ML сервиса, информацию о модели и состояние circuit breaker клиента. Для ансамбля моделей в models возвращается состояние каждой модели, а общий статус degraded означает, что отвечает только часть моделей
 // @Tags         analysis
 // @Produce      json
-// @Success      200 {object} mlclient.HealthResponse "ML сервис здоров"
-// @Failure      500 {object} ErrorResponse "ML сервис недоступен"
+// @Success      200 {object} MLHealthResponse "ML сервис здоров"
+// @Failure      503 {object} MLHealthResponse "ML сервис недоступен"
 // @Router       /analysis/health [get]
 func (h *AnalysisHandler) MLHealthCheck(c *gin.Context) {
-	health, err := h.mlClient.HealthCheck()
+	var response MLHealthResponse
+	if reporter, ok := h.mlClient.(mlclient.CircuitReporter); ok {
+		stats := reporter.CircuitState()
+		response.CircuitBreaker = &stats
+	}
+
+	var health *mlclient.HealthResponse
+	var err error
+	if reporter, ok := h.mlClient.(mlclient.ModelHealthReporter); ok {
+		response.Models = reporter.ModelsHealth(c.Request.Context())
+		health, err = mlclient.SummarizeHealth(response.Models)
+	} else {
+		health, err = h.mlClient.HealthCheck(c.Request.Context())
+	}
+
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "ML service is unavailable: " + err.Error()})
+		response.Error = "ML service is unavailable: " + err.Error()
+		c.JSON(http.StatusServiceUnavailable, response)
 		return
 	}
 
-	c.JSON(http.StatusOK, health)
+	response.HealthResponse = health
+	c.JSON(http.StatusOK, response)
 }
 
+// analysisErrorStatus выбирает код ответа по ошибке AnalysisService.
+// Недоступность ML сервиса (breaker разомкнут) и таймаут отличаются от
+// прочих ошибок анализа.
+func analysisErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrEmptyText),
+		errors.Is(err, services.ErrTextTooLong),
+		errors.Is(err, services.ErrEmptyBatch),
+		errors.Is(err, services.ErrTooManyTexts),
+		errors.Is(err, services.ErrInvalidHistoryFilter),
+		errors.Is(err, services.ErrInvalidCursor),
+		errors.Is(err, services.ErrInvalidStatsBucket),
+		errors.Is(err, services.ErrInvalidStatsRange),
+		errors.Is(err, services.ErrInvalidTimezone),
+		errors.Is(err, services.ErrStatsRangeTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCheckNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrMLUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, services.ErrMLTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CheckHistoryResponse - страница истории. Total отсутствует при
+// with_total=false, Page - при выборке по курсору.
 type CheckHistoryResponse struct {
-	Checks []models.Check `json:"checks"`
-	Total  int64          `json:"total"`
-	Page   int            `json:"page"`
-	Limit  int            `json:"limit"`
+	Checks     []models.Check `json:"checks"`
+	Total      *int64         `json:"total,omitempty"`
+	Page       int            `json:"page,omitempty"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
 }
 
 // GetCheckHistory godoc
 // @Summary      История проверок пользователя
-// @Description  Возвращает список всех проверок текущего пользователя с пагинацией
+// @Description  Возвращает проверки текущего пользователя с фильтрами, сортировкой и пагинацией. При сортировке по created_at ответ содержит next_cursor и prev_cursor: курсор передаётся в cursor вместо page, и страницы не сдвигаются при появлении новых проверок. Параметр q включает полнотекстовый поиск (русский и английский): в snippet возвращается фрагмент текста с найденными словами в <mark>, остальной текст экранирован
 // @Tags         analysis
 // @Produce      json
+// @Param        q query string false "Строка поиска (синтаксис websearch: фразы в кавычках, -исключение, or)"
+// @Param        danger_level query []string false "Уровни опасности" collectionFormat(csv) Enums(low, medium, high, critical)
+// @Param        status query []string false "Статусы" collectionFormat(csv) Enums(processing, completed, failed)
+// @Param        content_type query string false "Тип содержимого"
+// @Param        min_score query number false "Минимальная оценка опасности"
+// @Param        max_score query number false "Максимальная оценка опасности"
+// @Param        from query string false "Начало периода (RFC3339)"
+// @Param        to query string false "Конец периода (RFC3339)"
+// @Param        has_feedback query bool false "Только проверки с отзывом (true) или без него (false)"
+// @Param        sort query string false "Сортировка; по умолчанию relevance при поиске, иначе created_at" Enums(created_at, danger_score, processing_time, relevance)
+// @Param        order query string false "Направление сортировки" Enums(asc, desc) default(desc)
 // @Param        page query int false "Номер страницы" default(1)
 // @Param        limit query int false "Количество записей на странице" default(20)
+// @Param        cursor query string false "Курсор next_cursor или prev_cursor из предыдущего ответа; заменяет page"
+// @Param        with_total query bool false "Считать общее число проверок (total)" default(true)
 // @Success      200 {object} CheckHistoryResponse "Список проверок"
+// @Failure      400 {object} ErrorResponse "Неверные параметры"
 // @Failure      401 {object} ErrorResponse "Не авторизован"
 // @Failure      500 {object} ErrorResponse "Ошибка БД"
 // @Security     BearerAuth
@@ -370,34 +578,106 @@ func (h *AnalysisHandler) GetCheckHistory(c *gin.Context) {
 		return
 	}
 
-	page := 1
-	if p, exists := c.GetQuery("page"); exists {
-		if val, err := stringToInt(p); err == nil && val > 0 {
-			page = val
-		}
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	limit := 20
-	if l, exists := c.GetQuery("limit"); exists {
-		if val, err := stringToInt(l); err == nil && val > 0 && val <= 100 {
-			limit = val
+	page, limit := parsePagination(c)
+	query := services.HistoryQuery{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		Cursor: c.Query("cursor"),
+	}
+	if raw := c.Query("with_total"); raw != "" {
+		withTotal, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid with_total"})
+			return
 		}
+		query.SkipTotal = !withTotal
 	}
 
-	offset := (page - 1) * limit
-
-	checks, total, err := h.checkRepo.GetChecksByUserID(userID, limit, offset)
+	result, err := h.analysis.History(c.Request.Context(), userID, filter, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get check history: " + err.Error()})
+		c.JSON(analysisErrorStatus(err), ErrorResponse{Error: "Failed to get check history: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, CheckHistoryResponse{
-		Checks: checks,
-		Total:  total,
-		Page:   page,
-		Limit:  limit,
-	})
+	response := CheckHistoryResponse{
+		Checks:     result.Checks,
+		Total:      result.Total,
+		Limit:      limit,
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+	}
+	if query.Cursor == "" {
+		response.Page = page
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// historyFilter разбирает параметры запроса истории. Списки принимаются
+// через запятую или повторением параметра.
+func historyFilter(c *gin.Context) (models.CheckHistoryFilter, error) {
+	filter := models.CheckHistoryFilter{
+		DangerLevels: queryList(c, "danger_level"),
+		Statuses:     queryList(c, "status"),
+		ContentType:  c.Query("content_type"),
+		Query:        strings.TrimSpace(c.Query("q")),
+		Sort:         c.Query("sort"),
+	}
+
+	for name, target := range map[string]**float64{"min_score": &filter.MinScore, "max_score": &filter.MaxScore} {
+		if raw := c.Query(name); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = &value
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(name); raw != "" {
+			value, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC3339", name)
+			}
+			*target = &value
+		}
+	}
+
+	if raw := c.Query("has_feedback"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("invalid has_feedback")
+		}
+		filter.HasFeedback = &value
+	}
+
+	switch c.Query("order") {
+	case "", "desc":
+	case "asc":
+		filter.Asc = true
+	default:
+		return filter, errors.New("invalid order, expected asc or desc")
+	}
+
+	return filter, nil
+}
+
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
 }
 
 func stringToInt(s string) (int, error) {
@@ -413,12 +693,13 @@ func stringToInt(s string) (int, error) {
 
 // DeleteCheck godoc
 // @Summary      Удалить проверку
-// @Description  Удаляет проверку из истории пользователя
+// @Description  Удаляет проверку из истории пользователя вместе с её деталями и ревизиями
 // @Tags         analysis
 // @Param        id path int true "ID проверки"
 // @Success      200 {object} map[string]string "Успешно удалено"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
 // @Failure      401 {object} ErrorResponse "Не авторизован"
-// @Failure      403 {object} ErrorResponse "Нет доступа"
+// @Failure      404 {object} ErrorResponse "Проверка не найдена"
 // @Failure      500 {object} ErrorResponse "Ошибка БД"
 // @Security     BearerAuth
 // @Router       /analysis/history/{id} [delete]
@@ -436,7 +717,11 @@ func (h *AnalysisHandler) DeleteCheck(c *gin.Context) {
 		return
 	}
 
-	if err := h.checkRepo.DeleteCheck(uint(id), userID); err != nil {
+	if err := h.analysis.DeleteCheck(c.Request.Context(), userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "check not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete check: " + err.Error()})
 		return
 	}
@@ -444,12 +729,101 @@ func (h *AnalysisHandler) DeleteCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Check deleted successfully"})
 }
 
+// GetCheckRevisions godoc
+// @Summary      История оценок проверки
+// @Description  Возвращает все ревизии оценки проверки: исходную и полученные при повторном анализе новыми версиями модели
+// @Tags         analysis
+// @Produce      json
+// @Param        id path int true "ID проверки"
+// @Success      200 {array} models.CheckRevision "Ревизии"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      404 {object} ErrorResponse "Проверка не найдена"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/history/{id}/revisions [get]
+func (h *AnalysisHandler) GetCheckRevisions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid check id"})
+		return
+	}
+
+	revisions, err := h.analysis.Revisions(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get revisions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+type CheckFeedbackRequest struct {
+	IsScam *bool `json:"is_scam" binding:"required" example:"true"`
+}
+
+// SubmitFeedback godoc
+// @Summary      Оценка результата проверки
+// @Description  Сохраняет мнение пользователя о том, было ли сообщение мошенническим. Используется для оценки точности моделей
+// @Tags         analysis
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID проверки"
+// @Param        request body CheckFeedbackRequest true "Оценка пользователя"
+// @Success      200 {object} map[string]string "Оценка сохранена"
+// @Failure      400 {object} ErrorResponse "Невалидный запрос"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      404 {object} ErrorResponse "Проверка не найдена"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/history/{id}/feedback [post]
+func (h *AnalysisHandler) SubmitFeedback(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid check id"})
+		return
+	}
+
+	var req CheckFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.analysis.SubmitFeedback(c.Request.Context(), userID, uint(id), *req.IsScam); err != nil {
+		if errors.Is(err, services.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save feedback: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feedback saved"})
+}
+
 // GetStats godoc
 // @Summary      Статистика пользователя
 // @Description  Возвращает агрегированную статистику по проверкам пользователя
 // @Tags         analysis
 // @Produce      json
-// @Success      200 {object} map[string]interface{} "Статистика"
+// @Success      200 {object} models.UserStats "Статистика"
 // @Failure      401 {object} ErrorResponse "Не авторизован"
 // @Failure      500 {object} ErrorResponse "Ошибка БД"
 // @Security     BearerAuth
@@ -461,7 +835,7 @@ func (h *AnalysisHandler) GetStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := h.checkRepo.GetUserStats(userID)
+	stats, err := h.analysis.Stats(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get stats: " + err.Error()})
 		return
@@ -469,3 +843,57 @@ func (h *AnalysisHandler) GetStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetStatsTimeSeries godoc
+// @Summary      Статистика по интервалам
+// @Description  Возвращает число проверок по уровням опасности, средний риск и среднее время обработки для каждого интервала периода, а также самые частые правила и домены ссылок в опасных проверках
+// @Tags         analysis
+// @Produce      json
+// @Param        from query string false "Начало периода (RFC3339), по умолчанию 30 дней назад"
+// @Param        to query string false "Конец периода (RFC3339), по умолчанию сейчас"
+// @Param        bucket query string false "Интервал" Enums(hour, day, week) default(day)
+// @Param        tz query string false "Часовой пояс IANA, в котором начинаются интервалы" default(UTC)
+// @Success      200 {object} models.StatsTimeSeries "Статистика"
+// @Failure      400 {object} ErrorResponse "Неверные параметры"
+// @Failure      401 {object} ErrorResponse "Не авторизован"
+// @Failure      500 {object} ErrorResponse "Ошибка БД"
+// @Security     BearerAuth
+// @Router       /analysis/stats/timeseries [get]
+func (h *AnalysisHandler) GetStatsTimeSeries(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	query := models.StatsTimeSeriesQuery{
+		Bucket:   c.Query("bucket"),
+		Timezone: c.Query("tz"),
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid from, expected RFC3339"})
+			return
+		}
+		query.From = from
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid to, expected RFC3339"})
+			return
+		}
+		query.To = to
+	}
+
+	series, err := h.analysis.StatsTimeSeries(c.Request.Context(), userID, query)
+	if err != nil {
+		c.JSON(analysisErrorStatus(err), ErrorResponse{Error: "Failed to get stats: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,582 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"scam-detection-backend/internal/services"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testUserID uint = 1
+
+// memoryChecks хранит проверки в памяти. ListChecks запоминает фильтр и
+// отдаёт проверки пользователя от новых к старым без фильтрации, учитывая
+// только курсор вперёд; listErr имитирует сбой БД.
+type memoryChecks struct {
+	repository.CheckRepository
+
+	mu         sync.Mutex
+	checks     []*models.Check
+	listErr    error
+	lastFilter *models.CheckHistoryFilter
+}
+
+func (m *memoryChecks) CreateCheck(check *models.Check) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	check.ID = uint(len(m.checks) + 1)
+	check.CreatedAt = time.Date(2026, 1, 1, 0, 0, len(m.checks), 0, time.UTC)
+	stored := *check
+	m.checks = append(m.checks, &stored)
+	return nil
+}
+
+func (m *memoryChecks) UpdateCheckStatus(id uint, status string, dangerScore float64, dangerLevel string, processingTime int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	check := m.checks[id-1]
+	check.Status, check.ProcessingTime = status, processingTime
+	return nil
+}
+
+func (m *memoryChecks) CompleteCheck(ctx context.Context, check *models.Check, details []*models.CheckDetail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *check
+	stored.Status = "completed"
+	m.checks[check.ID-1] = &stored
+	return nil
+}
+
+func (m *memoryChecks) ListChecks(ctx context.Context, userID uint, filter models.CheckHistoryFilter, page models.CheckPage) (*models.CheckList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastFilter = &filter
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	var checks []models.Check
+	for _, check := range slices.Backward(m.checks) {
+		// Проверки упорядочены от новых к старым, поэтому курсор вперёд -
+		// проверки с меньшим id
+		if check.UserID == userID && (page.After == nil || check.ID < page.After.ID) {
+			checks = append(checks, *check)
+		}
+	}
+
+	list := &models.CheckList{}
+	if !page.SkipTotal {
+		total := int64(len(checks))
+		list.Total = &total
+	}
+	checks = checks[min(page.Offset, len(checks)):]
+	list.HasMore = len(checks) > page.Limit
+	list.Checks = checks[:min(page.Limit, len(checks))]
+	return list, nil
+}
+
+func (m *memoryChecks) stored(id uint) models.Check {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return *m.checks[id-1]
+}
+
+// memoryBatches хранит пакеты в памяти и присваивает ID проверкам частей.
+type memoryBatches struct {
+	repository.BatchJobRepository
+
+	mu     sync.Mutex
+	nextID uint
+	jobs   map[uint]models.BatchJob
+}
+
+func (r *memoryBatches) Create(ctx context.Context, job *models.BatchJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	job.ID = r.nextID
+	if r.jobs == nil {
+		r.jobs = make(map[uint]models.BatchJob)
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryBatches) GetByID(ctx context.Context, id uint) (*models.BatchJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := r.jobs[id]
+	return &job, nil
+}
+
+func (r *memoryBatches) Update(ctx context.Context, job *models.BatchJob) error {
+	return nil
+}
+
+func (r *memoryBatches) SaveChunk(ctx context.Context, jobID uint, items []*models.BatchItem, checks []*models.Check, details [][]*models.CheckDetail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, check := range checks {
+		if check != nil {
+			r.nextID++
+			check.ID = r.nextID
+		}
+	}
+	return nil
+}
+
+// newAnalysisRouter собирает AnalysisHandler поверх analyzer и хранилищ в
+// памяти; все запросы выполняются от имени testUserID.
+func newAnalysisRouter(analyzer *mlclient.FakeAnalyzer, degrade bool) (*gin.Engine, *memoryChecks) {
+	gin.SetMode(gin.TestMode)
+
+	checks := &memoryChecks{}
+	bus := events.NewHub(0)
+	experiment := services.NewModelExperiment(nil, analyzer, nil, services.ExperimentConfig{})
+	batches := services.NewBatchService(&memoryBatches{}, experiment, bus, services.BatchConfig{
+		MaxTexts:         10,
+		ChunkSize:        5,
+		Workers:          1,
+		DegradeOnFailure: degrade,
+	})
+	handler := NewAnalysisHandler(services.NewAnalysisService(checks, experiment, batches, bus, degrade), analyzer, batches)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(middleware.UserIDKey, testUserID) })
+	router.POST("/analysis/text", handler.AnalyzeText)
+	router.POST("/analysis/batch", handler.AnalyzeBatch)
+	router.GET("/analysis/health", handler.MLHealthCheck)
+	router.GET("/analysis/history", handler.GetCheckHistory)
+	return router, checks
+}
+
+func serve(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+}
+
+func TestAnalyzeText(t *testing.T) {
+	const scam = `{"text": "Ваша карта заблокирована, назовите код из СМС"}`
+
+	tests := []struct {
+		name        string
+		body        string
+		mlErr       error
+		degrade     bool
+		want        int
+		wantStatus  string
+		wantScam    bool
+		wantDegrade bool
+	}{
+		{name: "success", body: scam, want: http.StatusOK, wantStatus: "completed", wantScam: true},
+		{name: "empty text", body: `{"text": ""}`, want: http.StatusBadRequest},
+		{name: "ML service unavailable", body: scam, mlErr: mlclient.ErrCircuitOpen, want: http.StatusServiceUnavailable, wantStatus: "failed"},
+		{name: "ML service timeout", body: scam, mlErr: context.DeadlineExceeded, want: http.StatusGatewayTimeout, wantStatus: "failed"},
+		{name: "ML service error", body: scam, mlErr: errors.New("model crashed"), want: http.StatusInternalServerError, wantStatus: "failed"},
+		{
+			name: "degraded", body: scam, mlErr: mlclient.ErrCircuitOpen, degrade: true,
+			want: http.StatusOK, wantStatus: "completed", wantScam: true, wantDegrade: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := mlclient.NewFakeAnalyzer()
+			analyzer.Err = tt.mlErr
+			router, checks := newAnalysisRouter(analyzer, tt.degrade)
+
+			rec := serve(router, http.MethodPost, "/analysis/text", tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.wantStatus == "" {
+				if analyzer.TextCalls != 0 {
+					t.Fatal("invalid request reached the ML service")
+				}
+				return
+			}
+
+			stored := checks.stored(1)
+			if stored.Status != tt.wantStatus || stored.Degraded != tt.wantDegrade {
+				t.Fatalf("stored check: status %q, degraded %v; want %q, %v", stored.Status, stored.Degraded, tt.wantStatus, tt.wantDegrade)
+			}
+			if rec.Code != http.StatusOK {
+				var response ErrorResponse
+				decodeBody(t, rec, &response)
+				if response.Error == "" {
+					t.Fatal("error response without a message")
+				}
+				return
+			}
+
+			var response struct {
+				CheckID    uint                      `json:"check_id"`
+				Degraded   bool                      `json:"degraded"`
+				Prediction mlclient.PredictionResult `json:"prediction"`
+			}
+			decodeBody(t, rec, &response)
+			if response.CheckID != 1 || response.Degraded != tt.wantDegrade || response.Prediction.IsScam != tt.wantScam {
+				t.Fatalf("response = %+v", response)
+			}
+			if tt.wantDegrade && stored.ModelName != models.RulesModelName {
+				t.Fatalf("degraded check scored by %q, want %q", stored.ModelName, models.RulesModelName)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBatch(t *testing.T) {
+	const texts = `{"texts": ["Привет, как дела?", "Вы выиграли приз, переведите комиссию"]}`
+
+	tests := []struct {
+		name        string
+		body        string
+		mlErr       error
+		degrade     bool
+		want        int
+		wantItem    string
+		wantCode    string
+		wantDegrade bool
+	}{
+		{name: "success", body: texts, want: http.StatusOK, wantItem: models.BatchItemOK},
+		{name: "empty batch", body: `{"texts": []}`, want: http.StatusBadRequest},
+		{
+			name: "ML service unavailable", body: texts, mlErr: mlclient.ErrCircuitOpen,
+			want: http.StatusServiceUnavailable, wantItem: models.BatchItemFailed, wantCode: models.BatchErrorMLUnavailable,
+		},
+		{
+			name: "ML service timeout", body: texts, mlErr: context.DeadlineExceeded,
+			want: http.StatusGatewayTimeout, wantItem: models.BatchItemFailed, wantCode: models.BatchErrorMLTimeout,
+		},
+		{
+			name: "degraded", body: texts, mlErr: mlclient.ErrCircuitOpen, degrade: true,
+			want: http.StatusOK, wantItem: models.BatchItemDegraded, wantDegrade: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := mlclient.NewFakeAnalyzer()
+			analyzer.Err = tt.mlErr
+			router, _ := newAnalysisRouter(analyzer, tt.degrade)
+
+			rec := serve(router, http.MethodPost, "/analysis/batch", tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.wantItem == "" {
+				return
+			}
+
+			var response BatchAnalysisResponse
+			decodeBody(t, rec, &response)
+			if response.Success != (tt.want == http.StatusOK) || response.Degraded != tt.wantDegrade || len(response.Items) != 2 {
+				t.Fatalf("response = %+v", response)
+			}
+			for i, item := range response.Items {
+				if item.Index != i || item.Status != tt.wantItem || item.ErrorCode != tt.wantCode {
+					t.Errorf("item %d = %+v, want status %q, code %q", i, item, tt.wantItem, tt.wantCode)
+				}
+			}
+			if tt.wantItem == models.BatchItemFailed {
+				if response.Error == "" || len(response.CheckIDs) != 0 {
+					t.Fatalf("failed batch: error %q, check ids %v", response.Error, response.CheckIDs)
+				}
+				return
+			}
+			if len(response.CheckIDs) != 2 || len(response.Predictions) != 2 || !response.Predictions[1].IsScam {
+				t.Fatalf("check ids %v, predictions %+v", response.CheckIDs, response.Predictions)
+			}
+		})
+	}
+}
+
+func TestMLHealthCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		mlErr error
+		want  int
+	}{
+		{name: "healthy", want: http.StatusOK},
+		{name: "unavailable", mlErr: errors.New("connection refused"), want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := mlclient.NewFakeAnalyzer()
+			analyzer.Err = tt.mlErr
+			router, _ := newAnalysisRouter(analyzer, false)
+
+			rec := serve(router, http.MethodGet, "/analysis/health", "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+
+			var response MLHealthResponse
+			decodeBody(t, rec, &response)
+			if tt.mlErr != nil {
+				if response.Error == "" || response.HealthResponse != nil {
+					t.Fatalf("response = %+v, want only an error", response)
+				}
+				return
+			}
+			if response.HealthResponse == nil || response.Status != "healthy" || response.ModelName != "fake" {
+				t.Fatalf("response = %+v", response)
+			}
+		})
+	}
+}
+
+func TestGetCheckHistory(t *testing.T) {
+	analyzer := mlclient.NewFakeAnalyzer()
+	router, checks := newAnalysisRouter(analyzer, false)
+	for _, text := range []string{"первая", "вторая", "третья"} {
+		if rec := serve(router, http.MethodPost, "/analysis/text", fmt.Sprintf(`{"text": %q}`, text)); rec.Code != http.StatusOK {
+			t.Fatalf("analyze %q: status %d", text, rec.Code)
+		}
+	}
+	// Проверка другого пользователя не попадает в историю
+	checks.CreateCheck(&models.Check{Title: "чужая", UserID: testUserID + 1})
+
+	tests := []struct {
+		name      string
+		query     string
+		listErr   error
+		want      int
+		wantIDs   []uint
+		wantTotal bool
+		wantPage  int
+		wantNext  bool
+	}{
+		{name: "first page", query: "?limit=2", want: http.StatusOK, wantIDs: []uint{3, 2}, wantTotal: true, wantPage: 1, wantNext: true},
+		{name: "last page", query: "?limit=2&page=2", want: http.StatusOK, wantIDs: []uint{1}, wantTotal: true, wantPage: 2},
+		{name: "without total", query: "?with_total=false", want: http.StatusOK, wantIDs: []uint{3, 2, 1}, wantPage: 1},
+		{name: "invalid with_total", query: "?with_total=maybe", want: http.StatusBadRequest},
+		{name: "storage failure", listErr: errors.New("connection reset"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks.listErr = tt.listErr
+
+			rec := serve(router, http.MethodGet, "/analysis/history"+tt.query, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var response CheckHistoryResponse
+			decodeBody(t, rec, &response)
+			var ids []uint
+			for _, check := range response.Checks {
+				ids = append(ids, check.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("checks = %v, want %v", ids, tt.wantIDs)
+			}
+			if (response.Total != nil) != tt.wantTotal || tt.wantTotal && *response.Total != 3 {
+				t.Fatalf("total = %v, want present %v with 3", response.Total, tt.wantTotal)
+			}
+			if response.Page != tt.wantPage || (response.NextCursor != "") != tt.wantNext {
+				t.Fatalf("page %d, next cursor %q; want page %d, next %v", response.Page, response.NextCursor, tt.wantPage, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestBatchAnalysisStatus(t *testing.T) {
+	storage := errors.New("connection reset")
+
+	tests := []struct {
+		name   string
+		result services.BatchAnalysis
+		want   int
+	}{
+		{name: "all texts processed", result: services.BatchAnalysis{Succeeded: 3}, want: http.StatusOK},
+		{name: "all texts degraded", result: services.BatchAnalysis{Succeeded: 3, Degraded: true}, want: http.StatusOK},
+		{
+			name:   "some texts failed",
+			result: services.BatchAnalysis{Succeeded: 2, Err: fmt.Errorf("%w: missing prediction", services.ErrMLFailed)},
+			want:   http.StatusMultiStatus,
+		},
+		{
+			name:   "partial storage failure",
+			result: services.BatchAnalysis{Succeeded: 1, Err: storage},
+			want:   http.StatusMultiStatus,
+		},
+		{
+			name:   "ML service unavailable",
+			result: services.BatchAnalysis{Err: fmt.Errorf("%w: circuit open", services.ErrMLUnavailable)},
+			want:   http.StatusServiceUnavailable,
+		},
+		{
+			name:   "ML service timeout",
+			result: services.BatchAnalysis{Err: fmt.Errorf("%w: deadline exceeded", services.ErrMLTimeout)},
+			want:   http.StatusGatewayTimeout,
+		},
+		{name: "nothing stored", result: services.BatchAnalysis{Err: storage}, want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchAnalysisStatus(&tt.result); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCheckHistoryQueryValidation(t *testing.T) {
+	router, checks := newAnalysisRouter(mlclient.NewFakeAnalyzer(), false)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "invalid min_score", query: "min_score=high", want: http.StatusBadRequest},
+		{name: "invalid max_score", query: "max_score=1,5", want: http.StatusBadRequest},
+		{name: "from is not RFC3339", query: "from=2026-03-01", want: http.StatusBadRequest},
+		{name: "invalid to", query: "to=yesterday", want: http.StatusBadRequest},
+		{name: "invalid has_feedback", query: "has_feedback=maybe", want: http.StatusBadRequest},
+		{name: "invalid order", query: "order=sideways", want: http.StatusBadRequest},
+		{name: "invalid with_total", query: "with_total=2", want: http.StatusBadRequest},
+		{name: "unknown danger level", query: "danger_level=low,extreme", want: http.StatusBadRequest},
+		{name: "unknown status", query: "status=deleted", want: http.StatusBadRequest},
+		{name: "min_score above max_score", query: "min_score=0.8&max_score=0.2", want: http.StatusBadRequest},
+		{name: "empty period", query: "from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z", want: http.StatusBadRequest},
+		{name: "unknown sort", query: "sort=title", want: http.StatusBadRequest},
+		{name: "relevance without search", query: "sort=relevance", want: http.StatusBadRequest},
+		{name: "valid", query: "danger_level=high&status=completed&q=карта&sort=relevance", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks.lastFilter = nil
+
+			rec := serve(router, http.MethodGet, "/analysis/history?"+tt.query, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusBadRequest {
+				var response ErrorResponse
+				decodeBody(t, rec, &response)
+				if response.Error == "" || checks.lastFilter != nil {
+					t.Fatalf("error %q, repository called %v", response.Error, checks.lastFilter != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCheckHistoryFilter(t *testing.T) {
+	router, checks := newAnalysisRouter(mlclient.NewFakeAnalyzer(), false)
+
+	query := "danger_level=high,critical&danger_level=%20medium&status=completed&content_type=url" +
+		"&min_score=0.25&max_score=0.9&from=2026-03-01T00:00:00%2B03:00&to=2026-03-08T00:00:00Z" +
+		"&has_feedback=false&q=%20карта%20&order=asc"
+	if rec := serve(router, http.MethodGet, "/analysis/history?"+query, ""); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	filter := checks.lastFilter
+	if filter == nil {
+		t.Fatal("repository was not called")
+	}
+	if !slices.Equal(filter.DangerLevels, []string{"high", "critical", "medium"}) || !slices.Equal(filter.Statuses, []string{"completed"}) {
+		t.Fatalf("levels %v, statuses %v", filter.DangerLevels, filter.Statuses)
+	}
+	if filter.ContentType != "url" || *filter.MinScore != 0.25 || *filter.MaxScore != 0.9 || *filter.HasFeedback {
+		t.Fatalf("filter = %+v", *filter)
+	}
+	if !filter.From.Equal(time.Date(2026, 2, 28, 21, 0, 0, 0, time.UTC)) || !filter.To.Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("period = %v - %v", filter.From, filter.To)
+	}
+	// Без sort поиск упорядочен по релевантности
+	if filter.Query != "карта" || filter.Sort != models.HistorySortRelevance || !filter.Asc {
+		t.Fatalf("query %q, sort %q, asc %v", filter.Query, filter.Sort, filter.Asc)
+	}
+}
+
+func TestGetCheckHistoryCursor(t *testing.T) {
+	router, _ := newAnalysisRouter(mlclient.NewFakeAnalyzer(), false)
+	for _, text := range []string{"первая", "вторая", "третья"} {
+		if rec := serve(router, http.MethodPost, "/analysis/text", fmt.Sprintf(`{"text": %q}`, text)); rec.Code != http.StatusOK {
+			t.Fatalf("analyze %q: status %d", text, rec.Code)
+		}
+	}
+
+	rec := serve(router, http.MethodGet, "/analysis/history?limit=2", "")
+	var first CheckHistoryResponse
+	decodeBody(t, rec, &first)
+	if first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("first page cursors: next %q, prev %q", first.NextCursor, first.PrevCursor)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "next page", query: "limit=2&cursor=" + first.NextCursor, want: http.StatusOK},
+		{name: "tampered cursor", query: "limit=2&cursor=x" + first.NextCursor, want: http.StatusBadRequest},
+		{name: "malformed cursor", query: "limit=2&cursor=%7B%7D", want: http.StatusBadRequest},
+		{name: "cursor of another order", query: "limit=2&order=asc&cursor=" + first.NextCursor, want: http.StatusBadRequest},
+		{name: "cursor of another sort", query: "limit=2&sort=danger_score&cursor=" + first.NextCursor, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodGet, "/analysis/history?"+tt.query, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			// Страница по курсору без номера страницы, с курсором назад
+			var response CheckHistoryResponse
+			decodeBody(t, rec, &response)
+			if response.Page != 0 || response.PrevCursor == "" || response.NextCursor != "" {
+				t.Fatalf("page %d, next %q, prev %q", response.Page, response.NextCursor, response.PrevCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey *models.APIKey `json:"api_key"`
+	// Key показывается только один раз
+	Key string `json:"key"`
+}
+
+// CreateAPIKey godoc
+// @Summary      Создать ключ API
+// @Description  Выпускает ключ для gRPC API: клиент передаёт его в метаданных x-api-key вместо токена доступа. Ключ действует от имени пользователя и возвращается только в этом ответе
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        request body CreateAPIKeyRequest true "Название ключа"
+// @Success      201 {object} CreateAPIKeyResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, secret, err := h.apiKeyService.Create(c.Request.Context(), userID, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAPIKeyName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTooManyAPIKeys):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать ключ"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+// ListAPIKeys godoc
+// @Summary      Список ключей API
+// @Description  Возвращает ключи текущего пользователя без секретов: только начало ключа и время последнего использования
+// @Tags         api-keys
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200 {array} models.APIKey
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить ключи"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// DeleteAPIKey godoc
+// @Summary      Отозвать ключ API
+// @Description  Удаляет ключ: запросы с ним сразу перестают проходить
+// @Tags         api-keys
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID ключа"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный id ключа"})
+		return
+	}
+
+	if err := h.apiKeyService.Delete(c.Request.Context(), userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось удалить ключ"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ключ отозван"})
+}
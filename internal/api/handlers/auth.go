@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"scam-detection-backend/internal/api/middleware"
 	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/passwordpolicy"
 	"scam-detection-backend/internal/services"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,19 +14,31 @@ import (
 type AuthHandler struct {
 	authService *services.AuthService
 	userService services.UserService
+	cookies     CookieSettings
 }
 
-func NewAuthHandler(authService *services.AuthService, userService services.UserService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, userService services.UserService, cookies CookieSettings) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		userService: userService,
+		cookies:     cookies,
 	}
 }
 
 type RegisterRequest struct {
 	Username string  `json:"username" binding:"required,min=3"`
 	Email    *string `json:"email" binding:"omitempty,email"`
-	Password string  `json:"password" binding:"required,min=6"`
+	Password string  `json:"password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"cur_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type PasswordPolicyErrorResponse struct {
+	Error      string                     `json:"error"`
+	Violations []passwordpolicy.Violation `json:"violations"`
 }
 
 type LoginRequest struct {
@@ -47,7 +60,7 @@ type AuthResponse struct {
 // @Produce      json
 // @Param        request body RegisterRequest true "Данные для регистрации"
 // @Success      201 {object} AuthResponse
-// @Failure      400 {object} map[string]string
+// @Failure      400 {object} PasswordPolicyErrorResponse "Пароль не соответствует политике"
 // @Router       /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -64,11 +77,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, tokens, err := h.authService.Register(c.Request.Context(), createReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondPasswordError(c, err)
 		return
 	}
 
-	h.setTokenCookies(c, tokens)
+	if err := h.cookies.setTokenCookies(c, tokens); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
 
 	c.JSON(http.StatusCreated, AuthResponse{
 		User:         user,
@@ -100,7 +116,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	h.setTokenCookies(c, tokens)
+	if err := h.cookies.setTokenCookies(c, tokens); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
 
 	c.JSON(http.StatusOK, AuthResponse{
 		User:         user,
@@ -135,24 +154,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		}
 	}
 
-	c.SetCookie(
-		"access_token",
-		"",
-		-1,
-		"/",
-		"",
-		false,
-		true,
-	)
-	c.SetCookie(
-		"refresh_token",
-		"",
-		-1,
-		"/",
-		"",
-		false,
-		true,
-	)
+	h.cookies.clearTokenCookies(c)
 
 	c.JSON(http.StatusOK, gin.H{"message": "успешный выход"})
 }
@@ -178,7 +180,10 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	h.setTokenCookies(c, tokens)
+	if err := h.cookies.setTokenCookies(c, tokens); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "токены обновлены",
@@ -213,27 +218,85 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-func (h *AuthHandler) setTokenCookies(c *gin.Context, tokens *models.TokenPair) {
-	accessMaxAge := int(time.Until(tokens.AccessExpiry).Seconds())
-	refreshMaxAge := int(time.Until(tokens.RefreshExpry).Seconds())
-
-	c.SetCookie(
-		"access_token",
-		tokens.AccessToken,
-		accessMaxAge,
-		"/",
-		"",
-		false,
-		true,
-	)
-
-	c.SetCookie(
-		"refresh_token",
-		tokens.RefreshToken,
-		refreshMaxAge,
-		"/",
-		"",
-		false,
-		true,
-	)
+// ChangePassword godoc
+// @Summary      Смена пароля
+// @Description  Проверяет текущий пароль, применяет политику паролей к новому, отзывает все сессии и выдаёт новые токены
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        request body ChangePasswordRequest true "Текущий и новый пароль"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} PasswordPolicyErrorResponse "Пароль не соответствует политике"
+// @Failure      401 {object} map[string]string
+// @Router       /profile/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateReq := &models.UpdatePasswordRequest{
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}
+
+	tokens, err := h.authService.ChangePassword(c.Request.Context(), userID, updateReq)
+	if err != nil {
+		if errors.Is(err, services.ErrWrongPassword) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		respondPasswordError(c, err)
+		return
+	}
+
+	if err := h.cookies.setTokenCookies(c, tokens); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "пароль успешно изменён"})
+}
+
+func respondPasswordError(c *gin.Context, err error) {
+	var violationErr *passwordpolicy.ViolationError
+	if errors.As(err, &violationErr) {
+		c.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse{
+			Error:      "пароль не соответствует политике",
+			Violations: violationErr.Violations,
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+type CSRFTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// CSRFToken godoc
+// @Summary      Получить CSRF токен
+// @Description  Выдаёт новый double-submit токен в cookie csrf_token; его значение нужно передавать в заголовке X-CSRF-Token для POST/PUT/DELETE запросов с cookie-аутентификацией
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} CSRFTokenResponse
+// @Failure      500 {object} map[string]string
+// @Router       /auth/csrf [get]
+func (h *AuthHandler) CSRFToken(c *gin.Context) {
+	token, err := h.cookies.setCSRFCookie(c, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CSRFTokenResponse{CSRFToken: token})
 }
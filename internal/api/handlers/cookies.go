@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CookieSettings struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+func (s CookieSettings) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	c.SetSameSite(s.SameSite)
+	c.SetCookie(name, value, maxAge, "/", s.Domain, s.Secure, httpOnly)
+}
+
+// setTokenCookies выставляет cookies сессии вместе с CSRF токеном. Если
+// токен создать не удалось, не выставляется ни одна cookie: без него
+// изменяющие запросы с cookie-аутентификацией всё равно будут отклонены.
+func (s CookieSettings) setTokenCookies(c *gin.Context, tokens *models.TokenPair) error {
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	accessMaxAge := int(time.Until(tokens.AccessExpiry).Seconds())
+	refreshMaxAge := int(time.Until(tokens.RefreshExpry).Seconds())
+
+	s.setCookie(c, "access_token", tokens.AccessToken, accessMaxAge, true)
+	s.setCookie(c, "refresh_token", tokens.RefreshToken, refreshMaxAge, true)
+	s.setCookie(c, middleware.CSRFCookieName, csrfToken, refreshMaxAge, false)
+	return nil
+}
+
+func (s CookieSettings) clearTokenCookies(c *gin.Context) {
+	s.setCookie(c, "access_token", "", -1, true)
+	s.setCookie(c, "refresh_token", "", -1, true)
+	s.setCookie(c, middleware.CSRFCookieName, "", -1, false)
+}
+
+// setCSRFCookie выдаёт новый double-submit токен. Cookie не HttpOnly:
+// фронтенд читает её и отправляет значение в заголовке X-CSRF-Token.
+func (s CookieSettings) setCSRFCookie(c *gin.Context, maxAge int) (string, error) {
+	token, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.setCookie(c, middleware.CSRFCookieName, token, maxAge, false)
+	return token, nil
+}
+
+func newCSRFToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/events"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventsWriteTimeout = 10 * time.Second
+	// Пустое событие ping не даёт прокси закрыть простаивающее соединение
+	eventsPingInterval = 30 * time.Second
+)
+
+type EventsHandler struct {
+	bus events.Bus
+}
+
+func NewEventsHandler(bus events.Bus) *EventsHandler {
+	return &EventsHandler{bus: bus}
+}
+
+// Subscribe godoc
+// @Summary      События пользователя (WebSocket)
+// @Description  Открывает WebSocket, в который сервер отправляет JSON события {type, data, time}: check.status (проверка перешла в processing/completed/failed), check.feedback (сохранена оценка пользователя), check.revised (новая ревизия после повторного анализа администратором), check.rescored (проверка, оценённая только правилами, пересчитана моделью), batch.progress и batch.completed (прогресс пакетного анализа), а также ping раз в 30 секунд. Браузер аутентифицируется cookie access_token, соединение принимается только с доверенного Origin. Если клиент не успевает читать события, сервер закрывает соединение; после переподключения стоит перечитать историю
+// @Tags         events
+// @Success      101 "Switching Protocols"
+// @Failure      401 {object} map[string]string "Не авторизован"
+// @Failure      403 {object} map[string]string "Недоверенный Origin"
+// @Security     BearerAuth
+// @Router       /events/ws [get]
+func (h *EventsHandler) Subscribe(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не аутентифицирован"})
+		return
+	}
+
+	server := websocket.Server{
+		// Origin уже проверен WebSocketOriginMiddleware; проверка по
+		// умолчанию отклонила бы клиентов без заголовка Origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.serve(c.Request.Context(), conn, userID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *EventsHandler) serve(ctx context.Context, conn *websocket.Conn, userID uint) {
+	defer conn.Close()
+
+	sub := h.bus.Subscribe(userID)
+	defer sub.Close()
+
+	// Входящие сообщения не используются; чтение нужно, чтобы заметить
+	// закрытие соединения клиентом
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var message string
+		for websocket.Message.Receive(conn, &message) == nil {
+		}
+	}()
+
+	ticker := time.NewTicker(eventsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		var event events.Event
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+			event = events.Event{Type: "ping", Time: time.Now()}
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			event = e
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+		if err := websocket.JSON.Send(conn, event); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/events"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// newEventsServer поднимает EventsHandler; пользователь берётся из
+// заголовка X-User, без него запрос не аутентифицирован.
+func newEventsServer(t *testing.T, bus events.Bus) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			id, _ := stringToInt(user)
+			c.Set(middleware.UserIDKey, uint(id))
+		}
+	})
+	router.GET("/events/ws", NewEventsHandler(bus).Subscribe)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dialEvents(t *testing.T, server *httptest.Server, user string) *websocket.Conn {
+	t.Helper()
+
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/events/ws", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Header.Set("X-User", user)
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitSubscribed публикует событие, пока подписка соединения не начнёт его
+// получать: Subscribe вызывается уже после рукопожатия.
+func waitSubscribed(t *testing.T, hub *events.Hub, conn *websocket.Conn, userID uint) {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	received := make(chan error, 1)
+	go func() {
+		var event events.Event
+		received <- websocket.JSON.Receive(conn, &event)
+	}()
+	for {
+		hub.Publish(context.Background(), events.Event{Type: "ready", UserID: userID})
+		select {
+		case err := <-received:
+			if err != nil {
+				t.Fatalf("waiting for subscription: %v", err)
+			}
+			// Лишние ready остаются в буфере; вычитываем их
+			conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			for {
+				var event events.Event
+				if websocket.JSON.Receive(conn, &event) != nil {
+					break
+				}
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestEventsSubscribeUnauthenticated(t *testing.T) {
+	server := newEventsServer(t, events.NewHub(0))
+
+	resp, err := http.Get(server.URL + "/events/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestEventsSubscribeDeliversUserEvents(t *testing.T) {
+	hub := events.NewHub(0)
+	server := newEventsServer(t, hub)
+	conn := dialEvents(t, server, "1")
+	waitSubscribed(t, hub, conn, 1)
+
+	hub.Publish(context.Background(), events.Event{Type: events.CheckFeedback, UserID: 2})
+	hub.Publish(context.Background(), events.Event{
+		Type:   events.CheckFeedback,
+		UserID: 1,
+		Data:   events.CheckFeedbackData{CheckID: 7, IsScam: true},
+	})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event struct {
+		Type string                   `json:"type"`
+		Data events.CheckFeedbackData `json:"data"`
+	}
+	if err := websocket.JSON.Receive(conn, &event); err != nil {
+		t.Fatal(err)
+	}
+	// Событие пользователя 2 опубликовано раньше, но до соединения не дошло
+	if event.Type != events.CheckFeedback || event.Data.CheckID != 7 || !event.Data.IsScam {
+		t.Fatalf("event = %+v, want feedback of check 7", event)
+	}
+}
+
+func TestEventsSubscribeClosesSlowClient(t *testing.T) {
+	hub := events.NewHub(1)
+	server := newEventsServer(t, hub)
+	conn := dialEvents(t, server, "1")
+	waitSubscribed(t, hub, conn, 1)
+
+	// Клиент не читает: буфер подписки переполняется, и хаб отключает её
+	const published = 100000
+	payload := strings.Repeat("x", 256)
+	for range published {
+		hub.Publish(context.Background(), events.Event{Type: events.CheckStatus, UserID: 1, Data: payload})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	delivered := 0
+	for {
+		var event events.Event
+		if err := websocket.JSON.Receive(conn, &event); err != nil {
+			if delivered >= published {
+				t.Fatalf("connection ended after all %d events: %v", delivered, err)
+			}
+			if strings.Contains(err.Error(), "timeout") {
+				t.Fatalf("server kept the connection of a slow client: %v", err)
+			}
+			return
+		}
+		delivered++
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService        *services.OIDCService
+	successRedirectURL string
+	cookies            CookieSettings
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService, successRedirectURL string, cookies CookieSettings) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:        oidcService,
+		successRedirectURL: successRedirectURL,
+		cookies:            cookies,
+	}
+}
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type OIDCAuthURLResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ListProviders godoc
+// @Summary      Список OIDC провайдеров
+// @Description  Возвращает имена настроенных внешних провайдеров входа
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} OIDCProvidersResponse
+// @Router       /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, OIDCProvidersResponse{Providers: h.oidcService.Providers()})
+}
+
+// Login godoc
+// @Summary      Вход через внешнего провайдера
+// @Description  Перенаправляет на страницу авторизации OIDC провайдера (authorization code + PKCE)
+// @Tags         auth
+// @Param        provider path string true "Имя провайдера"
+// @Success      302
+// @Failure      404 {object} map[string]string
+// @Failure      502 {object} map[string]string
+// @Router       /auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, err := h.oidcService.BeginAuth(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary      Callback OIDC провайдера
+// @Description  Обменивает code на токены, проверяет id_token, находит/привязывает/создаёт пользователя и выдаёт JWT токены в cookies
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "Имя провайдера"
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State"
+// @Success      200 {object} AuthResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "провайдер отклонил авторизацию: " + providerErr})
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "отсутствует code или state"})
+		return
+	}
+
+	user, tokens, err := h.oidcService.CompleteAuth(c.Request.Context(), c.Param("provider"), code, state)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if err := h.cookies.setTokenCookies(c, tokens); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать CSRF токен"})
+		return
+	}
+
+	if h.successRedirectURL != "" {
+		c.Redirect(http.StatusFound, h.successRedirectURL)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		User:         user,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+// ListIdentities godoc
+// @Summary      Привязанные внешние аккаунты
+// @Description  Возвращает внешние OIDC аккаунты текущего пользователя
+// @Tags         user
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200 {array} models.UserIdentity
+// @Failure      401 {object} map[string]string
+// @Router       /profile/identities [get]
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить привязанные аккаунты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// LinkIdentity godoc
+// @Summary      Привязать внешний аккаунт
+// @Description  Возвращает URL авторизации у провайдера; после callback внешний аккаунт привязывается к текущему пользователю
+// @Tags         user
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider path string true "Имя провайдера"
+// @Success      200 {object} OIDCAuthURLResponse
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /profile/identities/{provider} [post]
+func (h *OIDCHandler) LinkIdentity(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	authURL, err := h.oidcService.BeginAuth(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, OIDCAuthURLResponse{AuthorizationURL: authURL})
+}
+
+// UnlinkIdentity godoc
+// @Summary      Отвязать внешний аккаунт
+// @Description  Удаляет привязку внешнего OIDC аккаунта от текущего пользователя
+// @Tags         user
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider path string true "Имя провайдера"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /profile/identities/{provider} [delete]
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	if err := h.oidcService.Unlink(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "внешний аккаунт отвязан"})
+}
+
+func (h *OIDCHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider), errors.Is(err, services.ErrIdentityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidOIDCState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityAlreadyTaken),
+		errors.Is(err, services.ErrLastLoginMethod),
+		errors.Is(err, services.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "ошибка внешнего провайдера: " + err.Error()})
+	}
+}
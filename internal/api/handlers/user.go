@@ -5,20 +5,32 @@ import (
 	"scam-detection-backend/internal/api/middleware"
 	"scam-detection-backend/internal/models"
 	"scam-detection-backend/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService services.UserService
+	userService  services.UserService
+	auditService services.AuditService
+	cookies      CookieSettings
 }
 
-func NewUserHandler(userService services.UserService) *UserHandler {
+func NewUserHandler(userService services.UserService, auditService services.AuditService, cookies CookieSettings) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:  userService,
+		auditService: auditService,
+		cookies:      cookies,
 	}
 }
 
+type AuditEventsResponse struct {
+	Events []models.AuditEvent `json:"events"`
+	Total  int64               `json:"total"`
+	Page   int                 `json:"page"`
+	Limit  int                 `json:"limit"`
+}
+
 type UpdateProfileRequest struct {
 	Username *string `json:"username,omitempty" binding:"omitempty,min=3"`
 	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
@@ -59,6 +71,17 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	changed := make([]string, 0, 2)
+	if req.Username != nil {
+		changed = append(changed, "username")
+	}
+	if req.Email != nil {
+		changed = append(changed, "email")
+	}
+	h.auditService.Record(c.Request.Context(), models.AuditProfileUpdated, userID, map[string]interface{}{
+		"fields": changed,
+	})
+
 	user, err := h.userService.GetByID(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить обновлённого пользователя"})
@@ -68,13 +91,18 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+type DeleteAccountResponse struct {
+	Message     string    `json:"message"`
+	ScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
 // DeleteAccount godoc
 // @Summary      Удалить аккаунт
-// @Description  Полностью удаляет аккаунт текущего пользователя
+// @Description  Помечает аккаунт на удаление и отзывает все сессии. До указанной даты аккаунт можно восстановить, просто войдя в систему; после неё все данные пользователя удаляются безвозвратно
 // @Tags         user
 // @Produce      json
 // @Security     CookieAuth
-// @Success      200 {object} map[string]string
+// @Success      200 {object} DeleteAccountResponse
 // @Failure      401 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /account [delete]
@@ -85,13 +113,73 @@ func (h *UserHandler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.Delete(userID); err != nil {
+	scheduledAt, err := h.userService.RequestDeletion(c.Request.Context(), userID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось удалить аккаунт"})
 		return
 	}
 
-	c.SetCookie("access_token", "", -1, "/", "", false, true)
-	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+	h.auditService.Record(c.Request.Context(), models.AuditAccountDeleted, userID, map[string]interface{}{
+		"scheduled_at": scheduledAt,
+	})
+
+	h.cookies.clearTokenCookies(c)
+
+	c.JSON(http.StatusOK, DeleteAccountResponse{
+		Message:     "аккаунт будет удалён; войдите до указанной даты, чтобы восстановить его",
+		ScheduledAt: scheduledAt,
+	})
+}
+
+// GetSecurityEvents godoc
+// @Summary      Журнал безопасности
+// @Description  Возвращает события безопасности текущего пользователя (входы, обновления токенов, изменения профиля) с пагинацией
+// @Tags         user
+// @Produce      json
+// @Security     CookieAuth
+// @Param        page query int false "Номер страницы" default(1)
+// @Param        limit query int false "Количество записей на странице" default(20)
+// @Success      200 {object} AuditEventsResponse
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /profile/security-events [get]
+func (h *UserHandler) GetSecurityEvents(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	page, limit := parsePagination(c)
+
+	events, total, err := h.auditService.ListForUser(c.Request.Context(), userID, limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить журнал безопасности"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditEventsResponse{
+		Events: events,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	})
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page := 1
+	if p, exists := c.GetQuery("page"); exists {
+		if val, err := stringToInt(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	limit := 20
+	if l, exists := c.GetQuery("limit"); exists {
+		if val, err := stringToInt(l); err == nil && val > 0 && val <= 100 {
+			limit = val
+		}
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "аккаунт успешно удалён"})
+	return page, limit
 }
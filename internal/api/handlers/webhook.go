@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scam-detection-backend/internal/api/middleware"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=check.completed check.high_risk check.feedback"`
+}
+
+type CreateWebhookResponse struct {
+	Webhook *models.Webhook `json:"webhook"`
+	// Secret показывается только один раз
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+}
+
+// CreateWebhook godoc
+// @Summary      Создать вебхук
+// @Description  Подписывает URL на события проверок: check.completed, check.high_risk (завершённая проверка с уровнем high или critical), check.feedback. Запросы подписываются заголовком X-Webhook-Signature: sha256=HMAC-SHA256(secret, "<X-Webhook-Timestamp>.<тело>") в hex. Секрет возвращается только в этом ответе
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        request body CreateWebhookRequest true "URL и события"
+// @Success      201 {object} CreateWebhookResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, secret, err := h.webhookService.Create(c.Request.Context(), userID, req.URL, req.Events)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookURL) || errors.Is(err, services.ErrInvalidWebhookEvents) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось создать вебхук"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: webhook, Secret: secret})
+}
+
+// ListWebhooks godoc
+// @Summary      Список вебхуков
+// @Description  Возвращает вебхуки текущего пользователя
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200 {array} models.Webhook
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return
+	}
+
+	webhooks, err := h.webhookService.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось получить вебхуки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook godoc
+// @Summary      Удалить вебхук
+// @Description  Удаляет вебхук вместе с журналом доставок
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID вебхука"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, id, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), userID, id); err != nil {
+		h.respondError(c, err, "не удалось удалить вебхук")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "вебхук удалён"})
+}
+
+// TestWebhook godoc
+// @Summary      Отправить тестовое событие
+// @Description  Сразу отправляет на URL вебхука событие webhook.test и возвращает результат попытки. При неудаче доставка повторяется по обычному расписанию
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID вебхука"
+// @Success      200 {object} models.WebhookDelivery
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks/{id}/test [post]
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	userID, id, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondError(c, err, "не удалось отправить тестовое событие")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// GetDeliveries godoc
+// @Summary      Журнал доставок
+// @Description  Возвращает доставки вебхука, новые первыми. Статусы: pending - ждёт отправки или повтора, succeeded - получатель ответил 2xx, dead - попытки исчерпаны
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID вебхука"
+// @Param        page query int false "Номер страницы" default(1)
+// @Param        limit query int false "Количество записей на странице" default(20)
+// @Success      200 {object} WebhookDeliveriesResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userID, id, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	page, limit := parsePagination(c)
+
+	deliveries, total, err := h.webhookService.Deliveries(c.Request.Context(), userID, id, limit, (page-1)*limit)
+	if err != nil {
+		h.respondError(c, err, "не удалось получить журнал доставок")
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	})
+}
+
+// RetryDelivery godoc
+// @Summary      Повторить доставку
+// @Description  Сбрасывает счётчик попыток и ставит доставку в очередь, в том числе из состояния dead
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        id path int true "ID вебхука"
+// @Param        delivery_id path int true "ID доставки"
+// @Success      202 {object} models.WebhookDelivery
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	userID, id, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := stringToInt(c.Param("delivery_id"))
+	if err != nil || deliveryID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный id доставки"})
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), userID, id, uint(deliveryID))
+	if err != nil {
+		h.respondError(c, err, "не удалось повторить доставку")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) webhookParams(c *gin.Context) (uint, uint, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+		return 0, 0, false
+	}
+
+	id, err := stringToInt(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный id вебхука"})
+		return 0, 0, false
+	}
+
+	return userID, uint(id), true
+}
+
+func (h *WebhookHandler) respondError(c *gin.Context, err error, message string) {
+	if errors.Is(err, services.ErrWebhookNotFound) || errors.Is(err, services.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
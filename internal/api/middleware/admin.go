@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware пропускает только администраторов. Должен идти после
+// AuthMiddleware.
+func AdminMiddleware(userService services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "пользователь не найден"})
+			c.Abort()
+			return
+		}
+
+		user, err := userService.GetByID(userID)
+		if err != nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "недостаточно прав"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
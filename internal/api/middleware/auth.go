@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"scam-detection-backend/internal/services"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,16 +15,27 @@ const (
 
 func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		accessToken, err := c.Cookie("access_token")
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "токен не найден"})
-			c.Abort()
-			return
+		accessToken, ok := bearerToken(c)
+		if !ok {
+			var err error
+			accessToken, err = c.Cookie("access_token")
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "токен не найден"})
+				c.Abort()
+				return
+			}
 		}
 
 		userID, err := authService.ValidateToken(accessToken)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "невалидный токен"})
+			switch {
+			case errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrAccountPendingDeletion):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrAccountCheckFailed):
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": services.ErrAccountCheckFailed.Error()})
+			default:
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "невалидный токен"})
+			}
 			c.Abort()
 			return
 		}
@@ -32,6 +45,15 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	}
 }
 
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
 func GetUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get(UserIDKey)
 	if !exists {
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CSRFMiddleware([]string{"https://app.example.com"}))
+	router.POST("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	withCookies := func(r *http.Request) {
+		r.AddCookie(&http.Cookie{Name: "access_token", Value: "jwt"})
+		r.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "csrf"})
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(r *http.Request)
+		want    int
+	}{
+		{name: "no auth cookies", prepare: func(r *http.Request) {}, want: http.StatusNoContent},
+		{name: "bearer token", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set("Authorization", "Bearer jwt")
+			r.Header.Set("Origin", "https://evil.example.com")
+		}, want: http.StatusNoContent},
+		{name: "non-bearer authorization falls back to cookie", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set("Authorization", "x")
+		}, want: http.StatusForbidden},
+		{name: "empty bearer falls back to cookie", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set("Authorization", "Bearer ")
+		}, want: http.StatusForbidden},
+		{name: "cookie without token header", prepare: withCookies, want: http.StatusForbidden},
+		{name: "cookie with matching token", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set(CSRFHeaderName, "csrf")
+			r.Header.Set("Origin", "https://app.example.com")
+		}, want: http.StatusNoContent},
+		{name: "cookie with mismatched token", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set(CSRFHeaderName, "other")
+		}, want: http.StatusForbidden},
+		{name: "untrusted origin", prepare: func(r *http.Request) {
+			withCookies(r)
+			r.Header.Set(CSRFHeaderName, "csrf")
+			r.Header.Set("Origin", "https://evil.example.com")
+		}, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://api.example.com/", nil)
+			tt.prepare(req)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketOriginMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(WebSocketOriginMiddleware([]string{"https://app.example.com"}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name          string
+		authorization string
+		origin        string
+		want          int
+	}{
+		{name: "trusted origin", origin: "https://app.example.com", want: http.StatusNoContent},
+		{name: "untrusted origin", origin: "https://evil.example.com", want: http.StatusForbidden},
+		{name: "non-bearer authorization", authorization: "x", origin: "https://evil.example.com", want: http.StatusForbidden},
+		{name: "bearer token", authorization: "Bearer jwt", origin: "https://evil.example.com", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/", nil)
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: "jwt"})
+			req.Header.Set("Origin", tt.origin)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"scam-detection-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetaMiddleware кладёт IP и User-Agent клиента в контекст запроса
+// для журнала аудита.
+func RequestMetaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := services.WithRequestMeta(c.Request.Context(), c.ClientIP(), c.Request.UserAgent())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
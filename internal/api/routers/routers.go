@@ -3,27 +3,46 @@ package routes
 import (
 	"scam-detection-backend/internal/api/handlers"
 	"scam-detection-backend/internal/api/middleware"
-	"scam-detection-backend/internal/repository"
+	"scam-detection-backend/internal/config"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
 	"scam-detection-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, authService *services.AuthService, userService services.UserService) {
-	authHandler := handlers.NewAuthHandler(authService, userService)
-	userHandler := handlers.NewUserHandler(userService)
+func SetupRoutes(r *gin.Engine, cfg *config.Config, analyzer mlclient.Analyzer, experiment *services.ModelExperiment, analysisService *services.AnalysisService, batchService *services.BatchService, reanalysisService *services.ReanalysisService, authService *services.AuthService, userService services.UserService, oidcService *services.OIDCService, auditService services.AuditService, bus events.Bus, webhookService *services.WebhookService, apiKeyService *services.APIKeyService) {
+	cookies := handlers.CookieSettings{
+		Domain:   cfg.Cookie.Domain,
+		Secure:   cfg.Cookie.Secure,
+		SameSite: cfg.Cookie.SameSiteMode(),
+	}
+
+	authHandler := handlers.NewAuthHandler(authService, userService, cookies)
+	userHandler := handlers.NewUserHandler(userService, auditService, cookies)
+	adminHandler := handlers.NewAdminHandler(auditService, experiment, reanalysisService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, cfg.OIDC.SuccessRedirectURL, cookies)
 
-	checkRepo := repository.NewCheckRepository(db)
-	analysisHandler := handlers.NewAnalysisHandler(checkRepo)
+	analysisHandler := handlers.NewAnalysisHandler(analysisService, analyzer, batchService)
+	eventsHandler := handlers.NewEventsHandler(bus)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
 
 	api := r.Group("/api/v1")
+	api.Use(middleware.RequestMetaMiddleware())
+	if cfg.CSRF.Enabled {
+		api.Use(middleware.CSRFMiddleware(cfg.Server.AllowedOrigins))
+	}
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.GET("/csrf", authHandler.CSRFToken)
+			auth.GET("/oidc/providers", oidcHandler.ListProviders)
+			auth.GET("/oidc/:provider/login", oidcHandler.Login)
+			auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 		}
 
 		authProtected := api.Group("/auth")
@@ -42,9 +61,21 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, authService *services.AuthService,
 		{
 			analysis.POST("/text", analysisHandler.AnalyzeText)
 			analysis.POST("/batch", analysisHandler.AnalyzeBatch)
+			analysis.GET("/batch/:id", analysisHandler.GetBatch)
+			analysis.GET("/batch/:id/items", analysisHandler.GetBatchItems)
+			analysis.GET("/batch/:id/stream", analysisHandler.StreamBatch)
 			analysis.GET("/history", analysisHandler.GetCheckHistory)
 			analysis.DELETE("/history/:id", analysisHandler.DeleteCheck)
+			analysis.POST("/history/:id/feedback", analysisHandler.SubmitFeedback)
+			analysis.GET("/history/:id/revisions", analysisHandler.GetCheckRevisions)
 			analysis.GET("/stats", analysisHandler.GetStats)
+			analysis.GET("/stats/timeseries", analysisHandler.GetStatsTimeSeries)
+		}
+
+		eventsGroup := api.Group("/events")
+		eventsGroup.Use(middleware.WebSocketOriginMiddleware(cfg.Server.AllowedOrigins), middleware.AuthMiddleware(authService))
+		{
+			eventsGroup.GET("/ws", eventsHandler.Subscribe)
 		}
 
 		protected := api.Group("")
@@ -52,7 +83,31 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, authService *services.AuthService,
 		{
 			protected.GET("/profile", authHandler.GetProfile)
 			protected.PUT("/profile", userHandler.UpdateProfile)
+			protected.PUT("/profile/password", authHandler.ChangePassword)
+			protected.GET("/profile/identities", oidcHandler.ListIdentities)
+			protected.POST("/profile/identities/:provider", oidcHandler.LinkIdentity)
+			protected.DELETE("/profile/identities/:provider", oidcHandler.UnlinkIdentity)
 			protected.DELETE("/account", userHandler.DeleteAccount)
+			protected.GET("/profile/security-events", userHandler.GetSecurityEvents)
+			protected.POST("/webhooks", webhookHandler.CreateWebhook)
+			protected.GET("/webhooks", webhookHandler.ListWebhooks)
+			protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			protected.POST("/webhooks/:id/test", webhookHandler.TestWebhook)
+			protected.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+			protected.POST("/webhooks/:id/deliveries/:delivery_id/retry", webhookHandler.RetryDelivery)
+			protected.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+			protected.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+			protected.DELETE("/api-keys/:id", apiKeyHandler.DeleteAPIKey)
+		}
+
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(authService), middleware.AdminMiddleware(userService))
+		{
+			admin.GET("/audit-events", adminHandler.ListAuditEvents)
+			admin.GET("/models/comparison", adminHandler.CompareModels)
+			admin.POST("/reanalysis", adminHandler.StartReanalysis)
+			admin.GET("/reanalysis", adminHandler.ListReanalysisJobs)
+			admin.GET("/reanalysis/:id", adminHandler.GetReanalysisJob)
 		}
 	}
 }
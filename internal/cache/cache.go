@@ -0,0 +1,14 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache - хранилище байтовых значений с временем жизни. Промах возвращает
+// (nil, false, nil); ошибка означает недоступность хранилища, и вызывающий
+// код должен работать так, будто кэша нет.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisServer - минимальный RESP сервер в памяти для проверки Redis
+// бэкенда без настоящего Redis. Поддерживает PING, AUTH, SELECT, GET,
+// SET (с EX/PX), DEL и FLUSHALL.
+type fakeRedisServer struct {
+	listener net.Listener
+	password string
+
+	mu     sync.Mutex
+	values map[string]fakeRedisValue
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+
+	commands int
+	dials    int
+}
+
+type fakeRedisValue struct {
+	data      string
+	expiresAt time.Time
+}
+
+// newFakeRedisServer запускает сервер на случайном порту 127.0.0.1 и
+// останавливает его по t.Cleanup. Пустой password отключает AUTH.
+func newFakeRedisServer(t testing.TB, password string) *fakeRedisServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeRedisServer{
+		listener: listener,
+		password: password,
+		values:   make(map[string]fakeRedisValue),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func (s *fakeRedisServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRedisServer) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *fakeRedisServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.dials++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedisServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		if !authed && name != "AUTH" {
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+
+		reply := s.exec(name, args[1:], &authed)
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedisServer) exec(name string, args []string, authed *bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands++
+
+	switch name {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH":
+		if len(args) != 1 || args[0] != s.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 1 {
+			return "-ERR wrong number of arguments for 'get' command\r\n"
+		}
+		value, ok := s.values[args[0]]
+		if !ok || (!value.expiresAt.IsZero() && time.Now().After(value.expiresAt)) {
+			delete(s.values, args[0])
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value.data), value.data)
+	case "SET":
+		if len(args) < 2 {
+			return "-ERR wrong number of arguments for 'set' command\r\n"
+		}
+		value := fakeRedisValue{data: args[1]}
+		for i := 2; i+1 < len(args); i += 2 {
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return "-ERR value is not an integer or out of range\r\n"
+			}
+			switch strings.ToUpper(args[i]) {
+			case "PX":
+				value.expiresAt = time.Now().Add(time.Duration(n) * time.Millisecond)
+			case "EX":
+				value.expiresAt = time.Now().Add(time.Duration(n) * time.Second)
+			default:
+				return "-ERR syntax error\r\n"
+			}
+		}
+		s.values[args[0]] = value
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "FLUSHALL":
+		s.values = make(map[string]fakeRedisValue)
+		return "+OK\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", strings.ToLower(name))
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for range count {
+		header, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("expected bulk string, got %q", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Memory - LRU кэш в памяти процесса. При переполнении вытесняется запись,
+// к которой дольше всего не обращались; просроченные записи удаляются при
+// чтении.
+type Memory struct {
+	capacity int
+	// now подменяется в тестах
+	now func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+var _ Cache = (*Memory)(nil)
+
+func NewMemory(capacity int) *Memory {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+
+	return &Memory{
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if m.now().After(entry.expiresAt) {
+		m.order.Remove(elem)
+		delete(m.items, key)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+
+	if elem, ok := m.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.order.MoveToFront(elem)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryEntry).key)
+	}
+
+	return nil
+}
+
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestMemory(capacity int) (*Memory, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory(capacity)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestMemoryGetSet(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMemory(10)
+
+	if _, ok, err := m.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("Get(missing) = %v, %v, want miss", ok, err)
+	}
+
+	m.Set(ctx, "key", []byte("value"), time.Minute)
+	value, ok, err := m.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("Get(key) = %q, %v, %v, want hit", value, ok, err)
+	}
+
+	m.Set(ctx, "key", []byte("updated"), time.Minute)
+	if value, _, _ := m.Get(ctx, "key"); string(value) != "updated" {
+		t.Fatalf("Get(key) after update = %q", value)
+	}
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", m.Len())
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	ctx := context.Background()
+	m, now := newTestMemory(10)
+
+	m.Set(ctx, "key", []byte("value"), time.Minute)
+
+	*now = now.Add(59 * time.Second)
+	if _, ok, _ := m.Get(ctx, "key"); !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+
+	*now = now.Add(2 * time.Second)
+	if _, ok, _ := m.Get(ctx, "key"); ok {
+		t.Fatal("entry returned after its TTL")
+	}
+	if m.Len() != 0 {
+		t.Fatalf("expired entry was not removed, Len = %d", m.Len())
+	}
+
+	// Перезапись продлевает время жизни
+	m.Set(ctx, "key", []byte("value"), time.Minute)
+	*now = now.Add(30 * time.Second)
+	m.Set(ctx, "key", []byte("value"), time.Minute)
+	*now = now.Add(45 * time.Second)
+	if _, ok, _ := m.Get(ctx, "key"); !ok {
+		t.Fatal("rewritten entry expired with its old TTL")
+	}
+}
+
+func TestMemoryLRUEviction(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ops     func(m *Memory)
+		present []string
+		evicted []string
+	}{
+		{
+			name: "oldest entry is evicted",
+			ops: func(m *Memory) {
+				for _, key := range []string{"a", "b", "c", "d"} {
+					m.Set(ctx, key, []byte(key), time.Minute)
+				}
+			},
+			present: []string{"b", "c", "d"},
+			evicted: []string{"a"},
+		},
+		{
+			name: "read protects an entry from eviction",
+			ops: func(m *Memory) {
+				for _, key := range []string{"a", "b", "c"} {
+					m.Set(ctx, key, []byte(key), time.Minute)
+				}
+				m.Get(ctx, "a")
+				m.Set(ctx, "d", []byte("d"), time.Minute)
+			},
+			present: []string{"a", "c", "d"},
+			evicted: []string{"b"},
+		},
+		{
+			name: "update protects an entry from eviction",
+			ops: func(m *Memory) {
+				for _, key := range []string{"a", "b", "c"} {
+					m.Set(ctx, key, []byte(key), time.Minute)
+				}
+				m.Set(ctx, "a", []byte("a2"), time.Minute)
+				m.Set(ctx, "d", []byte("d"), time.Minute)
+			},
+			present: []string{"a", "c", "d"},
+			evicted: []string{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newTestMemory(3)
+			tt.ops(m)
+
+			if m.Len() != 3 {
+				t.Fatalf("Len = %d, want 3", m.Len())
+			}
+			for _, key := range tt.evicted {
+				if _, ok, _ := m.Get(ctx, key); ok {
+					t.Errorf("%s was not evicted", key)
+				}
+			}
+			for _, key := range tt.present {
+				if _, ok, _ := m.Get(ctx, key); !ok {
+					t.Errorf("%s was evicted", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const defaultRedisTimeout = 500 * time.Millisecond
+
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix добавляется ко всем ключам, чтобы делить инстанс Redis с другими
+	// сервисами
+	Prefix   string
+	PoolSize int
+	Timeout  time.Duration
+}
+
+// Redis - кэш поверх любого сервера с протоколом RESP (Redis, KeyDB,
+// Dragonfly, Valkey). Использует только GET и SET ... PX, поэтому не
+// требует клиентской библиотеки.
+type Redis struct {
+	cfg  RedisConfig
+	pool chan *redisConn
+}
+
+var _ Cache = (*Redis)(nil)
+
+type redisConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+var errRedisNil = errors.New("redis: nil")
+
+func NewRedis(cfg RedisConfig) *Redis {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 16
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultRedisTimeout
+	}
+
+	return &Redis{
+		cfg:  cfg,
+		pool: make(chan *redisConn, cfg.PoolSize),
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.cfg.Prefix+key)
+	if errors.Is(err, errRedisNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms := max(ttl.Milliseconds(), 1)
+	_, err := r.do(ctx, "SET", r.cfg.Prefix+key, string(value), "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.do(ctx, "PING")
+	return err
+}
+
+// Close закрывает простаивающие соединения пула.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case c := <-r.pool:
+			c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(r.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	reply, err := c.command(args...)
+
+	// Ответ-ошибка Redis и nil оставляют соединение в согласованном
+	// состоянии; после сетевой ошибки или ошибки протокола оно закрывается
+	var replyErr redisError
+	if err == nil || errors.Is(err, errRedisNil) || errors.As(err, &replyErr) {
+		r.release(c)
+	} else {
+		c.conn.Close()
+	}
+
+	return reply, err
+}
+
+func (r *Redis) acquire(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.pool:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	c := &redisConn{
+		conn: conn,
+		rw:   bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)),
+	}
+	conn.SetDeadline(time.Now().Add(r.cfg.Timeout))
+
+	if r.cfg.Password != "" {
+		if _, err := c.command("AUTH", r.cfg.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.cfg.DB != 0 {
+		if _, err := c.command("SELECT", strconv.Itoa(r.cfg.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (r *Redis) release(c *redisConn) {
+	select {
+	case r.pool <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	return readReply(c.rw.Reader)
+}
+
+// readReply разбирает один ответ RESP2. Массивы не нужны командам кэша и
+// считаются ошибкой протокола.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply: %w", err)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if size < 0 {
+			return nil, errRedisNil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return buf[:size], nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply type %q", line[0])
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("redis: %w", err)
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: malformed reply line")
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedisGetSet(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "")
+	r := NewRedis(RedisConfig{Addr: server.Addr(), Prefix: "app:"})
+	defer r.Close()
+
+	if _, ok, err := r.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("Get(missing) = %v, %v, want miss", ok, err)
+	}
+
+	// Значение с переводами строк и двоичными данными передаётся как есть
+	want := "line1\r\nline2\x00"
+	if err := r.Set(ctx, "key", []byte(want), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	value, ok, err := r.Get(ctx, "key")
+	if err != nil || !ok || string(value) != want {
+		t.Fatalf("Get(key) = %q, %v, %v, want %q", value, ok, err, want)
+	}
+
+	server.mu.Lock()
+	_, prefixed := server.values["app:key"]
+	server.mu.Unlock()
+	if !prefixed {
+		t.Fatal("key was stored without the configured prefix")
+	}
+}
+
+func TestRedisTTL(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "")
+	r := NewRedis(RedisConfig{Addr: server.Addr()})
+	defer r.Close()
+
+	if err := r.Set(ctx, "short", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(ctx, "long", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if _, ok, _ := r.Get(ctx, "short"); ok {
+		t.Fatal("entry returned after its TTL")
+	}
+	if _, ok, _ := r.Get(ctx, "long"); !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+}
+
+func TestRedisAuth(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "secret")
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{name: "correct password", password: "secret"},
+		{name: "wrong password", password: "other", wantErr: "WRONGPASS"},
+		{name: "no password", wantErr: "NOAUTH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRedis(RedisConfig{Addr: server.Addr(), Password: tt.password, DB: 2})
+			defer r.Close()
+
+			err := r.Set(ctx, "key", []byte("v"), time.Minute)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedisReusesConnections(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "")
+	r := NewRedis(RedisConfig{Addr: server.Addr(), PoolSize: 2})
+	defer r.Close()
+
+	for range 10 {
+		if err := r.Set(ctx, "key", []byte("v"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := r.Get(ctx, "key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server.mu.Lock()
+	dials := server.dials
+	server.mu.Unlock()
+	if dials != 1 {
+		t.Fatalf("sequential commands opened %d connections, want 1", dials)
+	}
+}
+
+func TestRedisUnavailable(t *testing.T) {
+	server := newFakeRedisServer(t, "")
+	addr := server.Addr()
+	server.Close()
+
+	r := NewRedis(RedisConfig{Addr: addr, Timeout: 100 * time.Millisecond})
+	if _, _, err := r.Get(context.Background(), "key"); err == nil {
+		t.Fatal("Get succeeded against a stopped server")
+	}
+}
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +15,121 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	JWT      JWTConfig
+	Password PasswordConfig
+	OIDC     OIDCConfig
+	Cookie   CookieConfig
+	CSRF     CSRFConfig
+	Account  AccountConfig
+	ML       MLConfig
+	Cache    CacheConfig
+	Batch    BatchConfig
+	Webhook  WebhookConfig
+}
+
+type BatchConfig struct {
+	MaxTexts  int
+	ChunkSize int
+	Workers   int
+}
+
+type WebhookConfig struct {
+	Timeout              time.Duration
+	MaxAttempts          int
+	RetryBaseDelay       time.Duration
+	RetryMaxDelay        time.Duration
+	PollInterval         time.Duration
+	AllowPrivateNetworks bool
+}
+
+// CacheConfig - кэш результатов анализа. Backend: memory, redis или none.
+type CacheConfig struct {
+	Backend    string
+	TTL        time.Duration
+	MemorySize int
+
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	KeyPrefix     string
+}
+
+type MLConfig struct {
+	ServiceURL string
+	Models     []MLModelConfig
+	Candidate  MLCandidateConfig
+
+	TextTimeout   time.Duration
+	BatchTimeout  time.Duration
+	HealthTimeout time.Duration
+
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+
+	MaxIdleConns    int
+	MaxConnsPerHost int
+
+	DegradeOnFailure bool
+	RescoreInterval  time.Duration
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod string
+	PurgeInterval       string
+}
+
+type CookieConfig struct {
+	Domain   string
+	Secure   bool
+	SameSite string
+}
+
+type CSRFConfig struct {
+	Enabled bool
+}
+
+type OIDCConfig struct {
+	Providers          []OIDCProviderConfig
+	SuccessRedirectURL string
+}
+
+type MLModelConfig struct {
+	Name      string
+	URL       string
+	Weight    float64
+	Languages []string
+}
+
+// MLCandidateConfig - новая модель, проверяемая в тени и/или на части
+// пользователей перед переводом в live.
+type MLCandidateConfig struct {
+	Name           string
+	URL            string
+	TrafficPercent int
+	Shadow         bool
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type PasswordConfig struct {
+	MinLength        int
+	MaxLength        int
+	RequireLower     bool
+	RequireUpper     bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	MinStrength      int
+	ForbidIdentity   bool
+	BreachedListPath string
 }
 
 type JWTConfig struct {
@@ -25,11 +144,18 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	Name     string
+	// MigrateOnStart: применять SQL миграции при запуске сервера
+	MigrateOnStart bool
 }
 
 type ServerConfig struct {
-	Port string
-	Mode string
+	Port           string
+	Mode           string
+	AllowedOrigins []string
+	AdminUsernames []string
+	// gRPC API анализа на отдельном порту
+	GRPCEnabled bool
+	GRPCPort    string
 }
 
 func getEnv(key, defaultValue string) string {
@@ -39,6 +165,42 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func Load() *Config {
 	godotenv.Load()
 
@@ -47,14 +209,82 @@ func Load() *Config {
 	user := getEnv("DB_USER", "postgres")
 	password := getEnv("DB_PASSWORD", "password123")
 	name := getEnv("DB_NAME", "scamdetection")
+	migrateOnStart := getEnvBool("DB_MIGRATE_ON_START", true)
 
 	serverPort := getEnv("SERVER_PORT", "8080")
 	serverMode := getEnv("SERVER_MODE", "debug")
+	allowedOrigins := splitList(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173"))
+	adminUsernames := splitList(getEnv("ADMIN_USERNAMES", ""))
+	grpcEnabled := getEnvBool("GRPC_ENABLED", true)
+	grpcPort := getEnv("GRPC_PORT", "9090")
 
 	jwtSecret := getEnv("JWT_SECRET", "your-secret-key-change-in-production")
 	accessDuration := getEnv("JWT_ACCESS_DURATION", "60m")
 	refreshDuration := getEnv("JWT_REFRESH_DURATION", "168h")
 
+	passwordMinLength := getEnvInt("PASSWORD_MIN_LENGTH", 8)
+	passwordMaxLength := getEnvInt("PASSWORD_MAX_LENGTH", 128)
+	passwordRequireLower := getEnvBool("PASSWORD_REQUIRE_LOWER", true)
+	passwordRequireUpper := getEnvBool("PASSWORD_REQUIRE_UPPER", true)
+	passwordRequireDigit := getEnvBool("PASSWORD_REQUIRE_DIGIT", true)
+	passwordRequireSymbol := getEnvBool("PASSWORD_REQUIRE_SYMBOL", false)
+	passwordMinStrength := getEnvInt("PASSWORD_MIN_STRENGTH", 2)
+	passwordForbidIdentity := getEnvBool("PASSWORD_FORBID_IDENTITY", true)
+	breachedListPath := getEnv("PASSWORD_BREACHED_LIST", "")
+
+	cookieDomain := getEnv("COOKIE_DOMAIN", "")
+	cookieSecure := getEnvBool("COOKIE_SECURE", serverMode == "release")
+	cookieSameSite := strings.ToLower(getEnv("COOKIE_SAMESITE", "lax"))
+	if cookieSameSite == "none" && !cookieSecure {
+		fmt.Println("COOKIE_SAMESITE=none требует Secure cookie, COOKIE_SECURE принудительно включён")
+		cookieSecure = true
+	}
+
+	csrfEnabled := getEnvBool("CSRF_ENABLED", true)
+
+	deletionGracePeriod := getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h")
+	purgeInterval := getEnv("ACCOUNT_PURGE_INTERVAL", "1h")
+
+	mlServiceURL := getEnv("ML_SERVICE_URL", "http://localhost:8000")
+	mlModels := loadMLModels(getEnv("ML_MODELS", ""), mlServiceURL)
+	mlCandidateName := getEnv("ML_CANDIDATE_NAME", "candidate")
+	mlCandidateURL := getEnv("ML_CANDIDATE_URL", "")
+	mlCandidateTrafficPercent := getEnvInt("ML_CANDIDATE_TRAFFIC_PERCENT", 0)
+	mlCandidateShadow := getEnvBool("ML_CANDIDATE_SHADOW", true)
+	mlTextTimeout := getEnvDuration("ML_TEXT_TIMEOUT", 10*time.Second)
+	mlBatchTimeout := getEnvDuration("ML_BATCH_TIMEOUT", 60*time.Second)
+	mlHealthTimeout := getEnvDuration("ML_HEALTH_TIMEOUT", 3*time.Second)
+	mlMaxRetries := getEnvInt("ML_MAX_RETRIES", 2)
+	mlRetryBaseDelay := getEnvDuration("ML_RETRY_BASE_DELAY", 200*time.Millisecond)
+	mlBreakerThreshold := getEnvInt("ML_BREAKER_FAILURE_THRESHOLD", 5)
+	mlBreakerOpenTimeout := getEnvDuration("ML_BREAKER_OPEN_TIMEOUT", 30*time.Second)
+	mlMaxIdleConns := getEnvInt("ML_MAX_IDLE_CONNS", 32)
+	mlMaxConnsPerHost := getEnvInt("ML_MAX_CONNS_PER_HOST", 64)
+	mlDegradeOnFailure := getEnvBool("ML_DEGRADE_ON_FAILURE", true)
+	mlRescoreInterval := getEnvDuration("ML_RESCORE_INTERVAL", time.Minute)
+
+	cacheBackend := strings.ToLower(getEnv("CACHE_BACKEND", "memory"))
+	cacheTTL := getEnvDuration("CACHE_TTL", time.Hour)
+	cacheMemorySize := getEnvInt("CACHE_MEMORY_SIZE", 10000)
+	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
+	redisPassword := getEnv("REDIS_PASSWORD", "")
+	redisDB := getEnvInt("REDIS_DB", 0)
+	cacheKeyPrefix := getEnv("CACHE_KEY_PREFIX", "scam-detection:")
+
+	batchMaxTexts := getEnvInt("BATCH_MAX_TEXTS", 50000)
+	batchChunkSize := getEnvInt("BATCH_CHUNK_SIZE", 100)
+	batchWorkers := getEnvInt("BATCH_WORKERS", 4)
+
+	webhookTimeout := getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+	webhookMaxAttempts := getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	webhookRetryBaseDelay := getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second)
+	webhookRetryMaxDelay := getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", time.Hour)
+	webhookPollInterval := getEnvDuration("WEBHOOK_POLL_INTERVAL", 10*time.Second)
+	webhookAllowPrivate := getEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+
+	oidcProviders := loadOIDCProviders(getEnv("OIDC_PROVIDERS", ""))
+	oidcSuccessRedirect := getEnv("OIDC_SUCCESS_REDIRECT_URL", "")
+
 	config := &Config{
 		Database: DatabaseConfig{
 			Host:     host,
@@ -62,21 +292,185 @@ func Load() *Config {
 			User:     user,
 			Password: password,
 			Name:     name,
+
+			MigrateOnStart: migrateOnStart,
 		},
 		Server: ServerConfig{
-			Port: serverPort,
-			Mode: serverMode,
+			Port:           serverPort,
+			Mode:           serverMode,
+			AllowedOrigins: allowedOrigins,
+			AdminUsernames: adminUsernames,
+			GRPCEnabled:    grpcEnabled,
+			GRPCPort:       grpcPort,
 		},
 		JWT: JWTConfig{
 			Secret:               jwtSecret,
 			AccessTokenDuration:  accessDuration,
 			RefreshTokenDuration: refreshDuration,
 		},
+		Password: PasswordConfig{
+			MinLength:        passwordMinLength,
+			MaxLength:        passwordMaxLength,
+			RequireLower:     passwordRequireLower,
+			RequireUpper:     passwordRequireUpper,
+			RequireDigit:     passwordRequireDigit,
+			RequireSymbol:    passwordRequireSymbol,
+			MinStrength:      passwordMinStrength,
+			ForbidIdentity:   passwordForbidIdentity,
+			BreachedListPath: breachedListPath,
+		},
+		OIDC: OIDCConfig{
+			Providers:          oidcProviders,
+			SuccessRedirectURL: oidcSuccessRedirect,
+		},
+		Cookie: CookieConfig{
+			Domain:   cookieDomain,
+			Secure:   cookieSecure,
+			SameSite: cookieSameSite,
+		},
+		CSRF: CSRFConfig{
+			Enabled: csrfEnabled,
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: deletionGracePeriod,
+			PurgeInterval:       purgeInterval,
+		},
+		ML: MLConfig{
+			ServiceURL: mlServiceURL,
+			Models:     mlModels,
+			Candidate: MLCandidateConfig{
+				Name:           mlCandidateName,
+				URL:            mlCandidateURL,
+				TrafficPercent: mlCandidateTrafficPercent,
+				Shadow:         mlCandidateShadow,
+			},
+			TextTimeout:             mlTextTimeout,
+			BatchTimeout:            mlBatchTimeout,
+			HealthTimeout:           mlHealthTimeout,
+			MaxRetries:              mlMaxRetries,
+			RetryBaseDelay:          mlRetryBaseDelay,
+			BreakerFailureThreshold: mlBreakerThreshold,
+			BreakerOpenTimeout:      mlBreakerOpenTimeout,
+			MaxIdleConns:            mlMaxIdleConns,
+			MaxConnsPerHost:         mlMaxConnsPerHost,
+			DegradeOnFailure:        mlDegradeOnFailure,
+			RescoreInterval:         mlRescoreInterval,
+		},
+		Cache: CacheConfig{
+			Backend:       cacheBackend,
+			TTL:           cacheTTL,
+			MemorySize:    cacheMemorySize,
+			RedisAddr:     redisAddr,
+			RedisPassword: redisPassword,
+			RedisDB:       redisDB,
+			KeyPrefix:     cacheKeyPrefix,
+		},
+		Batch: BatchConfig{
+			MaxTexts:  batchMaxTexts,
+			ChunkSize: batchChunkSize,
+			Workers:   batchWorkers,
+		},
+		Webhook: WebhookConfig{
+			Timeout:              webhookTimeout,
+			MaxAttempts:          webhookMaxAttempts,
+			RetryBaseDelay:       webhookRetryBaseDelay,
+			RetryMaxDelay:        webhookRetryMaxDelay,
+			PollInterval:         webhookPollInterval,
+			AllowPrivateNetworks: webhookAllowPrivate,
+		},
 	}
 
 	return config
 }
 
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadOIDCProviders читает провайдеров из OIDC_PROVIDERS=google,keycloak и
+// переменных OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL, _SCOPES.
+func loadOIDCProviders(names string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(strings.ReplaceAll(getEnv(prefix+"SCOPES", ""), ",", " ")),
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			fmt.Printf("OIDC провайдер %s пропущен: не заданы ISSUER, CLIENT_ID или REDIRECT_URL\n", name)
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// loadMLModels читает модели ансамбля из ML_MODEL_<NAME>_*. Без ML_MODELS
+// используется одна модель по адресу ML_SERVICE_URL.
+func loadMLModels(names, defaultURL string) []MLModelConfig {
+	var models []MLModelConfig
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "ML_MODEL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		model := MLModelConfig{
+			Name:      name,
+			URL:       getEnv(prefix+"URL", ""),
+			Weight:    getEnvFloat(prefix+"WEIGHT", 1),
+			Languages: splitList(strings.ToLower(getEnv(prefix+"LANGUAGES", ""))),
+		}
+
+		if model.URL == "" {
+			fmt.Printf("ML модель %s пропущена: не задан URL\n", name)
+			continue
+		}
+
+		models = append(models, model)
+	}
+
+	if len(models) == 0 {
+		models = append(models, MLModelConfig{Name: "default", URL: defaultURL, Weight: 1})
+	}
+
+	return models
+}
+
+func (c *CookieConfig) SameSiteMode() http.SameSite {
+	switch c.SameSite {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	case "default":
+		return http.SameSiteDefaultMode
+	}
+	return http.SameSiteLaxMode
+}
+
 func (d *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		d.Host, d.Port, d.User, d.Password, d.Name)
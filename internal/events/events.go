@@ -0,0 +1,148 @@
+package events
+
+import (
+	"context"
+	"scam-detection-backend/internal/models"
+	"time"
+)
+
+const (
+	CheckStatus    = "check.status"
+	CheckFeedback  = "check.feedback"
+	CheckRevised   = "check.revised"
+	CheckRescored  = "check.rescored"
+	BatchProgress  = "batch.progress"
+	BatchCompleted = "batch.completed"
+)
+
+// Event - уведомление пользователя об изменении его данных.
+type Event struct {
+	Type   string      `json:"type"`
+	UserID uint        `json:"-"`
+	Data   interface{} `json:"data"`
+	Time   time.Time   `json:"time"`
+}
+
+// Bus доставляет события подписанным пользователям. Hub реализует её в
+// памяти процесса; для нескольких экземпляров сервиса её можно заменить
+// реализацией поверх брокера, не меняя издателей и WebSocket обработчик.
+type Bus interface {
+	Publish(ctx context.Context, event Event)
+	Subscribe(userID uint) *Subscription
+}
+
+type CheckStatusData struct {
+	CheckID     uint    `json:"check_id"`
+	Status      string  `json:"status"`
+	DangerScore float64 `json:"danger_score"`
+	DangerLevel string  `json:"danger_level"`
+	Degraded    bool    `json:"degraded"`
+}
+
+type CheckFeedbackData struct {
+	CheckID uint `json:"check_id"`
+	IsScam  bool `json:"is_scam"`
+}
+
+type CheckRevisedData struct {
+	CheckID     uint    `json:"check_id"`
+	Revision    int     `json:"revision,omitempty"`
+	DangerScore float64 `json:"danger_score"`
+	DangerLevel string  `json:"danger_level"`
+	// Source - источник ревизии, например reanalysis для повторного
+	// анализа, запущенного администратором
+	Source       string `json:"source"`
+	ModelName    string `json:"model_name"`
+	ModelVersion string `json:"model_version"`
+}
+
+// CheckRescoredData - новая оценка проверки, которая была оценена только
+// правилами, после пересчёта моделью. Статус проверки не меняется.
+type CheckRescoredData struct {
+	CheckID      uint    `json:"check_id"`
+	DangerScore  float64 `json:"danger_score"`
+	DangerLevel  string  `json:"danger_level"`
+	ModelName    string  `json:"model_name"`
+	ModelVersion string  `json:"model_version"`
+}
+
+type BatchProgressData struct {
+	BatchID   uint   `json:"batch_id"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	Degraded  int    `json:"degraded"`
+}
+
+func CheckStatusChanged(check *models.Check) Event {
+	return Event{
+		Type:   CheckStatus,
+		UserID: check.UserID,
+		Data: CheckStatusData{
+			CheckID:     check.ID,
+			Status:      check.Status,
+			DangerScore: check.DangerScore,
+			DangerLevel: check.DangerLevel,
+			Degraded:    check.Degraded,
+		},
+	}
+}
+
+func FeedbackReceived(userID, checkID uint, isScam bool) Event {
+	return Event{
+		Type:   CheckFeedback,
+		UserID: userID,
+		Data:   CheckFeedbackData{CheckID: checkID, IsScam: isScam},
+	}
+}
+
+func CheckRevisedBy(userID uint, revision *models.CheckRevision) Event {
+	return Event{
+		Type:   CheckRevised,
+		UserID: userID,
+		Data: CheckRevisedData{
+			CheckID:      revision.CheckID,
+			Revision:     revision.Revision,
+			DangerScore:  revision.DangerScore,
+			DangerLevel:  revision.DangerLevel,
+			Source:       revision.Source,
+			ModelName:    revision.ModelName,
+			ModelVersion: revision.ModelVersion,
+		},
+	}
+}
+
+func CheckRescoredBy(check *models.Check) Event {
+	return Event{
+		Type:   CheckRescored,
+		UserID: check.UserID,
+		Data: CheckRescoredData{
+			CheckID:      check.ID,
+			DangerScore:  check.DangerScore,
+			DangerLevel:  check.DangerLevel,
+			ModelName:    check.ModelName,
+			ModelVersion: check.ModelVersion,
+		},
+	}
+}
+
+func BatchChanged(job *models.BatchJob) Event {
+	eventType := BatchProgress
+	if job.Done() {
+		eventType = BatchCompleted
+	}
+
+	return Event{
+		Type:   eventType,
+		UserID: job.UserID,
+		Data: BatchProgressData{
+			BatchID:   job.ID,
+			Status:    job.Status,
+			Total:     job.Total,
+			Processed: job.Processed,
+			Failed:    job.Failed,
+			Degraded:  job.Degraded,
+		},
+	}
+}
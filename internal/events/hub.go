@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Буфер вмещает события целой части пакетного анализа
+const defaultSubscriptionBuffer = 256
+
+// Hub - Bus в памяти процесса. Подписчик, который не успевает читать
+// события, отключается: клиент переподключается и перечитывает историю,
+// вместо того чтобы молча терять события.
+type Hub struct {
+	buffer int
+
+	mu          sync.RWMutex
+	subscribers map[uint]map[*Subscription]struct{}
+	listeners   []Listener
+}
+
+var _ Bus = (*Hub)(nil)
+
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = defaultSubscriptionBuffer
+	}
+
+	return &Hub{
+		buffer:      buffer,
+		subscribers: make(map[uint]map[*Subscription]struct{}),
+	}
+}
+
+// Listener получает все события независимо от подписок пользователей,
+// например для отправки вебхуков. HandleEvent вызывается синхронно из
+// Publish и не должен надолго блокироваться.
+type Listener interface {
+	HandleEvent(ctx context.Context, event Event)
+}
+
+func (h *Hub) AddListener(listener Listener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listeners = append(h.listeners, listener)
+}
+
+type Subscription struct {
+	// C закрывается после Close или при отключении медленного подписчика
+	C <-chan Event
+
+	ch     chan Event
+	hub    *Hub
+	userID uint
+	once   sync.Once
+}
+
+func (h *Hub) Subscribe(userID uint) *Subscription {
+	ch := make(chan Event, h.buffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) Publish(ctx context.Context, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.mu.RLock()
+	listeners := h.listeners
+	var lagging []*Subscription
+	for sub := range h.subscribers[event.UserID] {
+		select {
+		case sub.ch <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener.HandleEvent(ctx, event)
+	}
+
+	if len(lagging) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range lagging {
+		log.Printf("events: подписчик пользователя %d не успевает читать события и отключён", sub.userID)
+		h.remove(sub)
+	}
+}
+
+// remove вызывается под h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		subs := h.subscribers[sub.userID]
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subscribers, sub.userID)
+		}
+		close(sub.ch)
+	})
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordedEvents - Listener, запоминающий все события.
+type recordedEvents struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recordedEvents) HandleEvent(ctx context.Context, event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// received вычитывает события, уже лежащие в буфере подписки.
+func received(sub *Subscription) []Event {
+	var got []Event
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return got
+			}
+			got = append(got, event)
+		default:
+			return got
+		}
+	}
+}
+
+func closed(sub *Subscription) bool {
+	select {
+	case _, ok := <-sub.C:
+		return !ok
+	default:
+		return false
+	}
+}
+
+func TestHubSubscribeAndClose(t *testing.T) {
+	hub := NewHub(4)
+	ctx := context.Background()
+
+	sub := hub.Subscribe(1)
+	hub.Publish(ctx, Event{Type: CheckStatus, UserID: 1})
+
+	got := received(sub)
+	if len(got) != 1 || got[0].Type != CheckStatus {
+		t.Fatalf("received %+v, want one %s event", got, CheckStatus)
+	}
+	if got[0].Time.IsZero() {
+		t.Fatal("Publish did not stamp the event time")
+	}
+
+	sub.Close()
+	sub.Close()
+	if !closed(sub) {
+		t.Fatal("channel is open after Close")
+	}
+	if len(hub.subscribers) != 0 {
+		t.Fatalf("hub keeps %d users after the last subscription closed", len(hub.subscribers))
+	}
+
+	// Публикация без подписчиков не блокируется и не паникует
+	hub.Publish(ctx, Event{Type: CheckStatus, UserID: 1})
+}
+
+func TestHubFanOutPerUser(t *testing.T) {
+	hub := NewHub(4)
+	listener := &recordedEvents{}
+	hub.AddListener(listener)
+	ctx := context.Background()
+
+	first, second := hub.Subscribe(1), hub.Subscribe(1)
+	other := hub.Subscribe(2)
+	defer first.Close()
+	defer second.Close()
+	defer other.Close()
+
+	hub.Publish(ctx, Event{Type: CheckStatus, UserID: 1})
+	hub.Publish(ctx, Event{Type: BatchProgress, UserID: 1})
+	hub.Publish(ctx, Event{Type: CheckFeedback, UserID: 3})
+
+	for name, sub := range map[string]*Subscription{"first": first, "second": second} {
+		got := received(sub)
+		if len(got) != 2 || got[0].Type != CheckStatus || got[1].Type != BatchProgress {
+			t.Errorf("%s subscription of user 1 received %+v", name, got)
+		}
+	}
+	if got := received(other); len(got) != 0 {
+		t.Errorf("user 2 received events of other users: %+v", got)
+	}
+	if len(listener.events) != 3 {
+		t.Fatalf("listener received %d events, want all 3", len(listener.events))
+	}
+}
+
+func TestHubDisconnectsSlowSubscriber(t *testing.T) {
+	hub := NewHub(2)
+	ctx := context.Background()
+
+	slow, fast := hub.Subscribe(1), hub.Subscribe(1)
+	defer fast.Close()
+
+	var fastGot int
+	for range 3 {
+		hub.Publish(ctx, Event{Type: CheckStatus, UserID: 1})
+		fastGot += len(received(fast))
+	}
+
+	// Медленный подписчик получает то, что успело попасть в буфер, после
+	// чего канал закрывается
+	if got := received(slow); len(got) != 2 || !closed(slow) {
+		t.Fatalf("slow subscriber: received %d events, closed %v; want 2 and closed", len(got), closed(slow))
+	}
+	if fastGot != 3 || closed(fast) {
+		t.Fatalf("fast subscriber: received %d events, closed %v; want 3 and open", fastGot, closed(fast))
+	}
+	if len(hub.subscribers[1]) != 1 {
+		t.Fatalf("hub keeps %d subscriptions of user 1, want 1", len(hub.subscribers[1]))
+	}
+
+	// Close отключённой подписки безопасен
+	slow.Close()
+}
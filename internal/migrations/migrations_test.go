@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"scam-detection-backend/internal/dbtest"
+	"scam-detection-backend/internal/models"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  string
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0010_later.up.sql":   file("SELECT 10"),
+				"0010_later.down.sql": file(""),
+				"0002_first.up.sql":   file("SELECT 2"),
+				"0002_first.down.sql": file(""),
+				"README.md":           file("not a migration"),
+			},
+			versions: []int64{2, 10},
+		},
+		{
+			name:    "missing down file",
+			files:   fstest.MapFS{"0001_init.up.sql": file("SELECT 1")},
+			wantErr: "must have both up and down files",
+		},
+		{
+			name: "different names",
+			files: fstest.MapFS{
+				"0001_init.up.sql":    file("SELECT 1"),
+				"0001_other.down.sql": file(""),
+			},
+			wantErr: "different names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("loaded %d migrations, want %d", len(migrations), len(tt.versions))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.versions[i] {
+					t.Fatalf("migration %d has version %d, want %d", i, m.Version, tt.versions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for i, m := range migrator.migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must go without gaps from 1", m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_init.up.sql", "0001_init.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := Create(dir, "Add API keys!")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if filepath.Base(up) != "0002_add_api_keys.up.sql" || filepath.Base(down) != "0002_add_api_keys.down.sql" {
+		t.Fatalf("created %s and %s", up, down)
+	}
+
+	if _, _, err := Create(dir, " -- "); err == nil {
+		t.Fatal("Create accepted an empty name")
+	}
+}
+
+// newTestMigrator - Migrator над пустой схемой тестовой базы.
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, db
+}
+
+// columns возвращает "таблица.колонка" всех таблиц текущей схемы.
+func columns(t *testing.T, db *gorm.DB) map[string]bool {
+	t.Helper()
+
+	var names []string
+	err := db.Raw(`SELECT table_name || '.' || column_name FROM information_schema.columns
+		WHERE table_schema = current_schema()`).Scan(&names).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(map[string]bool, len(names))
+	for _, name := range names {
+		result[name] = true
+	}
+	return result
+}
+
+func TestUpFromScratch(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrator.migrations))
+	}
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second Up applied %d migrations, err %v", len(applied), err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Fatalf("migration %d_%s is not applied", status.Version, status.Name)
+		}
+	}
+
+	// Все down файлы откатывают схему до пустой, и её можно создать снова
+	if _, err := migrator.Down(ctx, len(migrator.migrations)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	for column := range columns(t, db) {
+		if !strings.HasPrefix(column, "schema_migrations.") {
+			t.Fatalf("column %s is left after rolling back all migrations", column)
+		}
+	}
+	if _, err := migrator.Down(ctx, 1); !errors.Is(err, ErrNoMigrations) {
+		t.Fatalf("Down on empty schema = %v, want %v", err, ErrNoMigrations)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up after rollback: %v", err)
+	}
+}
+
+func TestUpOntoAutoMigratedSchema(t *testing.T) {
+	fresh, freshDB := newTestMigrator(t)
+	if _, err := fresh.Up(context.Background()); err != nil {
+		t.Fatalf("Up on empty schema: %v", err)
+	}
+	want := columns(t, freshDB)
+
+	migrator, db := newTestMigrator(t)
+	// Так сервер создавал схему до появления миграций
+	err := db.AutoMigrate(&models.User{}, &models.Check{}, &models.CheckDetail{}, &models.UserSessions{}, &models.UserIdentity{}, &models.OIDCAuthState{}, &models.AuditEvent{}, &models.ModelEvaluation{}, &models.CheckRevision{}, &models.ReanalysisJob{}, &models.BatchJob{}, &models.BatchItem{}, &models.Webhook{}, &models.WebhookDelivery{})
+	if err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	user := &models.User{Username: "existing", PasswordHash: "hash", Role: models.RoleUser}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	check := &models.Check{Title: "old", ContentType: "text", Content: "старая проверка", UserID: user.ID}
+	if err := db.Create(check).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up on AutoMigrate schema: %v", err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrator.migrations))
+	}
+
+	got := columns(t, db)
+	for column := range want {
+		if !got[column] {
+			t.Errorf("column %s is missing after migrating an AutoMigrate schema", column)
+		}
+	}
+
+	var stored models.Check
+	if err := db.First(&stored, check.ID).Error; err != nil {
+		t.Fatalf("existing check lost: %v", err)
+	}
+	var found int64
+	err = db.Model(&models.Check{}).Where("search_vector @@ plainto_tsquery('russian', ?)", "проверка").Count(&found).Error
+	if err != nil || found != 1 {
+		t.Fatalf("existing check not searchable: found %d, err %v", found, err)
+	}
+}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batch_jobs;
+DROP TABLE IF EXISTS reanalysis_jobs;
+DROP TABLE IF EXISTS check_revisions;
+DROP TABLE IF EXISTS model_evaluations;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS oidc_auth_states;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS check_details;
+DROP TABLE IF EXISTS checks;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,222 @@
+-- Схема, которую создавал GORM AutoMigrate. IF NOT EXISTS позволяет применить
+-- миграцию к базе, созданной AutoMigrate: она только запишется как применённая.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    username text NOT NULL,
+    email text,
+    password_hash text NOT NULL,
+    has_password boolean DEFAULT true,
+    role varchar(32) NOT NULL DEFAULT 'user',
+    is_active boolean DEFAULT true,
+    deletion_scheduled_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE IF NOT EXISTS checks (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    content_type text NOT NULL,
+    content text,
+    danger_score decimal,
+    danger_level text,
+    status text DEFAULT 'processing',
+    degraded boolean NOT NULL DEFAULT false,
+    user_verdict boolean,
+    model_name varchar(128),
+    model_version varchar(256),
+    revision bigint NOT NULL DEFAULT 1,
+    user_id bigint NOT NULL,
+    batch_id bigint,
+    batch_index bigint,
+    processing_time bigint,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_users_checks FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_checks_degraded ON checks (degraded);
+CREATE INDEX IF NOT EXISTS idx_checks_model ON checks (model_name, model_version);
+CREATE INDEX IF NOT EXISTS idx_checks_batch ON checks (batch_id, batch_index);
+
+CREATE TABLE IF NOT EXISTS check_details (
+    id bigserial PRIMARY KEY,
+    check_id bigint NOT NULL,
+    feature_name text NOT NULL,
+    feature_value text,
+    confidence_score decimal,
+    model_name varchar(128),
+    model_version varchar(256),
+    revision bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    CONSTRAINT fk_check_details_check FOREIGN KEY (check_id) REFERENCES checks (id)
+);
+CREATE INDEX IF NOT EXISTS idx_check_details_check_id ON check_details (check_id);
+CREATE INDEX IF NOT EXISTS idx_check_details_revision ON check_details (revision);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_token_hash ON user_sessions (token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at ON user_sessions (expires_at);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    provider varchar(64) NOT NULL,
+    subject varchar(255) NOT NULL,
+    email text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_provider_subject ON user_identities (provider, subject);
+
+CREATE TABLE IF NOT EXISTS oidc_auth_states (
+    state varchar(64) PRIMARY KEY,
+    provider varchar(64) NOT NULL,
+    code_verifier varchar(128) NOT NULL,
+    nonce varchar(64) NOT NULL,
+    link_user_id bigint,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_states_link_user_id ON oidc_auth_states (link_user_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_states_expires_at ON oidc_auth_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    actor_id bigint,
+    event_type varchar(64) NOT NULL,
+    ip_address varchar(64),
+    user_agent varchar(512),
+    metadata text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_event_type ON audit_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE TABLE IF NOT EXISTS model_evaluations (
+    id bigserial PRIMARY KEY,
+    check_id bigint NOT NULL,
+    variant varchar(16) NOT NULL,
+    model varchar(128) NOT NULL,
+    served boolean NOT NULL,
+    label varchar(64),
+    is_scam boolean,
+    confidence decimal,
+    danger_score decimal,
+    latency_ms bigint,
+    error text,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_evaluation_check_variant ON model_evaluations (check_id, variant);
+CREATE INDEX IF NOT EXISTS idx_model_evaluations_created_at ON model_evaluations (created_at);
+
+CREATE TABLE IF NOT EXISTS check_revisions (
+    id bigserial PRIMARY KEY,
+    check_id bigint NOT NULL,
+    revision bigint NOT NULL,
+    danger_score decimal,
+    danger_level text,
+    model_name varchar(128),
+    model_version varchar(256),
+    source varchar(32) NOT NULL,
+    job_id bigint,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_check_revision ON check_revisions (check_id, revision);
+CREATE INDEX IF NOT EXISTS idx_check_revisions_job_id ON check_revisions (job_id);
+
+CREATE TABLE IF NOT EXISTS reanalysis_jobs (
+    id bigserial PRIMARY KEY,
+    status varchar(16) NOT NULL,
+    filter text,
+    created_by bigint NOT NULL,
+    total bigint,
+    processed bigint,
+    failed bigint,
+    model_name varchar(128),
+    model_version varchar(256),
+    error text,
+    started_at timestamptz,
+    finished_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_reanalysis_jobs_status ON reanalysis_jobs (status);
+
+CREATE TABLE IF NOT EXISTS batch_jobs (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    status varchar(16) NOT NULL,
+    total bigint,
+    processed bigint,
+    failed bigint,
+    degraded bigint,
+    error text,
+    started_at timestamptz,
+    finished_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_batch_jobs_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_user_id ON batch_jobs (user_id);
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_status ON batch_jobs (status);
+
+CREATE TABLE IF NOT EXISTS batch_items (
+    id bigserial PRIMARY KEY,
+    batch_id bigint NOT NULL,
+    "index" bigint NOT NULL,
+    status varchar(16) NOT NULL,
+    check_id bigint,
+    danger_score decimal,
+    danger_level varchar(16),
+    error_code varchar(32),
+    error text,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batch_items_index ON batch_items (batch_id, "index");
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    url varchar(2048) NOT NULL,
+    secret varchar(128) NOT NULL,
+    events varchar(256) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL,
+    event_type varchar(32) NOT NULL,
+    payload text NOT NULL,
+    status varchar(16) NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_status_code bigint,
+    last_error text,
+    delivered_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,2 @@
+-- Сводка и история проверок выбирают проверки пользователя
+CREATE INDEX IF NOT EXISTS idx_checks_user_id ON checks (user_id);
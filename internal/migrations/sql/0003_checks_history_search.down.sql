@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_checks_user_feedback;
+DROP INDEX IF EXISTS idx_checks_user_score;
+DROP INDEX IF EXISTS idx_checks_user_created;
+DROP INDEX IF EXISTS idx_checks_search_vector;
+ALTER TABLE checks DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,12 @@
+-- Полнотекстовый поиск по истории: вектор строится по русской и английской
+-- конфигурациям, чтобы находились тексты на обоих языках
+ALTER TABLE checks ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('russian', coalesce(content, '')) || to_tsvector('english', coalesce(content, ''))
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_checks_search_vector ON checks USING gin (search_vector);
+
+-- Сортировки и фильтры истории внутри проверок пользователя
+CREATE INDEX IF NOT EXISTS idx_checks_user_created ON checks (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_checks_user_score ON checks (user_id, danger_score, id);
+CREATE INDEX IF NOT EXISTS idx_checks_user_feedback ON checks (user_id, created_at) WHERE user_verdict IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_batch_items_seq;
+ALTER TABLE batch_items DROP COLUMN IF EXISTS seq;
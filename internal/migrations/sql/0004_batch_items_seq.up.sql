@@ -0,0 +1,12 @@
+-- Курсор результатов пакета: номера выдаются при записи части под
+-- блокировкой задачи, в отличие от id, которые выдаются до фиксации
+-- транзакции и видны не по порядку
+ALTER TABLE batch_items ADD COLUMN IF NOT EXISTS seq bigint;
+UPDATE batch_items SET seq = numbered.seq
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY batch_id ORDER BY id) AS seq
+    FROM batch_items
+) AS numbered
+WHERE batch_items.id = numbered.id AND batch_items.seq IS NULL;
+ALTER TABLE batch_items ALTER COLUMN seq SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batch_items_seq ON batch_items (batch_id, seq);
@@ -0,0 +1,134 @@
+package mlclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+var ErrCircuitOpen = errors.New("ML service circuit breaker is open")
+
+type BreakerStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// CircuitReporter реализуют анализаторы с circuit breaker, чтобы
+// /analysis/health мог показать его состояние.
+type CircuitReporter interface {
+	CircuitState() BreakerStats
+}
+
+// circuitBreaker размыкается после threshold последовательных ошибок и
+// отклоняет вызовы openTimeout. Затем пропускает один пробный вызов
+// (half-open): успех замыкает цепь, ошибка снова размыкает.
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	// now подменяется в тестах
+	now func() time.Time
+
+	mu            sync.Mutex
+	state         string
+	failures      int
+	openedAt      time.Time
+	trialInFlight bool
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		now:         time.Now,
+		state:       CircuitClosed,
+	}
+}
+
+func (b *circuitBreaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.trialInFlight = true
+		return nil
+	case CircuitHalfOpen:
+		if b.trialInFlight {
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+		return nil
+	}
+
+	return nil
+}
+
+func (b *circuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trialInFlight = false
+}
+
+func (b *circuitBreaker) onFailure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialInFlight = false
+
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// onNeutral освобождает пробный вызов, не влияя на счётчик: например, когда
+// запрос отменил сам клиент.
+func (b *circuitBreaker) onNeutral() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+}
+
+func (b *circuitBreaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.openTimeout)
+		stats.OpenedAt = &openedAt
+		stats.RetryAt = &retryAt
+	}
+
+	return stats
+}
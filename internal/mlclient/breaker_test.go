@@ -0,0 +1,173 @@
+package mlclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock - время, которое двигает только тест.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(threshold int, openTimeout time.Duration) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	breaker := newCircuitBreaker(threshold, openTimeout)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	type step struct {
+		// action - allow, success, failure, neutral или wait
+		action  string
+		wait    time.Duration
+		wantErr error
+		state   string
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "opens after threshold consecutive failures",
+			steps: []step{
+				{action: "failure", state: CircuitClosed},
+				{action: "failure", state: CircuitClosed},
+				{action: "failure", state: CircuitOpen},
+				{action: "allow", wantErr: ErrCircuitOpen, state: CircuitOpen},
+			},
+		},
+		{
+			name: "success resets the failure count",
+			steps: []step{
+				{action: "failure", state: CircuitClosed},
+				{action: "failure", state: CircuitClosed},
+				{action: "success", state: CircuitClosed},
+				{action: "failure", state: CircuitClosed},
+				{action: "failure", state: CircuitClosed},
+				{action: "allow", state: CircuitClosed},
+			},
+		},
+		{
+			name: "stays open until the timeout passes",
+			steps: []step{
+				{action: "failure"}, {action: "failure"}, {action: "failure", state: CircuitOpen},
+				{action: "wait", wait: 29 * time.Second},
+				{action: "allow", wantErr: ErrCircuitOpen, state: CircuitOpen},
+				{action: "wait", wait: time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "half-open lets a single trial through",
+			steps: []step{
+				{action: "failure"}, {action: "failure"}, {action: "failure"},
+				{action: "wait", wait: 30 * time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+				{action: "allow", wantErr: ErrCircuitOpen, state: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "successful trial closes the circuit",
+			steps: []step{
+				{action: "failure"}, {action: "failure"}, {action: "failure"},
+				{action: "wait", wait: 30 * time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+				{action: "success", state: CircuitClosed},
+				{action: "allow", state: CircuitClosed},
+			},
+		},
+		{
+			name: "failed trial reopens for another timeout",
+			steps: []step{
+				{action: "failure"}, {action: "failure"}, {action: "failure"},
+				{action: "wait", wait: 30 * time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+				{action: "failure", state: CircuitOpen},
+				{action: "wait", wait: 10 * time.Second},
+				{action: "allow", wantErr: ErrCircuitOpen, state: CircuitOpen},
+				{action: "wait", wait: 20 * time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "neutral result frees the trial without closing",
+			steps: []step{
+				{action: "failure"}, {action: "failure"}, {action: "failure"},
+				{action: "wait", wait: 30 * time.Second},
+				{action: "allow", state: CircuitHalfOpen},
+				{action: "neutral", state: CircuitHalfOpen},
+				{action: "allow", state: CircuitHalfOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, clock := newTestBreaker(3, 30*time.Second)
+
+			for i, s := range tt.steps {
+				var err error
+				switch s.action {
+				case "allow":
+					err = breaker.allow()
+				case "success":
+					breaker.onSuccess()
+				case "failure":
+					breaker.onFailure()
+				case "neutral":
+					breaker.onNeutral()
+				case "wait":
+					clock.Advance(s.wait)
+				}
+
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d (%s): error = %v, want %v", i, s.action, err, s.wantErr)
+				}
+				if s.state != "" {
+					if got := breaker.stats().State; got != s.state {
+						t.Fatalf("step %d (%s): state = %s, want %s", i, s.action, got, s.state)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerStats(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	openedAt := clock.Now()
+	breaker.onFailure()
+
+	stats := breaker.stats()
+	if stats.State != CircuitOpen || stats.ConsecutiveFailures != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.OpenedAt == nil || !stats.OpenedAt.Equal(openedAt) {
+		t.Fatalf("OpenedAt = %v, want %v", stats.OpenedAt, openedAt)
+	}
+	if stats.RetryAt == nil || !stats.RetryAt.Equal(openedAt.Add(time.Minute)) {
+		t.Fatalf("RetryAt = %v, want %v", stats.RetryAt, openedAt.Add(time.Minute))
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker, _ := newTestBreaker(0, time.Minute)
+	for range 10 {
+		breaker.onFailure()
+	}
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("disabled breaker rejected a call: %v", err)
+	}
+}
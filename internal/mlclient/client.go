@@ -2,19 +2,70 @@ package mlclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 )
 
+// Analyzer - источник ML предсказаний. Реализуется HTTP клиентом к Python
+// сервису и FakeAnalyzer для тестов.
+type Analyzer interface {
+	AnalyzeText(ctx context.Context, text string) (*TextAnalysisResponse, error)
+	AnalyzeBatch(ctx context.Context, texts []string) (*BatchTextAnalysisResponse, error)
+	HealthCheck(ctx context.Context) (*HealthResponse, error)
+}
+
+// ModelVersioner сообщает текущую версию модели без анализа текста. Нужен
+// кэшу результатов: ответ другой версии модели нельзя выдавать из кэша.
+type ModelVersioner interface {
+	ModelVersion(ctx context.Context) (string, error)
+}
+
+var _ Analyzer = (*MLClient)(nil)
+var _ CircuitReporter = (*MLClient)(nil)
+var _ ModelVersioner = (*MLClient)(nil)
+
+type Config struct {
+	BaseURL string
+
+	TextTimeout   time.Duration
+	BatchTimeout  time.Duration
+	HealthTimeout time.Duration
+
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	BreakerThreshold   int
+	BreakerOpenTimeout time.Duration
+
+	MaxIdleConns    int
+	MaxConnsPerHost int
+}
+
 type MLClient struct {
 	baseURL    string
 	httpClient *http.Client
+	cfg        Config
+	breaker    *circuitBreaker
+
+	modelMu        sync.Mutex
+	modelName      string
+	modelVersion   string
+	modelFetchedAt time.Time
 }
 
+// Сколько доверять имени/версии модели из /health, если сервис не
+// возвращает их в ответах анализа.
+const modelInfoTTL = time.Minute
+
 type TextAnalysisRequest struct {
 	Text string `json:"text"`
 }
@@ -23,22 +74,41 @@ type BatchTextAnalysisRequest struct {
 	Texts []string `json:"texts"`
 }
 
+// Метки классов в ответах ML сервиса. Confidence - вероятность метки
+// ответа, а IsScam дополнительно учитывает PHISHING_THRESHOLD сервиса.
+const (
+	LabelPhishing   = "phishing"
+	LabelLegitimate = "legitimate"
+)
+
 type PredictionResult struct {
 	Label      string  `json:"label"`
 	Confidence float64 `json:"confidence"`
 	IsScam     bool    `json:"is_scam"`
+	// Модель, выдавшая предсказание. Если ML сервис не возвращает их в ответе,
+	// клиент подставляет данные из /health
+	ModelName    string `json:"model_name,omitempty"`
+	ModelVersion string `json:"model_version,omitempty"`
+	// Cached - результат взят из кэша, ML сервис не вызывался
+	Cached bool `json:"cached,omitempty"`
+	// Models заполняет Ensemble: ответы отдельных моделей
+	Models []ModelPrediction `json:"models,omitempty"`
 }
 
 type TextAnalysisResponse struct {
 	Success        bool             `json:"success"`
 	Prediction     PredictionResult `json:"prediction"`
 	ProcessingTime float64          `json:"processing_time"`
+	ModelName      string           `json:"model_name,omitempty"`
+	ModelVersion   string           `json:"model_version,omitempty"`
 }
 
 type BatchTextAnalysisResponse struct {
 	Success        bool               `json:"success"`
 	Predictions    []PredictionResult `json:"predictions"`
 	ProcessingTime float64            `json:"processing_time"`
+	ModelName      string             `json:"model_name,omitempty"`
+	ModelVersion   string             `json:"model_version,omitempty"`
 }
 
 type HealthResponse struct {
@@ -48,22 +118,76 @@ type HealthResponse struct {
 	Version     string `json:"version"`
 }
 
-func NewMLClient() *MLClient {
-	baseURL := os.Getenv("ML_SERVICE_URL")
-	if baseURL == "" {
-		baseURL = "http://localhost:8000"
+// StatusError - ответ ML сервиса с кодом, отличным от 200.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("ML service returned error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+func NewMLClient(cfg Config) *MLClient {
+	if cfg.TextTimeout <= 0 {
+		cfg.TextTimeout = 10 * time.Second
+	}
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = 60 * time.Second
+	}
+	if cfg.HealthTimeout <= 0 {
+		cfg.HealthTimeout = 3 * time.Second
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = 100 * time.Millisecond
+	}
+	if cfg.RetryMaxDelay <= 0 {
+		cfg.RetryMaxDelay = 2 * time.Second
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = 32
+	}
+
+	// Все запросы идут на один хост, поэтому пул соединений настраивается
+	// per-host, а не на общий лимит по умолчанию (2 idle соединения).
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConns,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
 	}
 
 	return &MLClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    cfg.BaseURL,
+		httpClient: &http.Client{Transport: transport},
+		cfg:        cfg,
+		breaker:    newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerOpenTimeout),
 	}
 }
 
-func (c *MLClient) HealthCheck() (*HealthResponse, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/health")
+func (c *MLClient) CircuitState() BreakerStats {
+	return c.breaker.stats()
+}
+
+// HealthCheck не проходит через circuit breaker: это проба доступности,
+// которая должна работать и когда breaker разомкнут.
+func (c *MLClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.HealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check ML service health: %w", err)
 	}
@@ -79,65 +203,201 @@ func (c *MLClient) HealthCheck() (*HealthResponse, error) {
 		return nil, fmt.Errorf("failed to decode health response: %w", err)
 	}
 
+	c.modelMu.Lock()
+	c.modelName, c.modelVersion, c.modelFetchedAt = health.ModelName, health.Version, time.Now()
+	c.modelMu.Unlock()
+
 	return &health, nil
 }
 
-func (c *MLClient) AnalyzeText(text string) (*TextAnalysisResponse, error) {
-	reqBody := TextAnalysisRequest{Text: text}
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+// modelInfo возвращает имя и версию модели: из ответа сервиса, если они там
+// есть, иначе из закэшированного /health.
+func (c *MLClient) modelInfo(ctx context.Context, name, version string) (string, string) {
+	if name != "" {
+		return name, version
 	}
 
-	resp, err := c.httpClient.Post(
-		c.baseURL+"/api/v1/analyze/text",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request to ML service: %w", err)
+	c.modelMu.Lock()
+	fresh := time.Since(c.modelFetchedAt) < modelInfoTTL
+	name, version = c.modelName, c.modelVersion
+	c.modelMu.Unlock()
+
+	if !fresh {
+		if health, err := c.HealthCheck(ctx); err == nil {
+			name, version = health.ModelName, health.Version
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ML service returned error: status %d, body: %s", resp.StatusCode, string(body))
+	return name, version
+}
+
+func (c *MLClient) ModelVersion(ctx context.Context) (string, error) {
+	name, version := c.modelInfo(ctx, "", "")
+	if name == "" {
+		return "", errors.New("ML model version is unknown")
 	}
+	return name + "@" + version, nil
+}
+
+func stampModel(p *PredictionResult, name, version string) {
+	if p.ModelName == "" {
+		p.ModelName, p.ModelVersion = name, version
+	}
+}
 
+func (c *MLClient) AnalyzeText(ctx context.Context, text string) (*TextAnalysisResponse, error) {
 	var result TextAnalysisResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.post(ctx, "/api/v1/analyze/text", TextAnalysisRequest{Text: text}, c.cfg.TextTimeout, &result); err != nil {
+		return nil, err
+	}
+
+	name, version := c.modelInfo(ctx, result.ModelName, result.ModelVersion)
+	stampModel(&result.Prediction, name, version)
+
+	return &result, nil
+}
+
+func (c *MLClient) AnalyzeBatch(ctx context.Context, texts []string) (*BatchTextAnalysisResponse, error) {
+	var result BatchTextAnalysisResponse
+	if err := c.post(ctx, "/api/v1/analyze/batch", BatchTextAnalysisRequest{Texts: texts}, c.cfg.BatchTimeout, &result); err != nil {
+		return nil, err
+	}
+
+	name, version := c.modelInfo(ctx, result.ModelName, result.ModelVersion)
+	for i := range result.Predictions {
+		stampModel(&result.Predictions[i], name, version)
 	}
 
 	return &result, nil
 }
 
-func (c *MLClient) AnalyzeBatch(texts []string) (*BatchTextAnalysisResponse, error) {
-	reqBody := BatchTextAnalysisRequest{Texts: texts}
-	jsonData, err := json.Marshal(reqBody)
+// post выполняет запрос через circuit breaker с ограниченным числом повторов.
+// Анализ не меняет состояние ML сервиса, поэтому повтор безопасен; повторяются
+// только сетевые ошибки, таймауты попытки и ответы 429/502/503/504.
+func (c *MLClient) post(ctx context.Context, path string, payload interface{}, timeout time.Duration, out interface{}) error {
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		c.baseURL+"/api/v1/analyze/batch",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepWithJitter(ctx, c.backoff(attempt)); err != nil {
+				c.breaker.onNeutral()
+				return err
+			}
+		}
+
+		lastErr = c.doPost(ctx, path, jsonData, timeout, out)
+		if lastErr == nil {
+			c.breaker.onSuccess()
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			c.breaker.onNeutral()
+			return lastErr
+		}
+
+		if !isRetryable(lastErr) {
+			break
+		}
+	}
+
+	if isServiceFailure(lastErr) {
+		c.breaker.onFailure()
+	} else {
+		c.breaker.onNeutral()
+	}
+
+	return lastErr
+}
+
+func (c *MLClient) doPost(ctx context.Context, path string, body []byte, timeout time.Duration, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request to ML service: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to ML service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ML service returned error: status %d, body: %s", resp.StatusCode, string(body))
+		respBody, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
-	var result BatchTextAnalysisResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return nil
+}
+
+func (c *MLClient) backoff(attempt int) time.Duration {
+	delay := c.cfg.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > c.cfg.RetryMaxDelay {
+		delay = c.cfg.RetryMaxDelay
+	}
+	return delay
+}
+
+// sleepWithJitter ждёт случайное время в [delay/2, delay) ("equal jitter"),
+// чтобы повторы от многих запросов не приходили в ML сервис одновременно.
+func sleepWithJitter(ctx context.Context, delay time.Duration) error {
+	half := delay / 2
+	wait := half
+	if half > 0 {
+		wait += rand.N(half)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isServiceFailure решает, засчитывать ли ошибку circuit breaker'у: 4xx
+// (кроме 429) означают невалидный запрос, а не недоступность сервиса.
+func isServiceFailure(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return isRetryable(err)
 }
@@ -0,0 +1,149 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	client := NewMLClient(Config{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second})
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 400 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 10, want: time.Second},
+		// Сдвиг переполняет Duration, задержка всё равно ограничена
+		{attempt: 64, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := client.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSleepWithJitterBounds(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	for range 5 {
+		start := time.Now()
+		if err := sleepWithJitter(context.Background(), delay); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < delay/2 {
+			t.Fatalf("slept %v, want at least %v", elapsed, delay/2)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepWithJitter(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("sleepWithJitter on cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+// statusServer отвечает кодами из statuses по очереди, затем 200.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"success":true,"prediction":{"label":"legitimate","confidence":0.9},"model_name":"m","model_version":"1"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestPostRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantCalls  int32
+		wantStatus int
+	}{
+		{name: "retries until success", statuses: []int{503, 502}, maxRetries: 3, wantCalls: 3},
+		{name: "gives up after max retries", statuses: []int{503, 503, 503, 503, 503}, maxRetries: 2, wantCalls: 3, wantStatus: 503},
+		{name: "no retries when disabled", statuses: []int{503}, maxRetries: 0, wantCalls: 1, wantStatus: 503},
+		{name: "retries 429", statuses: []int{429}, maxRetries: 1, wantCalls: 2},
+		{name: "does not retry 500", statuses: []int{500}, maxRetries: 3, wantCalls: 1, wantStatus: 500},
+		{name: "does not retry 4xx", statuses: []int{422}, maxRetries: 3, wantCalls: 1, wantStatus: 422},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, tt.statuses...)
+			client := NewMLClient(Config{
+				BaseURL:        server.URL,
+				MaxRetries:     tt.maxRetries,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  2 * time.Millisecond,
+			})
+
+			_, err := client.AnalyzeText(context.Background(), "text")
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", got, tt.wantCalls)
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+				t.Fatalf("error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPostOpensBreaker(t *testing.T) {
+	server, calls := statusServer(t, 503, 503, 503, 503)
+	client := NewMLClient(Config{
+		BaseURL:            server.URL,
+		BreakerThreshold:   2,
+		BreakerOpenTimeout: time.Minute,
+	})
+
+	for range 2 {
+		if _, err := client.AnalyzeText(context.Background(), "text"); err == nil {
+			t.Fatal("expected an error from a failing service")
+		}
+	}
+
+	if _, err := client.AnalyzeText(context.Background(), "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls = %d, want 2: open breaker must not reach the service", got)
+	}
+	if state := client.CircuitState().State; state != CircuitOpen {
+		t.Fatalf("state = %s, want %s", state, CircuitOpen)
+	}
+}
+
+func TestPostClientErrorsDoNotOpenBreaker(t *testing.T) {
+	server, _ := statusServer(t, 422, 422, 422)
+	client := NewMLClient(Config{BaseURL: server.URL, BreakerThreshold: 2, BreakerOpenTimeout: time.Minute})
+
+	for range 3 {
+		client.AnalyzeText(context.Background(), "text")
+	}
+	if state := client.CircuitState().State; state != CircuitClosed {
+		t.Fatalf("state = %s, want %s", state, CircuitClosed)
+	}
+}
@@ -0,0 +1,399 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ModelEndpoint - одна модель ансамбля. Пустой Languages означает, что
+// модель принимает тексты на любом языке.
+type ModelEndpoint struct {
+	Name      string
+	Weight    float64
+	Languages []string
+	Analyzer  Analyzer
+}
+
+// ModelPrediction - вклад одной модели в итоговое предсказание ансамбля.
+type ModelPrediction struct {
+	Model        string  `json:"model"`
+	ModelName    string  `json:"model_name,omitempty"`
+	ModelVersion string  `json:"model_version,omitempty"`
+	Weight       float64 `json:"weight"`
+	Label        string  `json:"label"`
+	Confidence   float64 `json:"confidence"`
+	IsScam       bool    `json:"is_scam"`
+	Error        string  `json:"error,omitempty"`
+}
+
+type ModelHealth struct {
+	Name           string          `json:"name"`
+	Weight         float64         `json:"weight"`
+	Languages      []string        `json:"languages,omitempty"`
+	Status         string          `json:"status"`
+	Error          string          `json:"error,omitempty"`
+	Health         *HealthResponse `json:"health,omitempty"`
+	CircuitBreaker *BreakerStats   `json:"circuit_breaker,omitempty"`
+}
+
+// ModelHealthReporter реализуют анализаторы из нескольких моделей, чтобы
+// /analysis/health мог показать состояние каждой.
+type ModelHealthReporter interface {
+	ModelsHealth(ctx context.Context) []ModelHealth
+}
+
+var ErrNoModels = errors.New("no ML models configured")
+
+const EnsembleModelName = "ensemble"
+
+// Ensemble опрашивает параллельно все модели, подходящие по языку текста, и
+// объединяет их ответы взвешенным голосованием по вероятности мошенничества.
+// Ошибка отдельной модели не мешает ответу, пока отвечает хотя бы одна.
+type Ensemble struct {
+	models []ModelEndpoint
+}
+
+var _ Analyzer = (*Ensemble)(nil)
+var _ ModelHealthReporter = (*Ensemble)(nil)
+var _ ModelVersioner = (*Ensemble)(nil)
+
+func NewEnsemble(models []ModelEndpoint) *Ensemble {
+	normalized := make([]ModelEndpoint, 0, len(models))
+	for _, m := range models {
+		if m.Weight <= 0 {
+			m.Weight = 1
+		}
+		normalized = append(normalized, m)
+	}
+
+	return &Ensemble{models: normalized}
+}
+
+func (e *Ensemble) AnalyzeText(ctx context.Context, text string) (*TextAnalysisResponse, error) {
+	selected := e.route(DetectLanguage(text))
+	if len(selected) == 0 {
+		return nil, ErrNoModels
+	}
+
+	responses := make([]*TextAnalysisResponse, len(selected))
+	errs := make([]error, len(selected))
+
+	var wg sync.WaitGroup
+	for i, m := range selected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], errs[i] = m.Analyzer.AnalyzeText(ctx, text)
+		}()
+	}
+	wg.Wait()
+
+	votes := make([]ModelPrediction, len(selected))
+	var processingTime float64
+	for i, m := range selected {
+		if errs[i] != nil {
+			votes[i] = ModelPrediction{Model: m.Name, Weight: m.Weight, Error: errs[i].Error()}
+			continue
+		}
+		votes[i] = modelPrediction(m, responses[i].Prediction)
+		processingTime = max(processingTime, responses[i].ProcessingTime)
+	}
+
+	prediction, err := vote(votes, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TextAnalysisResponse{
+		Success:        true,
+		Prediction:     prediction,
+		ProcessingTime: processingTime,
+	}, nil
+}
+
+// AnalyzeBatch группирует тексты по набору подходящих моделей, чтобы каждая
+// модель получила один пакетный запрос.
+func (e *Ensemble) AnalyzeBatch(ctx context.Context, texts []string) (*BatchTextAnalysisResponse, error) {
+	if len(e.models) == 0 {
+		return nil, ErrNoModels
+	}
+
+	indexesByModel := make([][]int, len(e.models))
+	for i, text := range texts {
+		for _, idx := range e.routeIndexes(DetectLanguage(text)) {
+			indexesByModel[idx] = append(indexesByModel[idx], i)
+		}
+	}
+
+	responses := make([]*BatchTextAnalysisResponse, len(e.models))
+	modelErrs := make([]error, len(e.models))
+
+	var wg sync.WaitGroup
+	for modelIdx, textIdxs := range indexesByModel {
+		if len(textIdxs) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m := e.models[modelIdx]
+			batch := make([]string, len(textIdxs))
+			for j, ti := range textIdxs {
+				batch[j] = texts[ti]
+			}
+
+			resp, err := m.Analyzer.AnalyzeBatch(ctx, batch)
+			if err == nil && len(resp.Predictions) != len(batch) {
+				err = fmt.Errorf("model %s returned %d predictions for %d texts", m.Name, len(resp.Predictions), len(batch))
+			}
+			responses[modelIdx], modelErrs[modelIdx] = resp, err
+		}()
+	}
+	wg.Wait()
+
+	votes := make([][]ModelPrediction, len(texts))
+	errs := make([][]error, len(texts))
+	var processingTime float64
+	for modelIdx, textIdxs := range indexesByModel {
+		m := e.models[modelIdx]
+		err := modelErrs[modelIdx]
+		if err == nil && len(textIdxs) > 0 {
+			processingTime = max(processingTime, responses[modelIdx].ProcessingTime)
+		}
+
+		for j, ti := range textIdxs {
+			if err != nil {
+				votes[ti] = append(votes[ti], ModelPrediction{Model: m.Name, Weight: m.Weight, Error: err.Error()})
+			} else {
+				votes[ti] = append(votes[ti], modelPrediction(m, responses[modelIdx].Predictions[j]))
+			}
+			errs[ti] = append(errs[ti], err)
+		}
+	}
+
+	predictions := make([]PredictionResult, len(texts))
+	for i := range texts {
+		prediction, err := vote(votes[i], errs[i])
+		if err != nil {
+			return nil, err
+		}
+		predictions[i] = prediction
+	}
+
+	return &BatchTextAnalysisResponse{
+		Success:        true,
+		Predictions:    predictions,
+		ProcessingTime: processingTime,
+	}, nil
+}
+
+func (e *Ensemble) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	return SummarizeHealth(e.ModelsHealth(ctx))
+}
+
+// SummarizeHealth сводит состояние моделей в один ответ: healthy - отвечают
+// все, degraded - часть, ошибка - ни одна.
+func SummarizeHealth(models []ModelHealth) (*HealthResponse, error) {
+	if len(models) == 0 {
+		return nil, ErrNoModels
+	}
+
+	healthy := 0
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		if m.Error == "" {
+			healthy++
+		}
+		names = append(names, m.Name)
+	}
+
+	if healthy == 0 {
+		return nil, fmt.Errorf("all %d ML models are unavailable", len(models))
+	}
+
+	status := "healthy"
+	if healthy < len(models) {
+		status = "degraded"
+	}
+
+	return &HealthResponse{
+		Status:      status,
+		ModelLoaded: true,
+		ModelName:   strings.Join(names, ","),
+	}, nil
+}
+
+func (e *Ensemble) ModelsHealth(ctx context.Context) []ModelHealth {
+	result := make([]ModelHealth, len(e.models))
+
+	var wg sync.WaitGroup
+	for i, m := range e.models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			status := ModelHealth{
+				Name:      m.Name,
+				Weight:    m.Weight,
+				Languages: m.Languages,
+			}
+			if reporter, ok := m.Analyzer.(CircuitReporter); ok {
+				stats := reporter.CircuitState()
+				status.CircuitBreaker = &stats
+			}
+
+			health, err := m.Analyzer.HealthCheck(ctx)
+			if err != nil {
+				status.Status = "unavailable"
+				status.Error = err.Error()
+			} else {
+				status.Status = health.Status
+				status.Health = health
+			}
+
+			result[i] = status
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// ModelVersion - состав ансамбля с весами, языками и версиями всех моделей:
+// изменение любого из них меняет результат голосования. Если версию одной
+// из моделей узнать нельзя, версия ансамбля тоже неизвестна.
+func (e *Ensemble) ModelVersion(ctx context.Context) (string, error) {
+	if len(e.models) == 0 {
+		return "", ErrNoModels
+	}
+
+	parts := make([]string, 0, len(e.models))
+	for _, m := range e.models {
+		versioner, ok := m.Analyzer.(ModelVersioner)
+		if !ok {
+			return "", fmt.Errorf("model %s does not report its version", m.Name)
+		}
+		version, err := versioner.ModelVersion(ctx)
+		if err != nil {
+			return "", fmt.Errorf("model %s: %w", m.Name, err)
+		}
+		parts = append(parts, fmt.Sprintf("%s[%g,%s]=%s", m.Name, m.Weight, strings.Join(m.Languages, ","), version))
+	}
+
+	return strings.Join(parts, "+"), nil
+}
+
+func (e *Ensemble) route(language string) []ModelEndpoint {
+	idxs := e.routeIndexes(language)
+	selected := make([]ModelEndpoint, 0, len(idxs))
+	for _, idx := range idxs {
+		selected = append(selected, e.models[idx])
+	}
+	return selected
+}
+
+// routeIndexes выбирает модели, поддерживающие язык текста. Если таких нет,
+// текст отправляется всем моделям: лучше ответ неспециализированной модели,
+// чем никакого.
+func (e *Ensemble) routeIndexes(language string) []int {
+	idxs := make([]int, 0, len(e.models))
+	for i, m := range e.models {
+		if len(m.Languages) == 0 || slices.Contains(m.Languages, language) {
+			idxs = append(idxs, i)
+		}
+	}
+
+	if len(idxs) == 0 {
+		for i := range e.models {
+			idxs = append(idxs, i)
+		}
+	}
+
+	return idxs
+}
+
+func modelPrediction(m ModelEndpoint, p PredictionResult) ModelPrediction {
+	return ModelPrediction{
+		Model:        m.Name,
+		ModelName:    p.ModelName,
+		ModelVersion: p.ModelVersion,
+		Weight:       m.Weight,
+		Label:        p.Label,
+		Confidence:   p.Confidence,
+		IsScam:       p.IsScam,
+	}
+}
+
+// scamProbability - вероятность класса phishing по ответу модели. Она
+// выводится из метки, к которой относится Confidence: IsScam - решение с
+// порогом модели, и при пороге не 0.5 дополнение к нему было бы вероятностью
+// не того класса. Только для ответа с неизвестной меткой остаётся IsScam.
+func scamProbability(v ModelPrediction) float64 {
+	switch strings.ToLower(v.Label) {
+	case LabelPhishing:
+		return v.Confidence
+	case LabelLegitimate:
+		return 1 - v.Confidence
+	}
+	if v.IsScam {
+		return v.Confidence
+	}
+	return 1 - v.Confidence
+}
+
+// vote усредняет вероятность мошенничества по ответившим моделям с учётом
+// весов. Итоговая метка - класс с большей средней вероятностью; при равенстве
+// текст считается мошенническим.
+func vote(votes []ModelPrediction, errs []error) (PredictionResult, error) {
+	var weighted, totalWeight float64
+	for i, v := range votes {
+		if errs[i] != nil {
+			continue
+		}
+		weighted += scamProbability(v) * v.Weight
+		totalWeight += v.Weight
+	}
+
+	if totalWeight == 0 {
+		return PredictionResult{}, fmt.Errorf("all ML models failed: %w", errors.Join(errs...))
+	}
+
+	scamProbability := weighted / totalWeight
+	result := PredictionResult{
+		Label:      LabelPhishing,
+		IsScam:     scamProbability >= 0.5,
+		Confidence: scamProbability,
+		Models:     votes,
+	}
+	if !result.IsScam {
+		result.Label = LabelLegitimate
+		result.Confidence = 1 - scamProbability
+	}
+
+	members := make([]string, 0, len(votes))
+	for i, v := range votes {
+		if errs[i] == nil {
+			members = append(members, v.Model+"@"+v.ModelVersion)
+		}
+	}
+
+	// Версия ансамбля - состав ответивших моделей с их версиями: переобучение
+	// любой из них даёт новую версию
+	result.ModelName = EnsembleModelName
+	result.ModelVersion = strings.Join(members, "+")
+	if len(members) == 1 {
+		for i, v := range votes {
+			if errs[i] == nil {
+				result.ModelName, result.ModelVersion = v.ModelName, v.ModelVersion
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestVote(t *testing.T) {
+	failed := errors.New("model is down")
+
+	tests := []struct {
+		name           string
+		votes          []ModelPrediction
+		errs           []error
+		wantLabel      string
+		wantScam       bool
+		wantConfidence float64
+	}{
+		{
+			name: "heavier model outvotes a lighter one",
+			votes: []ModelPrediction{
+				{Model: "a", Weight: 3, Label: LabelLegitimate, Confidence: 0.9},
+				{Model: "b", Weight: 1, Label: LabelPhishing, Confidence: 0.9, IsScam: true},
+			},
+			errs:      []error{nil, nil},
+			wantLabel: LabelLegitimate,
+			// P(phishing) = (3*0.1 + 1*0.9) / 4 = 0.3
+			wantConfidence: 0.7,
+		},
+		{
+			name: "confident minority shifts the average",
+			votes: []ModelPrediction{
+				{Model: "a", Weight: 1, Label: LabelLegitimate, Confidence: 0.55},
+				{Model: "b", Weight: 1, Label: LabelPhishing, Confidence: 0.99, IsScam: true},
+			},
+			errs:           []error{nil, nil},
+			wantLabel:      LabelPhishing,
+			wantScam:       true,
+			wantConfidence: 0.72,
+		},
+		{
+			name: "phishing label below the model threshold still counts as phishing probability",
+			votes: []ModelPrediction{
+				// Порог модели 0.7: метка phishing, но IsScam false
+				{Model: "a", Weight: 1, Label: LabelPhishing, Confidence: 0.6},
+			},
+			errs:           []error{nil},
+			wantLabel:      LabelPhishing,
+			wantScam:       true,
+			wantConfidence: 0.6,
+		},
+		{
+			name: "tie is resolved as phishing",
+			votes: []ModelPrediction{
+				{Model: "a", Weight: 1, Label: LabelPhishing, Confidence: 0.8, IsScam: true},
+				{Model: "b", Weight: 1, Label: LabelLegitimate, Confidence: 0.8},
+			},
+			errs:           []error{nil, nil},
+			wantLabel:      LabelPhishing,
+			wantScam:       true,
+			wantConfidence: 0.5,
+		},
+		{
+			name: "failed model is ignored",
+			votes: []ModelPrediction{
+				{Model: "a", Weight: 10, Error: failed.Error()},
+				{Model: "b", Weight: 1, Label: LabelLegitimate, Confidence: 0.8},
+			},
+			errs:           []error{failed, nil},
+			wantLabel:      LabelLegitimate,
+			wantConfidence: 0.8,
+		},
+		{
+			name: "unknown label falls back to the model decision",
+			votes: []ModelPrediction{
+				{Model: "a", Weight: 1, Label: "spam", Confidence: 0.9, IsScam: true},
+			},
+			errs:           []error{nil},
+			wantLabel:      LabelPhishing,
+			wantScam:       true,
+			wantConfidence: 0.9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := vote(tt.votes, tt.errs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Label != tt.wantLabel || result.IsScam != tt.wantScam {
+				t.Fatalf("label = %s, is_scam = %v, want %s, %v", result.Label, result.IsScam, tt.wantLabel, tt.wantScam)
+			}
+			if math.Abs(result.Confidence-tt.wantConfidence) > 1e-9 {
+				t.Fatalf("confidence = %v, want %v", result.Confidence, tt.wantConfidence)
+			}
+			if len(result.Models) != len(tt.votes) {
+				t.Fatalf("models = %d, want %d", len(result.Models), len(tt.votes))
+			}
+		})
+	}
+}
+
+func TestVoteAllFailed(t *testing.T) {
+	failed := errors.New("model is down")
+	_, err := vote(
+		[]ModelPrediction{{Model: "a", Weight: 1, Error: failed.Error()}},
+		[]error{failed},
+	)
+	if !errors.Is(err, failed) {
+		t.Fatalf("vote error = %v, want %v", err, failed)
+	}
+}
+
+func TestVoteModelVersion(t *testing.T) {
+	failed := errors.New("model is down")
+
+	result, err := vote([]ModelPrediction{
+		{Model: "a", ModelName: "bert", ModelVersion: "1", Weight: 1, Label: LabelLegitimate, Confidence: 0.9},
+		{Model: "b", Weight: 1, Error: failed.Error()},
+	}, []error{nil, failed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ответила одна модель: результат - её предсказание
+	if result.ModelName != "bert" || result.ModelVersion != "1" {
+		t.Fatalf("model = %s@%s, want bert@1", result.ModelName, result.ModelVersion)
+	}
+
+	result, err = vote([]ModelPrediction{
+		{Model: "a", ModelVersion: "1", Weight: 1, Label: LabelLegitimate, Confidence: 0.9},
+		{Model: "b", ModelVersion: "2", Weight: 1, Label: LabelLegitimate, Confidence: 0.9},
+	}, []error{nil, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ModelName != EnsembleModelName || result.ModelVersion != "a@1+b@2" {
+		t.Fatalf("model = %s@%s, want %s@a@1+b@2", result.ModelName, result.ModelVersion, EnsembleModelName)
+	}
+}
+
+func fakeModel(name string, weight float64, responses map[string]PredictionResult) (ModelEndpoint, *FakeAnalyzer) {
+	analyzer := NewFakeAnalyzer()
+	analyzer.Responses = responses
+	analyzer.Health.ModelName = name
+	return ModelEndpoint{Name: name, Weight: weight, Analyzer: analyzer}, analyzer
+}
+
+func TestEnsembleAnalyzeText(t *testing.T) {
+	const text = "Ваша карта заблокирована"
+
+	a, _ := fakeModel("a", 2, map[string]PredictionResult{text: {Label: LabelPhishing, Confidence: 0.9, IsScam: true}})
+	b, bAnalyzer := fakeModel("b", 1, map[string]PredictionResult{text: {Label: LabelLegitimate, Confidence: 0.6}})
+	ensemble := NewEnsemble([]ModelEndpoint{a, b})
+
+	resp, err := ensemble.AnalyzeText(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// P(phishing) = (2*0.9 + 1*0.4) / 3
+	if want := 2.2 / 3; !resp.Prediction.IsScam || math.Abs(resp.Prediction.Confidence-want) > 1e-9 {
+		t.Fatalf("prediction = %+v, want phishing with confidence %v", resp.Prediction, want)
+	}
+
+	bAnalyzer.Err = errors.New("unavailable")
+	resp, err = ensemble.AnalyzeText(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Prediction.Confidence != 0.9 || resp.Prediction.Models[1].Error == "" {
+		t.Fatalf("prediction with failed member = %+v", resp.Prediction)
+	}
+}
+
+func TestEnsembleAnalyzeBatchWithFailedMember(t *testing.T) {
+	texts := []string{"первый текст", "второй текст"}
+
+	a, _ := fakeModel("a", 1, map[string]PredictionResult{
+		texts[0]: {Label: LabelPhishing, Confidence: 0.8, IsScam: true},
+		texts[1]: {Label: LabelLegitimate, Confidence: 0.7},
+	})
+	b, bAnalyzer := fakeModel("b", 1, nil)
+	bAnalyzer.Err = errors.New("unavailable")
+	ensemble := NewEnsemble([]ModelEndpoint{a, b})
+
+	resp, err := ensemble.AnalyzeBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Predictions) != 2 {
+		t.Fatalf("predictions = %d, want 2", len(resp.Predictions))
+	}
+	if p := resp.Predictions[0]; p.Label != LabelPhishing || p.Confidence != 0.8 {
+		t.Fatalf("first prediction = %+v", p)
+	}
+	if p := resp.Predictions[1]; p.Label != LabelLegitimate || math.Abs(p.Confidence-0.7) > 1e-9 {
+		t.Fatalf("second prediction = %+v", p)
+	}
+
+	a.Analyzer.(*FakeAnalyzer).Err = errors.New("unavailable")
+	if _, err := ensemble.AnalyzeBatch(context.Background(), texts); err == nil {
+		t.Fatal("expected an error when all models fail")
+	}
+}
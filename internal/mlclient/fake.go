@@ -0,0 +1,133 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var defaultFakeScamKeywords = []string{
+	"заблокирован", "cvv", "код из смс", "перейдите по ссылке", "выиграли",
+	"переведите", "данные карты", "phishing", "verify your account",
+}
+
+// FakeAnalyzer - детерминированный Analyzer без сети. Текст считается
+// мошенническим, если содержит одно из ScamKeywords; Responses позволяет
+// задать ответ для конкретного текста, Err - смоделировать сбой сервиса.
+type FakeAnalyzer struct {
+	mu sync.Mutex
+
+	ScamKeywords []string
+	Responses    map[string]PredictionResult
+	Err          error
+	Health       *HealthResponse
+
+	TextCalls  int
+	BatchCalls int
+}
+
+var _ Analyzer = (*FakeAnalyzer)(nil)
+var _ ModelVersioner = (*FakeAnalyzer)(nil)
+
+func NewFakeAnalyzer() *FakeAnalyzer {
+	return &FakeAnalyzer{
+		ScamKeywords: defaultFakeScamKeywords,
+		Responses:    make(map[string]PredictionResult),
+		Health: &HealthResponse{
+			Status:      "healthy",
+			ModelLoaded: true,
+			ModelName:   "fake",
+			Version:     "0.0.0",
+		},
+	}
+}
+
+func (f *FakeAnalyzer) AnalyzeText(ctx context.Context, text string) (*TextAnalysisResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.TextCalls++
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &TextAnalysisResponse{
+		Success:        true,
+		Prediction:     f.predict(text),
+		ProcessingTime: 0.001,
+	}, nil
+}
+
+func (f *FakeAnalyzer) AnalyzeBatch(ctx context.Context, texts []string) (*BatchTextAnalysisResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.BatchCalls++
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	predictions := make([]PredictionResult, 0, len(texts))
+	for _, text := range texts {
+		predictions = append(predictions, f.predict(text))
+	}
+
+	return &BatchTextAnalysisResponse{
+		Success:        true,
+		Predictions:    predictions,
+		ProcessingTime: 0.001 * float64(len(texts)),
+	}, nil
+}
+
+func (f *FakeAnalyzer) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	health := *f.Health
+	return &health, nil
+}
+
+func (f *FakeAnalyzer) ModelVersion(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Health == nil {
+		return "", errors.New("fake model has no health info")
+	}
+	return f.Health.ModelName + "@" + f.Health.Version, nil
+}
+
+func (f *FakeAnalyzer) predict(text string) PredictionResult {
+	result, ok := f.Responses[text]
+	if !ok {
+		result = PredictionResult{Label: LabelLegitimate, Confidence: 0.9, IsScam: false}
+
+		lower := strings.ToLower(text)
+		for _, keyword := range f.ScamKeywords {
+			if strings.Contains(lower, keyword) {
+				result = PredictionResult{Label: LabelPhishing, Confidence: 0.95, IsScam: true}
+				break
+			}
+		}
+	}
+
+	if f.Health != nil {
+		stampModel(&result, f.Health.ModelName, f.Health.Version)
+	}
+
+	return result
+}
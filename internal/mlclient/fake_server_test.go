@@ -0,0 +1,147 @@
+package mlclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newFakeServer поднимает httptest сервер с API Python ML сервиса
+// (/health, /api/v1/analyze/text, /api/v1/analyze/batch), отвечающий через
+// переданный Analyzer. Позволяет проверять MLClient целиком, включая HTTP.
+func newFakeServer(t *testing.T, analyzer Analyzer) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		health, err := analyzer.HealthCheck(r.Context())
+		if err != nil {
+			writeFakeError(w, err)
+			return
+		}
+		writeFakeJSON(w, health)
+	})
+
+	mux.HandleFunc("POST /api/v1/analyze/text", func(w http.ResponseWriter, r *http.Request) {
+		var req TextAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Text == "" {
+			http.Error(w, `{"detail":"invalid request"}`, http.StatusUnprocessableEntity)
+			return
+		}
+
+		result, err := analyzer.AnalyzeText(r.Context(), req.Text)
+		if err != nil {
+			writeFakeError(w, err)
+			return
+		}
+		writeFakeJSON(w, result)
+	})
+
+	mux.HandleFunc("POST /api/v1/analyze/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req BatchTextAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Texts) == 0 {
+			http.Error(w, `{"detail":"invalid request"}`, http.StatusUnprocessableEntity)
+			return
+		}
+
+		result, err := analyzer.AnalyzeBatch(r.Context(), req.Texts)
+		if err != nil {
+			writeFakeError(w, err)
+			return
+		}
+		writeFakeJSON(w, result)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeFakeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{"detail": err.Error()})
+}
+
+func TestMLClientWithFakeServer(t *testing.T) {
+	fake := NewFakeAnalyzer()
+	fake.Health.ModelName, fake.Health.Version = "rubert-scam", "2.1.0"
+	server := newFakeServer(t, fake)
+	client := NewMLClient(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	health, err := client.HealthCheck(ctx)
+	if err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	if *health != *fake.Health {
+		t.Fatalf("health = %+v, want %+v", *health, *fake.Health)
+	}
+	if version, err := client.ModelVersion(ctx); err != nil || version != "rubert-scam@2.1.0" {
+		t.Fatalf("ModelVersion = %q, %v", version, err)
+	}
+
+	text, err := client.AnalyzeText(ctx, "Ваша карта заблокирована, назовите CVV")
+	if err != nil {
+		t.Fatalf("AnalyzeText: %v", err)
+	}
+	if !text.Success || !text.Prediction.IsScam || text.Prediction.Label != LabelPhishing {
+		t.Fatalf("AnalyzeText = %+v, want a phishing prediction", *text)
+	}
+	if text.Prediction.ModelName != "rubert-scam" || text.Prediction.ModelVersion != "2.1.0" {
+		t.Fatalf("prediction model = %s@%s, want rubert-scam@2.1.0", text.Prediction.ModelName, text.Prediction.ModelVersion)
+	}
+
+	texts := []string{"Привет, как дела?", "Вы выиграли приз", "Встреча в 15:00"}
+	batch, err := client.AnalyzeBatch(ctx, texts)
+	if err != nil {
+		t.Fatalf("AnalyzeBatch: %v", err)
+	}
+	if len(batch.Predictions) != len(texts) {
+		t.Fatalf("AnalyzeBatch returned %d predictions for %d texts", len(batch.Predictions), len(texts))
+	}
+	for i, want := range []bool{false, true, false} {
+		if batch.Predictions[i].IsScam != want {
+			t.Errorf("prediction %d: is_scam = %v, want %v", i, batch.Predictions[i].IsScam, want)
+		}
+	}
+	if fake.TextCalls != 1 || fake.BatchCalls != 1 {
+		t.Fatalf("fake calls: text %d, batch %d, want 1 and 1", fake.TextCalls, fake.BatchCalls)
+	}
+}
+
+func TestMLClientWithFailingFakeServer(t *testing.T) {
+	fake := NewFakeAnalyzer()
+	fake.Err = errors.New("model is not loaded")
+	server := newFakeServer(t, fake)
+	client := NewMLClient(Config{
+		BaseURL:            server.URL,
+		RetryBaseDelay:     time.Millisecond,
+		BreakerThreshold:   1,
+		BreakerOpenTimeout: time.Minute,
+	})
+	ctx := context.Background()
+
+	if _, err := client.HealthCheck(ctx); err == nil {
+		t.Fatal("HealthCheck of a failing service succeeded")
+	}
+
+	var statusErr *StatusError
+	if _, err := client.AnalyzeBatch(ctx, []string{"text"}); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("AnalyzeBatch error = %v, want status 500", err)
+	}
+	if _, err := client.AnalyzeText(ctx, "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("AnalyzeText after a failure = %v, want %v", err, ErrCircuitOpen)
+	}
+	if fake.TextCalls != 0 {
+		t.Fatalf("open breaker reached the service %d times", fake.TextCalls)
+	}
+}
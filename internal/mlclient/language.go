@@ -0,0 +1,33 @@
+package mlclient
+
+import "unicode"
+
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+	LanguageUnknown = "unknown"
+)
+
+// DetectLanguage различает русский и английский по преобладающему алфавиту.
+// Для маршрутизации между моделями этого достаточно: смешанные тексты уходят
+// модели того алфавита, букв которого больше.
+func DetectLanguage(text string) string {
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case cyrillic == 0 && latin == 0:
+		return LanguageUnknown
+	case cyrillic >= latin:
+		return LanguageRussian
+	default:
+		return LanguageEnglish
+	}
+}
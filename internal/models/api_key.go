@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// APIKey - ключ доступа к gRPC API для внутренних сервисов. Хранится только
+// SHA-256 хэш ключа; Prefix - начало ключа, по которому владелец отличает
+// ключи в списке.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:128;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	AuditLoginSuccess     = "login_success"
+	AuditLoginFailed      = "login_failed"
+	AuditRegister         = "register"
+	AuditTokenRefresh     = "token_refresh"
+	AuditRefreshReuse     = "refresh_token_reuse"
+	AuditLogout           = "logout"
+	AuditPasswordChanged  = "password_changed"
+	AuditProfileUpdated   = "profile_updated"
+	AuditAccountDeleted   = "account_deleted"
+	AuditAccountRestored  = "account_restored"
+	AuditAccountPurged    = "account_purged"
+	AuditOIDCLogin        = "oidc_login"
+	AuditIdentityLinked   = "identity_linked"
+	AuditIdentityUnlinked = "identity_unlinked"
+	AuditAPIKeyCreated    = "api_key_created"
+	AuditAPIKeyRevoked    = "api_key_revoked"
+)
+
+// AuditEvent - запись журнала безопасности. Таблица только дополняется:
+// репозиторий не предоставляет обновления и удаления.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"user_id"`
+	ActorID   *uint     `gorm:"index" json:"actor_id"`
+	EventType string    `gorm:"size:64;not null;index" json:"event_type"`
+	IPAddress string    `gorm:"size:64" json:"ip_address"`
+	UserAgent string    `gorm:"size:512" json:"user_agent"`
+	Metadata  string    `gorm:"type:text" json:"metadata"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+type AuditEventFilter struct {
+	UserID    *uint
+	EventType string
+	IPAddress string
+	From      *time.Time
+	To        *time.Time
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	BatchPending   = "pending"
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+	BatchFailed    = "failed"
+)
+
+// BatchJob - пакетный анализ текстов. Тексты разбиваются на части по размеру
+// пакета ML сервиса и обрабатываются параллельно; проверки пакета ссылаются
+// на задачу через Check.BatchID.
+type BatchJob struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Status     string     `gorm:"size:16;not null;index" json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Failed     int        `json:"failed"`
+	Degraded   int        `json:"degraded"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (j *BatchJob) Done() bool {
+	return j.Status == BatchCompleted || j.Status == BatchFailed
+}
+
+const (
+	BatchItemOK       = "ok"
+	BatchItemDegraded = "degraded"
+	BatchItemFailed   = "failed"
+
+	BatchErrorMLUnavailable     = "ml_unavailable"
+	BatchErrorMLTimeout         = "ml_timeout"
+	BatchErrorML                = "ml_error"
+	BatchErrorMissingPrediction = "missing_prediction"
+	BatchErrorStorage           = "storage_error"
+	BatchErrorCanceled          = "canceled"
+	// BatchErrorInvalidText - пустой или слишком длинный текст в потоке
+	// gRPC AnalyzeStream; пакеты проверяются целиком до обработки
+	BatchErrorInvalidText = "invalid_text"
+)
+
+// BatchItem - результат одного текста пакета. Записывается для каждого
+// текста, в том числе неудавшегося, в порядке завершения.
+type BatchItem struct {
+	ID      uint `gorm:"primaryKey" json:"id"`
+	BatchID uint `gorm:"not null;uniqueIndex:idx_batch_items_index;uniqueIndex:idx_batch_items_seq" json:"batch_id"`
+	// Seq - номер результата в пакете в порядке записи, с 1. Части пакета
+	// получают номера под блокировкой задачи, поэтому результат с большим
+	// Seq никогда не становится виден раньше результата с меньшим: Seq
+	// служит курсором для получения результатов по мере обработки. ID
+	// выдаются до фиксации транзакции и таким курсором быть не могут.
+	Seq         uint      `gorm:"not null;uniqueIndex:idx_batch_items_seq" json:"seq"`
+	Index       int       `gorm:"not null;uniqueIndex:idx_batch_items_index" json:"index"`
+	Status      string    `gorm:"size:16;not null" json:"status"`
+	CheckID     *uint     `json:"check_id,omitempty"`
+	DangerScore float64   `json:"danger_score"`
+	DangerLevel string    `gorm:"size:16" json:"danger_level,omitempty"`
+	ErrorCode   string    `gorm:"size:32" json:"error_code,omitempty"`
+	Error       string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -2,12 +2,19 @@ package models
 
 import "time"
 
+// FeatureRuleMatch - сработавшее правило: FeatureValue - шаблон,
+// ConfidenceScore - его вес в оценке правил.
+const FeatureRuleMatch = "rule_match"
+
 type CheckDetail struct {
 	ID              uint      `gorm:"primaryKey" json:"id"`
 	CheckID         uint      `gorm:"not null;index" json:"check_id"`
 	FeatureName     string    `gorm:"not null" json:"feature_name"`
 	FeatureValue    string    `gorm:"type:text" json:"feature_value"`
 	ConfidenceScore float64   `json:"confidence_score"`
+	ModelName       string    `gorm:"size:128" json:"model_name"`
+	ModelVersion    string    `gorm:"size:256" json:"model_version"`
+	Revision        int       `gorm:"not null;default:1;index" json:"revision"`
 	CreatedAt       time.Time `json:"created_at"`
 
 	Check Check `gorm:"foreignKey:CheckID" json:"-"`
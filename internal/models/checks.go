@@ -5,17 +5,158 @@ import (
 )
 
 type Check struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	Title          string    `gorm:"not null" json:"title"`
-	ContentType    string    `gorm:"not null" json:"content_type"`
-	Content        string    `gorm:"type:text" json:"content"`
-	DangerScore    float64   `json:"danger_score"`
-	DangerLevel    string    `json:"danger_level"`
-	Status         string    `gorm:"default:processing" json:"status"`
-	UserID         uint      `gorm:"not null" json:"user_id"`
+	ID           uint    `gorm:"primaryKey" json:"id"`
+	Title        string  `gorm:"not null" json:"title"`
+	ContentType  string  `gorm:"not null" json:"content_type"`
+	Content      string  `gorm:"type:text" json:"content"`
+	DangerScore  float64 `json:"danger_score"`
+	DangerLevel  string  `json:"danger_level"`
+	Status       string  `gorm:"default:processing" json:"status"`
+	Degraded     bool    `gorm:"not null;default:false;index" json:"degraded"`
+	UserVerdict  *bool   `json:"user_verdict"`
+	ModelName    string  `gorm:"size:128;index:idx_checks_model" json:"model_name"`
+	ModelVersion string  `gorm:"size:256;index:idx_checks_model" json:"model_version"`
+	Revision     int     `gorm:"not null;default:1" json:"revision"`
+	UserID       uint    `gorm:"not null;index" json:"user_id"`
+	BatchID      *uint   `gorm:"index:idx_checks_batch" json:"batch_id,omitempty"`
+	BatchIndex   *int    `gorm:"index:idx_checks_batch" json:"batch_index,omitempty"`
+	// ProcessingTime у проверок пакета - оценка: время запроса части к ML
+	// сервису, делённое на число её текстов
 	ProcessingTime int       `json:"processing_time_ms"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	// Snippet - фрагмент текста с найденными словами в <mark>, заполняется
+	// только при полнотекстовом поиске
+	Snippet string `gorm:"->" json:"snippet,omitempty"`
 
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
+
+const (
+	HistorySortCreatedAt      = "created_at"
+	HistorySortDangerScore    = "danger_score"
+	HistorySortProcessingTime = "processing_time"
+	HistorySortRelevance      = "relevance"
+)
+
+// CheckHistoryFilter - фильтры истории проверок. Пустые поля не
+// ограничивают выборку. Query - строка полнотекстового поиска по Content.
+type CheckHistoryFilter struct {
+	DangerLevels []string
+	Statuses     []string
+	ContentType  string
+	MinScore     *float64
+	MaxScore     *float64
+	From         *time.Time
+	To           *time.Time
+	HasFeedback  *bool
+	Query        string
+
+	// Sort - поле сортировки, Asc - по возрастанию (по умолчанию по убыванию)
+	Sort string
+	Asc  bool
+}
+
+// CheckCursor - позиция в истории, отсортированной по (created_at, id).
+type CheckCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// CheckPage - какую страницу истории выбрать: по смещению Offset или,
+// если задан курсор, строго после After либо строго перед Before в
+// порядке сортировки. SkipTotal отключает подсчёт общего числа проверок.
+type CheckPage struct {
+	Limit     int
+	Offset    int
+	After     *CheckCursor
+	Before    *CheckCursor
+	SkipTotal bool
+}
+
+// CheckList - страница истории. Total nil, если подсчёт отключён; HasMore -
+// за страницей в направлении выборки есть ещё проверки.
+type CheckList struct {
+	Checks  []Check
+	Total   *int64
+	HasMore bool
+}
+
+// UserStats - сводка по проверкам пользователя. ByLevel - число проверок
+// для каждого уровня опасности.
+type UserStats struct {
+	TotalAnalyses         int64            `json:"total_analyses"`
+	SafeCount             int64            `json:"safe_count"`
+	SuspiciousCount       int64            `json:"suspicious_count"`
+	DangerousCount        int64            `json:"dangerous_count"`
+	AverageRiskScore      float64          `json:"average_risk_score"`
+	AverageProcessingTime int64            `json:"average_processing_time"`
+	ByLevel               map[string]int64 `json:"by_level"`
+}
+
+const (
+	StatsBucketHour = "hour"
+	StatsBucketDay  = "day"
+	StatsBucketWeek = "week"
+)
+
+// StatsBucket - проверки, созданные в интервале [Start, Start+bucket).
+type StatsBucket struct {
+	Start                 time.Time        `json:"start"`
+	Total                 int64            `json:"total"`
+	ByLevel               map[string]int64 `json:"by_level"`
+	AverageRiskScore      float64          `json:"average_risk_score"`
+	AverageProcessingTime float64          `json:"average_processing_time"`
+}
+
+// StatsTimeSeriesQuery - период [From, To), размер интервала и часовой
+// пояс (имя IANA), в котором интервалы начинаются.
+type StatsTimeSeriesQuery struct {
+	From     time.Time
+	To       time.Time
+	Bucket   string
+	Timezone string
+	Top      int
+}
+
+type StatsCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// StatsTimeSeries - статистика проверок по интервалам за период. TopRules -
+// чаще всего срабатывавшие правила, TopIndicators - домены ссылок в
+// опасных проверках.
+type StatsTimeSeries struct {
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	Bucket        string        `json:"bucket"`
+	Timezone      string        `json:"timezone"`
+	Buckets       []StatsBucket `json:"buckets"`
+	TopRules      []StatsCount  `json:"top_rules"`
+	TopIndicators []StatsCount  `json:"top_indicators"`
+}
+
+const (
+	// Модель, которой помечаются проверки, оценённые только правилами
+	RulesModelName    = "rules"
+	RulesModelVersion = "1"
+
+	RevisionSourceAnalysis   = "analysis"
+	RevisionSourceReanalysis = "reanalysis"
+)
+
+// CheckRevision - одна из оценок проверки. Текущая оценка хранится в самой
+// проверке, прежние - здесь; детали каждой ревизии остаются в check_details.
+type CheckRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CheckID      uint      `gorm:"not null;uniqueIndex:idx_check_revision" json:"check_id"`
+	Revision     int       `gorm:"not null;uniqueIndex:idx_check_revision" json:"revision"`
+	DangerScore  float64   `json:"danger_score"`
+	DangerLevel  string    `json:"danger_level"`
+	ModelName    string    `gorm:"size:128" json:"model_name"`
+	ModelVersion string    `gorm:"size:256" json:"model_version"`
+	Source       string    `gorm:"size:32;not null" json:"source"`
+	JobID        *uint     `gorm:"index" json:"job_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+const (
+	VariantLive      = "live"
+	VariantCandidate = "candidate"
+)
+
+// ModelEvaluation - предсказание одной модели для проверки. Served = true у
+// модели, чей ответ получил пользователь; у теневой модели ответ только
+// сохраняется для сравнения.
+type ModelEvaluation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CheckID     uint      `gorm:"not null;uniqueIndex:idx_model_evaluation_check_variant" json:"check_id"`
+	Variant     string    `gorm:"size:16;not null;uniqueIndex:idx_model_evaluation_check_variant" json:"variant"`
+	Model       string    `gorm:"size:128;not null" json:"model"`
+	Served      bool      `gorm:"not null" json:"served"`
+	Label       string    `gorm:"size:64" json:"label"`
+	IsScam      bool      `json:"is_scam"`
+	Confidence  float64   `json:"confidence"`
+	DangerScore float64   `json:"danger_score"`
+	LatencyMs   int       `json:"latency_ms"`
+	Error       string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+type ScoreBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+type VariantReport struct {
+	Variant          string        `json:"variant"`
+	Model            string        `json:"model"`
+	Evaluations      int64         `json:"evaluations"`
+	Served           int64         `json:"served"`
+	Errors           int64         `json:"errors"`
+	ScamRate         float64       `json:"scam_rate"`
+	AvgDangerScore   float64       `json:"avg_danger_score"`
+	AvgLatencyMs     float64       `json:"avg_latency_ms"`
+	ScoreHistogram   []ScoreBucket `json:"score_histogram"`
+	FeedbackCount    int64         `json:"feedback_count"`
+	FeedbackAccuracy *float64      `json:"feedback_accuracy"`
+}
+
+// ModelComparisonReport сравнивает live и candidate модели на проверках,
+// где ответили обе.
+type ModelComparisonReport struct {
+	Since         time.Time       `json:"since"`
+	PairedChecks  int64           `json:"paired_checks"`
+	Agreements    int64           `json:"agreements"`
+	AgreementRate *float64        `json:"agreement_rate"`
+	Variants      []VariantReport `json:"variants"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	ReanalysisPending   = "pending"
+	ReanalysisRunning   = "running"
+	ReanalysisCompleted = "completed"
+	ReanalysisFailed    = "failed"
+)
+
+// CheckFilter отбирает исторические проверки для повторного анализа.
+type CheckFilter struct {
+	UserID *uint      `json:"user_id,omitempty"`
+	From   *time.Time `json:"from,omitempty"`
+	To     *time.Time `json:"to,omitempty"`
+	// DangerLevel - только проверки с указанным уровнем опасности
+	DangerLevel string `json:"danger_level,omitempty"`
+	// ModelVersion - только проверки, оценённые этой версией модели
+	ModelVersion string `json:"model_version,omitempty"`
+	// ExcludeModelVersion - пропустить проверки, уже оценённые этой версией
+	ExcludeModelVersion string `json:"exclude_model_version,omitempty"`
+	Limit               int    `json:"limit,omitempty"`
+}
+
+type ReanalysisJob struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Status       string     `gorm:"size:16;not null;index" json:"status"`
+	Filter       string     `gorm:"type:text" json:"filter"`
+	CreatedBy    uint       `gorm:"not null" json:"created_by"`
+	Total        int64      `json:"total"`
+	Processed    int64      `json:"processed"`
+	Failed       int64      `json:"failed"`
+	ModelName    string     `gorm:"size:128" json:"model_name"`
+	ModelVersion string     `gorm:"size:256" json:"model_version"`
+	Error        string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -4,14 +4,22 @@ import (
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	Username     string    `gorm:"uniqueIndex;not null" json:"username"`
-	Email        *string   `gorm:"uniqueIndex" json:"email"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	IsActive     bool      `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	Username            string     `gorm:"uniqueIndex;not null" json:"username"`
+	Email               *string    `gorm:"uniqueIndex" json:"email"`
+	PasswordHash        string     `gorm:"not null" json:"-"`
+	HasPassword         bool       `gorm:"default:true" json:"has_password"`
+	Role                string     `gorm:"size:32;not null;default:user" json:"role"`
+	IsActive            bool       `gorm:"default:true" json:"is_active"`
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 
 	Checks []Check `gorm:"foreignKey:UserID" json:"checks,omitempty"`
 }
@@ -19,7 +27,7 @@ type User struct {
 type CreateUserRequest struct {
 	Username string  `json:"username" binding:"required,min=3"`
 	Email    *string `json:"email" binding:"omitempty,email"`
-	Password string  `json:"password" binding:"required,min=6"`
+	Password string  `json:"password" binding:"required"`
 }
 
 type UpdateUserRequest struct {
@@ -28,6 +36,6 @@ type UpdateUserRequest struct {
 }
 
 type UpdatePasswordRequest struct {
-	CurrentPassword string `json:"cur_password" binding:"required"`
-	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	CurrentPassword string `json:"cur_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
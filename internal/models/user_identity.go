@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Provider  string    `gorm:"size:64;not null;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"subject"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type OIDCAuthState struct {
+	State        string    `gorm:"primaryKey;size:64"`
+	Provider     string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:128;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	LinkUserID   *uint     `gorm:"index"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
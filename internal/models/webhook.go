@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// События, на которые можно подписать вебхук
+const (
+	WebhookEventCompleted = "check.completed"
+	WebhookEventHighRisk  = "check.high_risk"
+	WebhookEventFeedback  = "check.feedback"
+	// WebhookEventTest отправляется только по запросу пользователя
+	WebhookEventTest = "webhook.test"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead - все попытки исчерпаны; доставку можно повторить вручную
+	DeliveryDead = "dead"
+)
+
+type Webhook struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	URL    string `gorm:"size:2048;not null" json:"url"`
+	// Secret - ключ HMAC-SHA256 подписи; показывается только при создании
+	Secret string `gorm:"size:128;not null" json:"-"`
+	// Events - события через запятую
+	Events    string    `gorm:"size:256;not null" json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	EventType      string     `gorm:"size:32;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:16;not null;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Webhook Webhook `gorm:"foreignKey:WebhookID" json:"-"`
+}
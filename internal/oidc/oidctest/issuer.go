@@ -0,0 +1,206 @@
+// Package oidctest - OIDC провайдер для тестов: discovery, JWKS и token
+// endpoint с проверкой PKCE поверх httptest.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "test-key"
+
+// Identity - пользователь, от имени которого провайдер выдаёт id_token.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+
+	// Nonce заменяет nonce из запроса авторизации, если не пуст
+	Nonce string
+	// ExpiresIn - время жизни id_token; отрицательное выдаёт просроченный
+	// токен, ноль - час
+	ExpiresIn time.Duration
+}
+
+type authRequest struct {
+	challenge string
+	nonce     string
+	identity  Identity
+}
+
+// Issuer - запущенный провайдер. Сервер останавливается по t.Cleanup.
+type Issuer struct {
+	URL      string
+	ClientID string
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+func NewIssuer(t testing.TB, clientID string) *Issuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &Issuer{ClientID: clientID, key: key, codes: make(map[string]authRequest)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("GET /jwks", issuer.jwks)
+	mux.HandleFunc("POST /token", issuer.token)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	issuer.URL = server.URL
+
+	return issuer
+}
+
+// Authorize имитирует вход пользователя у провайдера: проверяет URL
+// авторизации и возвращает code и state для callback.
+func (i *Issuer) Authorize(authURL string, identity Identity) (code, state string, err error) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := parsed.Query()
+	switch {
+	case query.Get("client_id") != i.ClientID:
+		return "", "", errors.New("unknown client_id")
+	case query.Get("response_type") != "code":
+		return "", "", errors.New("unsupported response_type")
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		return "", "", errors.New("PKCE S256 is required")
+	}
+
+	code = randomString()
+	i.mu.Lock()
+	i.codes[code] = authRequest{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		identity:  identity,
+	}
+	i.mu.Unlock()
+
+	return code, query.Get("state"), nil
+}
+
+// SignIDToken подписывает claims ключом провайдера.
+func (i *Issuer) SignIDToken(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// IDTokenClaims возвращает claims id_token для identity с указанным nonce.
+func (i *Issuer) IDTokenClaims(identity Identity, nonce string) jwt.MapClaims {
+	if identity.Nonce != "" {
+		nonce = identity.Nonce
+	}
+	expiresIn := identity.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = time.Hour
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"sub":   identity.Subject,
+		"nonce": nonce,
+		"iat":   now.Add(min(expiresIn, 0) - time.Hour).Unix(),
+		"exp":   now.Add(expiresIn).Unix(),
+	}
+	if identity.Email != "" {
+		claims["email"] = identity.Email
+		claims["email_verified"] = identity.EmailVerified
+	}
+	if identity.PreferredUsername != "" {
+		claims["preferred_username"] = identity.PreferredUsername
+	}
+	return claims
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	public := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": keyID,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	request, ok := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != request.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     i.SignIDToken(i.IDTokenClaims(request.identity, request.nonce)),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,169 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"scam-detection-backend/internal/oidc/oidctest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// Пример из RFC 7636, приложение B
+	got := CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Fatalf("CodeChallengeS256 = %q, want %q", got, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client")
+	provider := NewProvider(Config{Name: "test", Issuer: issuer.URL, ClientID: "client", RedirectURL: "http://app/callback"})
+
+	raw, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authURL.Query()
+	for param, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          "http://app/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	} {
+		if got := query.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+}
+
+func TestExchangeRequiresMatchingVerifier(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client")
+	provider := NewProvider(Config{Name: "test", Issuer: issuer.URL, ClientID: "client"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		verifier string
+		wantErr  bool
+	}{
+		{name: "matching verifier", verifier: "verifier"},
+		{name: "wrong verifier", verifier: "other", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", CodeChallengeS256("verifier"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, _, err := issuer.Authorize(authURL, oidctest.Identity{Subject: "sub"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tokens, err := provider.Exchange(ctx, code, tt.verifier)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Exchange succeeded with wrong code_verifier")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tokens.IDToken == "" {
+				t.Fatal("Exchange returned no id_token")
+			}
+		})
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client")
+	provider := NewProvider(Config{Name: "test", Issuer: issuer.URL, ClientID: "client"})
+
+	valid := func() jwt.MapClaims {
+		return issuer.IDTokenClaims(oidctest.Identity{Subject: "sub", Email: "a@example.com", EmailVerified: true}, "nonce")
+	}
+
+	tests := []struct {
+		name    string
+		claims  func() jwt.MapClaims
+		wantErr error
+	}{
+		{name: "valid", claims: valid},
+		{name: "nonce mismatch", claims: func() jwt.MapClaims {
+			claims := valid()
+			claims["nonce"] = "other"
+			return claims
+		}, wantErr: ErrNonceMismatch},
+		{name: "expired", claims: func() jwt.MapClaims {
+			claims := valid()
+			claims["exp"] = time.Now().Add(-5 * time.Minute).Unix()
+			return claims
+		}, wantErr: jwt.ErrTokenExpired},
+		{name: "expiry within leeway", claims: func() jwt.MapClaims {
+			claims := valid()
+			claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+			return claims
+		}},
+		{name: "no expiry", claims: func() jwt.MapClaims {
+			claims := valid()
+			delete(claims, "exp")
+			return claims
+		}, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "other audience", claims: func() jwt.MapClaims {
+			claims := valid()
+			claims["aud"] = "other-client"
+			return claims
+		}, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "other issuer", claims: func() jwt.MapClaims {
+			claims := valid()
+			claims["iss"] = "https://evil.example.com"
+			return claims
+		}, wantErr: jwt.ErrTokenInvalidIssuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(context.Background(), issuer.SignIDToken(tt.claims()), "nonce")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyIDToken error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "sub" || claims.Email != "a@example.com" || !claims.EmailVerified {
+				t.Fatalf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsForeignSignature(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client")
+	other := oidctest.NewIssuer(t, "client")
+	provider := NewProvider(Config{Name: "test", Issuer: issuer.URL, ClientID: "client"})
+
+	// Токен с верными claims, но подписанный чужим ключом с тем же kid
+	claims := issuer.IDTokenClaims(oidctest.Identity{Subject: "sub"}, "nonce")
+	_, err := provider.VerifyIDToken(context.Background(), other.SignIDToken(claims), "nonce")
+	if err == nil || !strings.Contains(err.Error(), "invalid id_token") {
+		t.Fatalf("VerifyIDToken error = %v, want invalid signature", err)
+	}
+}
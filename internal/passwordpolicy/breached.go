@@ -0,0 +1,94 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const hashPrefixLength = 5
+
+// BreachedList хранит SHA-1 хеши утёкших паролей, сгруппированные по
+// 5-символьному префиксу, как в k-anonymity API Have I Been Pwned.
+type BreachedList struct {
+	buckets map[string]map[string]struct{}
+	size    int
+}
+
+// LoadBreachedList читает файл в формате HIBP: по одной строке
+// "SHA1HEX" или "SHA1HEX:count". Пустые строки и строки с # пропускаются.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть список утёкших паролей: %w", err)
+	}
+	defer file.Close()
+
+	list := &BreachedList{buckets: make(map[string]map[string]struct{})}
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("невалидный хеш в строке %d", lineNum)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("невалидный хеш в строке %d: %w", lineNum, err)
+		}
+
+		list.add(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать список утёкших паролей: %w", err)
+	}
+
+	return list, nil
+}
+
+func (l *BreachedList) add(hash string) {
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+	bucket, ok := l.buckets[prefix]
+	if !ok {
+		bucket = make(map[string]struct{})
+		l.buckets[prefix] = bucket
+	}
+	if _, exists := bucket[suffix]; !exists {
+		bucket[suffix] = struct{}{}
+		l.size++
+	}
+}
+
+func (l *BreachedList) Contains(password string) bool {
+	if l == nil {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	bucket, ok := l.buckets[hash[:hashPrefixLength]]
+	if !ok {
+		return false
+	}
+	_, found := bucket[hash[hashPrefixLength:]]
+	return found
+}
+
+func (l *BreachedList) Size() int {
+	if l == nil {
+		return 0
+	}
+	return l.size
+}
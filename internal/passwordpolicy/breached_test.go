@@ -0,0 +1,103 @@
+package passwordpolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeBreachedList(t *testing.T, lines ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	hunter := sha1Hex("hunter2")
+
+	tests := []struct {
+		name     string
+		lines    []string
+		wantSize int
+		wantErr  string
+	}{
+		{
+			name:     "hashes with counts and comments",
+			lines:    []string{"# HIBP export", "", strings.ToUpper(hunter) + ":17", "  " + sha1Hex("qwerty") + "  "},
+			wantSize: 2,
+		},
+		{
+			name:     "same hash in different case",
+			lines:    []string{strings.ToUpper(hunter), hunter + ":3"},
+			wantSize: 1,
+		},
+		{name: "short hash", lines: []string{hunter[:39]}, wantErr: "строке 1"},
+		{name: "not hex", lines: []string{hunter, strings.Repeat("Z", 40)}, wantErr: "строке 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := LoadBreachedList(writeBreachedList(t, tt.lines...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadBreachedList error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadBreachedList: %v", err)
+			}
+			if list.Size() != tt.wantSize {
+				t.Fatalf("size = %d, want %d", list.Size(), tt.wantSize)
+			}
+		})
+	}
+
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("LoadBreachedList accepted a missing file")
+	}
+}
+
+func TestBreachedListContains(t *testing.T) {
+	hunter := sha1Hex("hunter2")
+	// Другой хеш с тем же 5-символьным префиксом: совпасть должен суффикс
+	samePrefix := hunter[:hashPrefixLength] + strings.Repeat("0", len(hunter)-hashPrefixLength)
+
+	list, err := LoadBreachedList(writeBreachedList(t, strings.ToLower(hunter)+":5", samePrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "hunter2", want: true},
+		// Хеш считается от пароля как есть, регистр пароля важен
+		{password: "Hunter2", want: false},
+		{password: "hunter3", want: false},
+		{password: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+
+	var empty *BreachedList
+	if empty.Contains("hunter2") || empty.Size() != 0 {
+		t.Fatal("nil list must be empty")
+	}
+}
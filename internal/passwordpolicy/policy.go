@@ -0,0 +1,144 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingLower     = "missing_lowercase"
+	CodeMissingUpper     = "missing_uppercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+	CodeContainsUsername = "contains_username"
+	CodeContainsEmail    = "contains_email"
+	CodeTooWeak          = "too_weak"
+	CodeBreached         = "breached"
+)
+
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ViolationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "пароль не соответствует политике: " + strings.Join(messages, "; ")
+}
+
+type Config struct {
+	MinLength      int
+	MaxLength      int
+	RequireLower   bool
+	RequireUpper   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	MinStrength    int
+	ForbidIdentity bool
+}
+
+type Policy struct {
+	Config
+	breached *BreachedList
+}
+
+func New(cfg Config, breached *BreachedList) *Policy {
+	return &Policy{
+		Config:   cfg,
+		breached: breached,
+	}
+}
+
+// Validate проверяет пароль и возвращает *ViolationError со всеми нарушениями,
+// либо nil, если пароль допустим.
+func (p *Policy) Validate(password, username string, email *string) error {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, Violation{Code: CodeTooShort, Message: "пароль слишком короткий"})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{Code: CodeTooLong, Message: "пароль слишком длинный"})
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireLower && !hasLower {
+		violations = append(violations, Violation{Code: CodeMissingLower, Message: "пароль должен содержать строчную букву"})
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, Violation{Code: CodeMissingUpper, Message: "пароль должен содержать заглавную букву"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{Code: CodeMissingDigit, Message: "пароль должен содержать цифру"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{Code: CodeMissingSymbol, Message: "пароль должен содержать спецсимвол"})
+	}
+
+	passwordLower := strings.ToLower(password)
+	var userInputs []string
+
+	if p.ForbidIdentity {
+		if username != "" && strings.Contains(passwordLower, strings.ToLower(username)) {
+			violations = append(violations, Violation{Code: CodeContainsUsername, Message: "пароль не должен содержать имя пользователя"})
+		}
+		if email != nil && *email != "" && containsEmail(passwordLower, *email) {
+			violations = append(violations, Violation{Code: CodeContainsEmail, Message: "пароль не должен содержать email"})
+		}
+	}
+
+	if username != "" {
+		userInputs = append(userInputs, username)
+	}
+	if email != nil && *email != "" {
+		userInputs = append(userInputs, *email)
+	}
+
+	if p.MinStrength > 0 && EstimateStrength(password, userInputs...).Score < p.MinStrength {
+		violations = append(violations, Violation{Code: CodeTooWeak, Message: "пароль слишком простой"})
+	}
+
+	if p.breached != nil && p.breached.Contains(password) {
+		violations = append(violations, Violation{Code: CodeBreached, Message: "пароль найден в утечках, выберите другой"})
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+
+	return nil
+}
+
+func containsEmail(passwordLower, email string) bool {
+	emailLower := strings.ToLower(email)
+	if strings.Contains(passwordLower, emailLower) {
+		return true
+	}
+
+	local, _, found := strings.Cut(emailLower, "@")
+	return found && utf8.RuneCountInString(local) >= 3 && strings.Contains(passwordLower, local)
+}
@@ -0,0 +1,91 @@
+package passwordpolicy
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var violationErr *ViolationError
+	if !errors.As(err, &violationErr) {
+		t.Fatalf("error %v is not a *ViolationError", err)
+	}
+
+	codes := make([]string, len(violationErr.Violations))
+	for i, v := range violationErr.Violations {
+		if v.Message == "" || !strings.Contains(err.Error(), v.Message) {
+			t.Fatalf("violation %s: message %q is missing from %q", v.Code, v.Message, err.Error())
+		}
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPolicyValidate(t *testing.T) {
+	list, err := LoadBreachedList(writeBreachedList(t, sha1Hex("Zy8&wKn3%Sx0")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict := New(Config{
+		MinLength:      8,
+		MaxLength:      64,
+		RequireLower:   true,
+		RequireUpper:   true,
+		RequireDigit:   true,
+		RequireSymbol:  true,
+		MinStrength:    3,
+		ForbidIdentity: true,
+	}, list)
+	email := "petr.sidorov@mail.ru"
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		password string
+		username string
+		email    *string
+		want     []string
+	}{
+		{name: "valid", policy: strict, password: "Tq7#vLm2$Rz9", username: "ivanov", email: &email},
+		{name: "too short", policy: strict, password: "Tq7#vL", want: []string{CodeTooShort}},
+		{name: "too long", policy: strict, password: strings.Repeat("Tq7#vLm2$Rz9", 6), want: []string{CodeTooLong}},
+		{name: "length counted in characters", policy: strict, password: "Пq7#вЛм2", want: nil},
+		{name: "missing lowercase", policy: strict, password: "TQ7#VLM2$RZ9", want: []string{CodeMissingLower}},
+		{name: "missing uppercase", policy: strict, password: "tq7#vlm2$rz9", want: []string{CodeMissingUpper}},
+		{name: "missing digit", policy: strict, password: "Tqx#vLmw$Rzk", want: []string{CodeMissingDigit}},
+		{name: "missing symbol", policy: strict, password: "Tq7xvLm2wRz9", want: []string{CodeMissingSymbol}},
+		{name: "contains username", policy: strict, password: "IVANOV#2024xQ", username: "ivanov", want: []string{CodeContainsUsername}},
+		{name: "contains email", policy: strict, password: "Xq#7Petr.Sidorov@mail.ru", email: &email, want: []string{CodeContainsEmail}},
+		{name: "contains email local part", policy: strict, password: "Xq#7petr.sidorovZ", email: &email, want: []string{CodeContainsEmail}},
+		{name: "too weak", policy: strict, password: "Password1!", want: []string{CodeTooWeak}},
+		{name: "breached", policy: strict, password: "Zy8&wKn3%Sx0", want: []string{CodeBreached}},
+		{
+			name:     "all violations reported",
+			policy:   strict,
+			password: "abc",
+			want:     []string{CodeTooShort, CodeMissingUpper, CodeMissingDigit, CodeMissingSymbol, CodeTooWeak},
+		},
+		{
+			name:     "identity allowed when not forbidden",
+			policy:   New(Config{MinLength: 8}, nil),
+			password: "ivanov-password",
+			username: "ivanov",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violationCodes(t, tt.policy.Validate(tt.password, tt.username, tt.email))
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package passwordpolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+type Strength struct {
+	Score        int     `json:"score"`
+	GuessesLog10 float64 `json:"guesses_log10"`
+}
+
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234",
+	"111111", "1234567", "dragon", "123123", "baseball", "abc123", "football",
+	"monkey", "letmein", "696969", "shadow", "master", "666666", "qwertyuiop",
+	"123321", "mustang", "1234567890", "michael", "654321", "superman", "1qaz2wsx",
+	"7777777", "121212", "000000", "qazwsx", "123qwe", "killer", "trustno1",
+	"jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter", "buster", "soccer",
+	"harley", "batman", "andrew", "tigger", "sunshine", "iloveyou", "charlie",
+	"robert", "thomas", "hockey", "ranger", "daniel", "starwars", "klaster",
+	"112233", "george", "computer", "michelle", "jessica", "pepper", "zaq1zaq1",
+	"admin", "welcome", "login", "passw0rd", "secret", "princess", "qwerty123",
+	"parol", "privet", "natasha", "marina", "svetlana", "sergey", "maksim",
+	"dmitriy", "alexander", "anastasia", "lovely", "samsung", "pokemon",
+}
+
+var keyboardRows = []string{
+	"1234567890-=",
+	"qwertyuiop[]",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"йцукенгшщзхъ",
+	"фывапролджэ",
+	"ячсмитьбю",
+}
+
+var leetSubstitutions = map[rune]rune{
+	'@': 'a', '4': 'a', '0': 'o', '1': 'i', '!': 'i', '3': 'e',
+	'$': 's', '5': 's', '7': 't', '+': 't', '8': 'b',
+}
+
+var dictionaryRanks = func() map[string]int {
+	ranks := make(map[string]int, len(commonPasswords))
+	for i, p := range commonPasswords {
+		ranks[p] = i + 1
+	}
+	return ranks
+}()
+
+// EstimateStrength оценивает стойкость пароля по аналогии с zxcvbn: пароль
+// разбивается на угадываемые фрагменты (словарные слова, повторы,
+// последовательности, раскладка клавиатуры), остаток считается перебором.
+// Score от 0 (очень слабый) до 4 (стойкий).
+func EstimateStrength(password string, userInputs ...string) Strength {
+	runes := []rune(strings.ToLower(password))
+	if len(runes) == 0 {
+		return Strength{}
+	}
+
+	dictionary := make(map[string]int, len(dictionaryRanks)+len(userInputs))
+	for word, rank := range dictionaryRanks {
+		dictionary[word] = rank
+	}
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		if len([]rune(input)) >= 3 {
+			dictionary[input] = 1
+		}
+		if local, _, found := strings.Cut(input, "@"); found && len([]rune(local)) >= 3 {
+			dictionary[local] = 1
+		}
+	}
+
+	unleeted := make([]rune, len(runes))
+	for i, r := range runes {
+		if sub, ok := leetSubstitutions[r]; ok {
+			unleeted[i] = sub
+		} else {
+			unleeted[i] = r
+		}
+	}
+
+	cardinality := float64(bruteforceCardinality(password))
+	log10Guesses := 0.0
+
+	for i := 0; i < len(runes); {
+		length, guesses := bestMatch(runes, unleeted, i, dictionary)
+		if length == 0 {
+			log10Guesses += math.Log10(cardinality)
+			i++
+			continue
+		}
+		log10Guesses += math.Log10(guesses)
+		i += length
+	}
+
+	return Strength{
+		Score:        scoreFromGuesses(log10Guesses),
+		GuessesLog10: log10Guesses,
+	}
+}
+
+func bestMatch(runes, unleeted []rune, start int, dictionary map[string]int) (int, float64) {
+	bestLen := 0
+	bestGuesses := 0.0
+
+	consider := func(length int, guesses float64) {
+		if length > bestLen || (length == bestLen && guesses < bestGuesses) {
+			bestLen = length
+			bestGuesses = guesses
+		}
+	}
+
+	for end := len(runes); end-start >= 3; end-- {
+		if rank, ok := dictionary[string(runes[start:end])]; ok {
+			consider(end-start, float64(rank)*2)
+			break
+		}
+		if rank, ok := dictionary[string(unleeted[start:end])]; ok {
+			consider(end-start, float64(rank)*4)
+			break
+		}
+	}
+
+	if n := repeatLength(runes, start); n >= 3 {
+		consider(n, 10*float64(n))
+	}
+
+	if n := sequenceLength(runes, start); n >= 3 {
+		consider(n, 4*float64(n))
+	}
+
+	if n := keyboardLength(runes, start); n >= 4 {
+		consider(n, 12*float64(n))
+	}
+
+	return bestLen, bestGuesses
+}
+
+func repeatLength(runes []rune, start int) int {
+	n := 1
+	for start+n < len(runes) && runes[start+n] == runes[start] {
+		n++
+	}
+	return n
+}
+
+func sequenceLength(runes []rune, start int) int {
+	if start+1 >= len(runes) {
+		return 1
+	}
+	delta := runes[start+1] - runes[start]
+	if delta != 1 && delta != -1 {
+		return 1
+	}
+	n := 2
+	for start+n < len(runes) && runes[start+n]-runes[start+n-1] == delta {
+		n++
+	}
+	return n
+}
+
+func keyboardLength(runes []rune, start int) int {
+	best := 1
+	for _, row := range keyboardRows {
+		rowRunes := []rune(row)
+		for offset := range rowRunes {
+			if rowRunes[offset] != runes[start] {
+				continue
+			}
+			n := 1
+			for start+n < len(runes) && offset+n < len(rowRunes) && rowRunes[offset+n] == runes[start+n] {
+				n++
+			}
+			if n > best {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+func bruteforceCardinality(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case r < 128:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	cardinality := 0
+	if lower {
+		cardinality += 26
+	}
+	if upper {
+		cardinality += 26
+	}
+	if digit {
+		cardinality += 10
+	}
+	if symbol {
+		cardinality += 33
+	}
+	if other {
+		cardinality += 66
+	}
+	return cardinality
+}
+
+func scoreFromGuesses(log10Guesses float64) int {
+	switch {
+	case log10Guesses < 3:
+		return 0
+	case log10Guesses < 6:
+		return 1
+	case log10Guesses < 8:
+		return 2
+	case log10Guesses < 10:
+		return 3
+	}
+	return 4
+}
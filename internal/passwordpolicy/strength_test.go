@@ -0,0 +1,60 @@
+package passwordpolicy
+
+import "testing"
+
+func TestScoreFromGuesses(t *testing.T) {
+	tests := []struct {
+		log10Guesses float64
+		want         int
+	}{
+		{log10Guesses: 0, want: 0},
+		{log10Guesses: 2.99, want: 0},
+		{log10Guesses: 3, want: 1},
+		{log10Guesses: 5.99, want: 1},
+		{log10Guesses: 6, want: 2},
+		{log10Guesses: 7.99, want: 2},
+		{log10Guesses: 8, want: 3},
+		{log10Guesses: 9.99, want: 3},
+		{log10Guesses: 10, want: 4},
+		{log10Guesses: 40, want: 4},
+	}
+
+	for _, tt := range tests {
+		if got := scoreFromGuesses(tt.log10Guesses); got != tt.want {
+			t.Errorf("scoreFromGuesses(%v) = %d, want %d", tt.log10Guesses, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		want       int
+	}{
+		{name: "empty", password: "", want: 0},
+		{name: "common password", password: "password", want: 0},
+		{name: "common password in upper case", password: "PASSWORD", want: 0},
+		{name: "leet substitutions", password: "p@ssw0rd", want: 0},
+		{name: "repeated character", password: "aaaaaaaaaa", want: 0},
+		{name: "sequence", password: "abcdefgh", want: 0},
+		{name: "keyboard row", password: "asdfghjkl", want: 0},
+		{name: "russian keyboard row", password: "йцукенгшщ", want: 0},
+		{name: "username", password: "ivanpetrov", userInputs: []string{"ivanpetrov"}, want: 0},
+		{name: "email local part", password: "ivan.petrov", userInputs: []string{"ivan.petrov@mail.ru"}, want: 0},
+		{name: "three random characters", password: "kx9", want: 1},
+		{name: "four random characters", password: "kx9q", want: 2},
+		{name: "five random characters", password: "kx9q#", want: 3},
+		{name: "long random password", password: "Tq7#vLm2$Rz9", want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateStrength(tt.password, tt.userInputs...)
+			if got.Score != tt.want {
+				t.Fatalf("score = %d (log10 guesses %.2f), want %d", got.Score, got.GuessesLog10, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return count, nil
+}
+
+func (r *apiKeyRepository) Delete(ctx context.Context, id, userID uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetActiveByHash находит ключ по хэшу. Ключи заблокированных пользователей
+// и аккаунтов, ожидающих удаления, не действуют.
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if hash == "" {
+		return nil, gorm.ErrInvalidData
+	}
+
+	var key models.APIKey
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = api_keys.user_id").
+		Where("api_keys.key_hash = ? AND users.is_active AND users.deletion_scheduled_at IS NULL", hash).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+	return nil
+}
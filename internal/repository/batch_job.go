@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type batchJobRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchJobRepository(db *gorm.DB) BatchJobRepository {
+	return &batchJobRepository{db: db}
+}
+
+func (r *batchJobRepository) Create(ctx context.Context, job *models.BatchJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create batch job: %w", err)
+	}
+	return nil
+}
+
+// Update сохраняет статус задачи. Счётчики прогресса меняет только
+// SaveChunk, поэтому они здесь не перезаписываются.
+func (r *batchJobRepository) Update(ctx context.Context, job *models.BatchJob) error {
+	err := r.db.WithContext(ctx).Model(job).
+		Select("status", "error", "started_at", "finished_at").
+		Updates(job).Error
+	if err != nil {
+		return fmt.Errorf("failed to update batch job: %w", err)
+	}
+	return nil
+}
+
+func (r *batchJobRepository) GetByID(ctx context.Context, id uint) (*models.BatchJob, error) {
+	var job models.BatchJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SaveChunk сохраняет завершённую часть пакета одной транзакцией: проверки
+// с деталями, результаты по каждому тексту и прогресс задачи. checks[i] и
+// details[i] относятся к items[i]; для неудавшихся текстов checks[i] = nil.
+func (r *batchJobRepository) SaveChunk(ctx context.Context, jobID uint, items []*models.BatchItem, checks []*models.Check, details [][]*models.CheckDetail) error {
+	processed, failed, degraded := 0, 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case models.BatchItemFailed:
+			failed++
+		case models.BatchItemDegraded:
+			degraded++
+			processed++
+		default:
+			processed++
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Счётчики обновляются первыми: блокировка строки задачи держится до
+		// конца транзакции, поэтому части одного пакета получают номера
+		// результатов и становятся видны строго по очереди. Части
+		// завершаются параллельно, и счётчики увеличиваются в SQL, а не
+		// перезаписываются
+		var saved uint
+		result := tx.Raw(`
+			UPDATE batch_jobs
+			SET processed = processed + ?, failed = failed + ?, degraded = degraded + ?, updated_at = ?
+			WHERE id = ?
+			RETURNING processed + failed`,
+			processed, failed, degraded, time.Now(), jobID,
+		).Scan(&saved)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		seq := saved - uint(len(items))
+
+		var newChecks []*models.Check
+		for _, check := range checks {
+			if check != nil {
+				newChecks = append(newChecks, check)
+			}
+		}
+		if len(newChecks) > 0 {
+			if err := tx.CreateInBatches(newChecks, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		var newDetails []*models.CheckDetail
+		for i, item := range items {
+			seq++
+			item.BatchID = jobID
+			item.Seq = seq
+
+			if i >= len(checks) || checks[i] == nil {
+				continue
+			}
+			item.CheckID = &checks[i].ID
+			for _, detail := range details[i] {
+				detail.CheckID = checks[i].ID
+				newDetails = append(newDetails, detail)
+			}
+		}
+
+		if len(newDetails) > 0 {
+			if err := tx.CreateInBatches(newDetails, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.CreateInBatches(items, 500).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save batch chunk: %w", err)
+	}
+	return nil
+}
+
+// ListItems возвращает результаты пакета с номером больше afterSeq в
+// порядке записи.
+func (r *batchJobRepository) ListItems(ctx context.Context, jobID uint, afterSeq uint, limit int) ([]models.BatchItem, error) {
+	var items []models.BatchItem
+	err := r.db.WithContext(ctx).
+		Where("batch_id = ? AND seq > ?", jobID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch items: %w", err)
+	}
+	return items, nil
+}
+
+// FailInterrupted помечает пакеты, прерванные перезапуском сервера: тексты
+// необработанных частей хранятся только в памяти процесса.
+func (r *batchJobRepository) FailInterrupted(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.BatchJob{}).
+		Where("status IN ?", []string{models.BatchPending, models.BatchRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.BatchFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"scam-detection-backend/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type pauseKey struct{}
+
+// chunkPause останавливает SaveChunk после записи результатов части, до
+// фиксации транзакции.
+type chunkPause struct {
+	reached chan struct{}
+	release chan struct{}
+}
+
+func pauseAfterItems(t *testing.T, db *gorm.DB) {
+	err := db.Callback().Create().After("gorm:create").Register("test:pause_after_items", func(tx *gorm.DB) {
+		pause, ok := tx.Statement.Context.Value(pauseKey{}).(*chunkPause)
+		if ok && tx.Statement.Table == "batch_items" {
+			close(pause.reached)
+			<-pause.release
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func batchChunk(indexes ...int) []*models.BatchItem {
+	items := make([]*models.BatchItem, len(indexes))
+	for i, index := range indexes {
+		items[i] = &models.BatchItem{Index: index, Status: models.BatchItemFailed, ErrorCode: models.BatchErrorML}
+	}
+	return items
+}
+
+// Части пакета фиксируются в произвольном порядке; курсор по seq не должен
+// пропускать результаты части, которая начала запись раньше, а
+// зафиксировалась позже другой.
+func TestBatchItemsInterleavedChunks(t *testing.T) {
+	db := newTestDB(t)
+	pauseAfterItems(t, db)
+	repo := NewBatchJobRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "batch-owner")
+	job := &models.BatchJob{UserID: user.ID, Status: models.BatchRunning, Total: 4}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []models.BatchItem
+	var cursor uint
+	read := func() {
+		items, err := repo.ListItems(ctx, job.ID, cursor, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			seen = append(seen, item)
+			cursor = item.Seq
+		}
+	}
+
+	pause := &chunkPause{reached: make(chan struct{}), release: make(chan struct{})}
+	first := make(chan error, 1)
+	go func() {
+		first <- repo.SaveChunk(context.WithValue(ctx, pauseKey{}, pause), job.ID, batchChunk(0, 1), nil, nil)
+	}()
+	<-pause.reached
+
+	// Первая часть записала результаты, но не зафиксирована; вторая
+	// начинается позже и не должна стать видна раньше неё
+	second := make(chan error, 1)
+	go func() {
+		second <- repo.SaveChunk(ctx, job.ID, batchChunk(2, 3), nil, nil)
+	}()
+	time.Sleep(200 * time.Millisecond)
+	read()
+
+	close(pause.release)
+	for _, done := range []chan error{first, second} {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+	read()
+
+	if len(seen) != 4 {
+		t.Fatalf("cursor returned %d of 4 items: %+v", len(seen), seen)
+	}
+	indexes := make(map[int]bool)
+	for i, item := range seen {
+		if item.Seq != uint(i+1) {
+			t.Fatalf("item %d has seq %d, want %d", i, item.Seq, i+1)
+		}
+		indexes[item.Index] = true
+	}
+	if len(indexes) != 4 {
+		t.Fatalf("duplicate items returned: %+v", seen)
+	}
+
+	stored, err := repo.GetByID(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Failed != 4 || stored.Processed != 0 {
+		t.Fatalf("job counters: processed %d, failed %d", stored.Processed, stored.Failed)
+	}
+}
+
+func TestSaveChunkUnknownJob(t *testing.T) {
+	repo := NewBatchJobRepository(newTestDB(t))
+
+	if err := repo.SaveChunk(context.Background(), 404, batchChunk(0), nil, nil); err == nil {
+		t.Fatal("chunk saved for a missing job")
+	}
+}
@@ -1,9 +1,15 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"scam-detection-backend/internal/models"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type checkRepository struct {
@@ -26,23 +32,130 @@ func (r *checkRepository) GetCheckByID(id uint) (*models.Check, error) {
 	return &check, nil
 }
 
-func (r *checkRepository) GetChecksByUserID(userID uint, limit, offset int) ([]models.Check, int64, error) {
+// searchQuery - запрос полнотекстового поиска в русской и английской
+// конфигурациях; search_vector проверки строится по обеим. Строка поиска
+// передаётся дважды.
+const searchQuery = "websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?)"
+
+// ListChecks возвращает страницу проверок пользователя по фильтру. При
+// поиске в Snippet попадает фрагмент текста с найденными словами: текст
+// проверки экранируется до выделения, поэтому фрагмент безопасен для вставки
+// в HTML. Курсоры page.After и page.Before применимы только к сортировке по
+// created_at.
+func (r *checkRepository) ListChecks(ctx context.Context, userID uint, filter models.CheckHistoryFilter, page models.CheckPage) (*models.CheckList, error) {
+	query := r.db.WithContext(ctx).Model(&models.Check{}).Where("user_id = ?", userID)
+
+	if len(filter.DangerLevels) > 0 {
+		query = query.Where("danger_level IN ?", filter.DangerLevels)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.ContentType != "" {
+		query = query.Where("content_type = ?", filter.ContentType)
+	}
+	if filter.MinScore != nil {
+		query = query.Where("danger_score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("danger_score <= ?", *filter.MaxScore)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.HasFeedback != nil {
+		if *filter.HasFeedback {
+			query = query.Where("user_verdict IS NOT NULL")
+		} else {
+			query = query.Where("user_verdict IS NULL")
+		}
+	}
+	if filter.Query != "" {
+		query = query.Where("search_vector @@ ("+searchQuery+")", filter.Query, filter.Query)
+	}
+
+	list := &models.CheckList{}
+	if !page.SkipTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		list.Total = &total
+	}
+
+	if filter.Query != "" {
+		query = query.Select("checks.*, ts_headline('russian', "+
+			"replace(replace(replace(content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), "+
+			"("+searchQuery+"), "+
+			"'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \"') AS snippet",
+			filter.Query, filter.Query)
+	}
+
+	// Назад по курсору выбираем в обратном порядке и разворачиваем результат
+	backward := page.Before != nil
+	order := filter
+	order.Asc = filter.Asc != backward
+	if cursor := page.After; cursor != nil || backward {
+		if backward {
+			cursor = page.Before
+		}
+		op := "<"
+		if order.Asc {
+			op = ">"
+		}
+		query = query.Where("(created_at, id) "+op+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	} else {
+		query = query.Offset(page.Offset)
+	}
+
+	// Лишняя строка показывает, есть ли проверки за страницей
 	var checks []models.Check
-	var total int64
+	err := query.Order(historyOrder(order)).
+		Limit(page.Limit + 1).
+		Find(&checks).Error
+	if err != nil {
+		return nil, err
+	}
 
-	if err := r.db.Model(&models.Check{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if len(checks) > page.Limit {
+		checks = checks[:page.Limit]
+		list.HasMore = true
+	}
+	if backward {
+		slices.Reverse(checks)
 	}
+	list.Checks = checks
 
-	if err := r.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&checks).Error; err != nil {
-		return nil, 0, err
+	return list, nil
+}
+
+// historyOrder - сортировка истории; при равенстве ключа порядок
+// определяется id, чтобы страницы не пересекались.
+func historyOrder(filter models.CheckHistoryFilter) clause.OrderBy {
+	direction := func(column clause.Column) []clause.OrderByColumn {
+		return []clause.OrderByColumn{
+			{Column: column, Desc: !filter.Asc},
+			{Column: clause.Column{Name: "id"}, Desc: !filter.Asc},
+		}
 	}
 
-	return checks, total, nil
+	switch filter.Sort {
+	case models.HistorySortDangerScore:
+		return clause.OrderBy{Columns: direction(clause.Column{Name: "danger_score"})}
+	case models.HistorySortProcessingTime:
+		return clause.OrderBy{Columns: direction(clause.Column{Name: "processing_time"})}
+	case models.HistorySortRelevance:
+		return clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(search_vector, " + searchQuery + ") DESC, id DESC",
+			Vars:               []interface{}{filter.Query, filter.Query},
+			WithoutParentheses: true,
+		}}
+	default:
+		return clause.OrderBy{Columns: direction(clause.Column{Name: "created_at"})}
+	}
 }
 
 func (r *checkRepository) UpdateCheckStatus(id uint, status string, dangerScore float64, dangerLevel string, processingTime int) error {
@@ -56,70 +169,435 @@ func (r *checkRepository) UpdateCheckStatus(id uint, status string, dangerScore
 		}).Error
 }
 
-func (r *checkRepository) AddCheckDetail(detail *models.CheckDetail) error {
-	return r.db.Create(detail).Error
+// CompleteCheck сохраняет оценку проверки вместе с её деталями в одной
+// транзакции. Вердикт только по правилам (check.Degraded) ставит проверку в
+// очередь на повторную оценку ML моделью.
+func (r *checkRepository) CompleteCheck(ctx context.Context, check *models.Check, details []*models.CheckDetail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Check{}).
+			Where("id = ?", check.ID).
+			Updates(map[string]interface{}{
+				"status":          "completed",
+				"degraded":        check.Degraded,
+				"danger_score":    check.DangerScore,
+				"danger_level":    check.DangerLevel,
+				"processing_time": check.ProcessingTime,
+				"model_name":      check.ModelName,
+				"model_version":   check.ModelVersion,
+			}).Error; err != nil {
+			return err
+		}
+
+		if len(details) == 0 {
+			return nil
+		}
+		return tx.Create(details).Error
+	})
 }
 
-func (r *checkRepository) GetCheckDetails(checkID uint) ([]models.CheckDetail, error) {
-	var details []models.CheckDetail
-	if err := r.db.Where("check_id = ?", checkID).Find(&details).Error; err != nil {
-		return nil, err
+func (r *checkRepository) SetUserVerdict(ctx context.Context, id, userID uint, isScam bool) error {
+	result := r.db.WithContext(ctx).Model(&models.Check{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("user_verdict", isScam)
+	if result.Error != nil {
+		return result.Error
 	}
-	return details, nil
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
-func (r *checkRepository) DeleteCheck(id uint, userID uint) error {
-	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Check{}).Error
+func (r *checkRepository) ListDegradedChecks(ctx context.Context, limit int) ([]models.Check, error) {
+	var checks []models.Check
+	err := r.db.WithContext(ctx).
+		Where("degraded = ?", true).
+		Order("id ASC").
+		Limit(limit).
+		Find(&checks).Error
+	return checks, err
+}
+
+// ApplyRescore заменяет деградированный вердикт результатом ML модели.
+// Условие degraded = true защищает от двойного применения, если проверку
+// параллельно пересчитал другой экземпляр сервиса.
+func (r *checkRepository) ApplyRescore(ctx context.Context, id uint, dangerScore float64, dangerLevel, modelName, modelVersion string, details []*models.CheckDetail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Check{}).
+			Where("id = ? AND degraded = ?", id, true).
+			Updates(map[string]interface{}{
+				"degraded":      false,
+				"danger_score":  dangerScore,
+				"danger_level":  dangerLevel,
+				"model_name":    modelName,
+				"model_version": modelVersion,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Create(details).Error
+	})
 }
 
-func (r *checkRepository) GetUserStats(userID uint) (map[string]interface{}, error) {
+func (r *checkRepository) reanalysisQuery(ctx context.Context, filter models.CheckFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Check{}).Where("status = ?", "completed")
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.DangerLevel != "" {
+		query = query.Where("danger_level = ?", filter.DangerLevel)
+	}
+	if filter.ModelVersion != "" {
+		query = query.Where("model_version = ?", filter.ModelVersion)
+	}
+	if filter.ExcludeModelVersion != "" {
+		query = query.Where("model_version <> ?", filter.ExcludeModelVersion)
+	}
+
+	return query
+}
+
+func (r *checkRepository) CountForReanalysis(ctx context.Context, filter models.CheckFilter) (int64, error) {
 	var total int64
+	err := r.reanalysisQuery(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+// ListForReanalysis возвращает следующую порцию проверок после afterID
+// (keyset по id), чтобы новые проверки не сдвигали уже пройденные.
+func (r *checkRepository) ListForReanalysis(ctx context.Context, filter models.CheckFilter, afterID uint, limit int) ([]models.Check, error) {
 	var checks []models.Check
+	err := r.reanalysisQuery(ctx, filter).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&checks).Error
+	return checks, err
+}
+
+// ApplyRevision делает revision текущей оценкой проверки. Прежняя оценка
+// сохраняется в check_revisions (для проверок, у которых ещё нет истории,
+// она записывается перед новой).
+func (r *checkRepository) ApplyRevision(ctx context.Context, revision *models.CheckRevision, details []*models.CheckDetail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var check models.Check
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&check, revision.CheckID).Error; err != nil {
+			return err
+		}
 
-	if err := r.db.Model(&models.Check{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		var existing int64
+		if err := tx.Model(&models.CheckRevision{}).Where("check_id = ?", check.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 {
+			initial := &models.CheckRevision{
+				CheckID:      check.ID,
+				Revision:     check.Revision,
+				DangerScore:  check.DangerScore,
+				DangerLevel:  check.DangerLevel,
+				ModelName:    check.ModelName,
+				ModelVersion: check.ModelVersion,
+				Source:       models.RevisionSourceAnalysis,
+				CreatedAt:    check.CreatedAt,
+			}
+			if err := tx.Create(initial).Error; err != nil {
+				return err
+			}
+		}
+
+		revision.Revision = check.Revision + 1
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&check).Updates(map[string]interface{}{
+			"danger_score":  revision.DangerScore,
+			"danger_level":  revision.DangerLevel,
+			"model_name":    revision.ModelName,
+			"model_version": revision.ModelVersion,
+			"revision":      revision.Revision,
+			"degraded":      false,
+		}).Error; err != nil {
+			return err
+		}
+
+		if len(details) == 0 {
+			return nil
+		}
+		for _, detail := range details {
+			detail.CheckID = check.ID
+			detail.Revision = revision.Revision
+		}
+		return tx.Create(details).Error
+	})
+}
+
+func (r *checkRepository) ListRevisions(ctx context.Context, checkID, userID uint) ([]models.CheckRevision, error) {
+	var check models.Check
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", checkID, userID).First(&check).Error; err != nil {
 		return nil, err
 	}
 
-	if err := r.db.Where("user_id = ?", userID).Find(&checks).Error; err != nil {
+	var revisions []models.CheckRevision
+	if err := r.db.WithContext(ctx).
+		Where("check_id = ?", checkID).
+		Order("revision ASC").
+		Find(&revisions).Error; err != nil {
 		return nil, err
 	}
 
-	stats := map[string]interface{}{
-		"total_analyses":          total,
-		"safe_count":              0,
-		"suspicious_count":        0,
-		"dangerous_count":         0,
-		"average_risk_score":      0.0,
-		"average_processing_time": 0,
+	// Пока проверку не пересчитывали, её единственная ревизия - она сама
+	if len(revisions) == 0 {
+		revisions = append(revisions, models.CheckRevision{
+			CheckID:      check.ID,
+			Revision:     check.Revision,
+			DangerScore:  check.DangerScore,
+			DangerLevel:  check.DangerLevel,
+			ModelName:    check.ModelName,
+			ModelVersion: check.ModelVersion,
+			Source:       models.RevisionSourceAnalysis,
+			CreatedAt:    check.CreatedAt,
+		})
 	}
 
-	if total == 0 {
-		return stats, nil
+	return revisions, nil
+}
+
+func (r *checkRepository) GetCheckDetails(checkID uint) ([]models.CheckDetail, error) {
+	var details []models.CheckDetail
+	if err := r.db.Where("check_id = ?", checkID).Find(&details).Error; err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// DeleteCheck удаляет проверку пользователя вместе с деталями, ревизиями и
+// оценками моделей.
+func (r *checkRepository) DeleteCheck(ctx context.Context, id, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := tx.Model(&models.Check{}).Select("id").Where("id = ? AND user_id = ?", id, userID)
+		if err := tx.Where("check_id IN (?)", owned).Delete(&models.CheckDetail{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("check_id IN (?)", owned).Delete(&models.ModelEvaluation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("check_id IN (?)", owned).Delete(&models.CheckRevision{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Check{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetUserStats считает сводку одним запросом с группировкой по уровню
+// опасности. Средние берутся по всем проверкам, включая незавершённые, как
+// и раньше.
+func (r *checkRepository) GetUserStats(ctx context.Context, userID uint) (*models.UserStats, error) {
+	var levels []struct {
+		DangerLevel    string
+		Count          int64
+		DangerScore    float64
+		ProcessingTime int64
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COALESCE(danger_level, '') AS danger_level,
+		       COUNT(*) AS count,
+		       COALESCE(SUM(danger_score), 0) AS danger_score,
+		       COALESCE(SUM(processing_time), 0) AS processing_time
+		FROM checks
+		WHERE user_id = ?
+		GROUP BY 1`, userID,
+	).Scan(&levels).Error
+	if err != nil {
+		return nil, err
 	}
 
-	var safeCount, suspiciousCount, dangerousCount int
+	stats := &models.UserStats{ByLevel: make(map[string]int64)}
 	var totalRisk float64
-	var totalTime int
+	var totalTime int64
 
-	for _, check := range checks {
-		totalRisk += check.DangerScore
-		totalTime += check.ProcessingTime
+	for _, level := range levels {
+		stats.TotalAnalyses += level.Count
+		totalRisk += level.DangerScore
+		totalTime += level.ProcessingTime
 
-		switch check.DangerLevel {
+		switch level.DangerLevel {
 		case "low":
-			safeCount++
+			stats.SafeCount += level.Count
 		case "medium":
-			suspiciousCount++
+			stats.SuspiciousCount += level.Count
 		case "high", "critical":
-			dangerousCount++
+			stats.DangerousCount += level.Count
+		}
+		if level.DangerLevel != "" {
+			stats.ByLevel[level.DangerLevel] = level.Count
 		}
 	}
 
-	stats["safe_count"] = safeCount
-	stats["suspicious_count"] = suspiciousCount
-	stats["dangerous_count"] = dangerousCount
-	stats["average_risk_score"] = totalRisk / float64(total)
-	stats["average_processing_time"] = totalTime / int(total)
+	if stats.TotalAnalyses > 0 {
+		stats.AverageRiskScore = totalRisk / float64(stats.TotalAnalyses)
+		stats.AverageProcessingTime = totalTime / stats.TotalAnalyses
+	}
 
 	return stats, nil
 }
+
+// indicatorPattern выделяет домен из ссылки в тексте проверки
+const indicatorPattern = `https?://([^/\s?#:"'<>]+)`
+
+// bucketIntervals - шаг generate_series для размера интервала.
+var bucketIntervals = map[string]string{
+	models.StatsBucketHour: "1 hour",
+	models.StatsBucketDay:  "1 day",
+	models.StatsBucketWeek: "1 week",
+}
+
+// GetStatsTimeSeries группирует проверки по интервалам date_trunc в часовом
+// поясе запроса. Интервалы строятся в SQL по местному времени и
+// соединяются с проверками по нему же, поэтому при переходе с летнего
+// времени оба повторившихся часа попадают в один интервал, а не теряются.
+// Интервалы без проверок возвращаются с нулями, чтобы график был
+// непрерывным.
+func (r *checkRepository) GetStatsTimeSeries(ctx context.Context, userID uint, query models.StatsTimeSeriesQuery) (*models.StatsTimeSeries, error) {
+	loc, err := time.LoadLocation(query.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	interval, ok := bucketIntervals[query.Bucket]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats bucket %q", query.Bucket)
+	}
+	db := r.db.WithContext(ctx)
+
+	var rows []struct {
+		LocalStart     time.Time
+		Bucket         time.Time
+		DangerLevel    string
+		Count          int64
+		DangerScore    float64
+		ProcessingTime int64
+	}
+	err = db.Raw(`
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc(@bucket, CAST(@from AS timestamptz) AT TIME ZONE @tz),
+				CAST(@to AS timestamptz) AT TIME ZONE @tz,
+				CAST(@interval AS interval)
+			) AS local_start
+		), counts AS (
+			SELECT date_trunc(@bucket, created_at AT TIME ZONE @tz) AS local_start,
+			       COALESCE(danger_level, '') AS danger_level,
+			       COUNT(*) AS count,
+			       COALESCE(SUM(danger_score), 0) AS danger_score,
+			       COALESCE(SUM(processing_time), 0) AS processing_time
+			FROM checks
+			WHERE user_id = @user AND created_at >= @from AND created_at < @to
+			GROUP BY 1, 2
+		)
+		SELECT b.local_start, b.local_start AT TIME ZONE @tz AS bucket,
+		       COALESCE(c.danger_level, '') AS danger_level,
+		       COALESCE(c.count, 0) AS count,
+		       COALESCE(c.danger_score, 0) AS danger_score,
+		       COALESCE(c.processing_time, 0) AS processing_time
+		FROM buckets b
+		LEFT JOIN counts c ON c.local_start = b.local_start
+		WHERE b.local_start < CAST(@to AS timestamptz) AT TIME ZONE @tz
+		ORDER BY b.local_start, 3`,
+		sql.Named("bucket", query.Bucket),
+		sql.Named("tz", query.Timezone),
+		sql.Named("interval", interval),
+		sql.Named("user", userID),
+		sql.Named("from", query.From),
+		sql.Named("to", query.To),
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.StatsTimeSeries{
+		From:          query.From,
+		To:            query.To,
+		Bucket:        query.Bucket,
+		Timezone:      query.Timezone,
+		Buckets:       []models.StatsBucket{},
+		TopRules:      []models.StatsCount{},
+		TopIndicators: []models.StatsCount{},
+	}
+
+	// Строки одного интервала идут подряд, по одной на уровень опасности
+	var totalRisk []float64
+	var totalTime []int64
+	var localStart time.Time
+	for _, row := range rows {
+		if len(series.Buckets) == 0 || !row.LocalStart.Equal(localStart) {
+			localStart = row.LocalStart
+			series.Buckets = append(series.Buckets, models.StatsBucket{Start: row.Bucket.In(loc), ByLevel: make(map[string]int64)})
+			totalRisk = append(totalRisk, 0)
+			totalTime = append(totalTime, 0)
+		}
+
+		i := len(series.Buckets) - 1
+		series.Buckets[i].Total += row.Count
+		if row.DangerLevel != "" {
+			series.Buckets[i].ByLevel[row.DangerLevel] += row.Count
+		}
+		totalRisk[i] += row.DangerScore
+		totalTime[i] += row.ProcessingTime
+	}
+	for i := range series.Buckets {
+		if total := series.Buckets[i].Total; total > 0 {
+			series.Buckets[i].AverageRiskScore = totalRisk[i] / float64(total)
+			series.Buckets[i].AverageProcessingTime = float64(totalTime[i]) / float64(total)
+		}
+	}
+
+	err = db.Raw(`
+		SELECT d.feature_value AS value, COUNT(DISTINCT d.check_id) AS count
+		FROM check_details d
+		JOIN checks c ON c.id = d.check_id
+		WHERE d.feature_name = ? AND c.user_id = ? AND c.created_at >= ? AND c.created_at < ?
+		GROUP BY 1
+		ORDER BY count DESC, value
+		LIMIT ?`,
+		models.FeatureRuleMatch, userID, query.From, query.To, query.Top,
+	).Scan(&series.TopRules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Raw(`
+		SELECT lower(m[1]) AS value, COUNT(DISTINCT c.id) AS count
+		FROM checks c
+		CROSS JOIN LATERAL regexp_matches(c.content, ?, 'gi') AS m
+		WHERE c.user_id = ? AND c.created_at >= ? AND c.created_at < ?
+		  AND c.danger_level IN ('high', 'critical')
+		GROUP BY 1
+		ORDER BY count DESC, value
+		LIMIT ?`,
+		indicatorPattern, userID, query.From, query.To, query.Top,
+	).Scan(&series.TopIndicators).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
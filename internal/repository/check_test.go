@@ -0,0 +1,703 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"scam-detection-backend/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// loadUserStats - прежняя реализация GetUserStats: загружает все проверки
+// пользователя и считает сводку в Go. Эталон для сравнения и бенчмарка.
+func loadUserStats(db *gorm.DB, userID uint) (*models.UserStats, error) {
+	var checks []models.Check
+	if err := db.Where("user_id = ?", userID).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &models.UserStats{TotalAnalyses: int64(len(checks)), ByLevel: make(map[string]int64)}
+	if len(checks) == 0 {
+		return stats, nil
+	}
+
+	var totalRisk float64
+	var totalTime int64
+	for _, check := range checks {
+		totalRisk += check.DangerScore
+		totalTime += int64(check.ProcessingTime)
+
+		switch check.DangerLevel {
+		case "low":
+			stats.SafeCount++
+		case "medium":
+			stats.SuspiciousCount++
+		case "high", "critical":
+			stats.DangerousCount++
+		}
+		if check.DangerLevel != "" {
+			stats.ByLevel[check.DangerLevel]++
+		}
+	}
+	stats.AverageRiskScore = totalRisk / float64(stats.TotalAnalyses)
+	stats.AverageProcessingTime = totalTime / stats.TotalAnalyses
+
+	return stats, nil
+}
+
+type seededCheck struct {
+	level string
+	score float64
+	time  int
+}
+
+func seedChecks(t testing.TB, db *gorm.DB, userID uint, seeds []seededCheck) {
+	t.Helper()
+
+	checks := make([]*models.Check, len(seeds))
+	for i, seed := range seeds {
+		checks[i] = &models.Check{
+			Title:          fmt.Sprintf("check %d", i),
+			ContentType:    "text",
+			Content:        "текст проверки",
+			Status:         "completed",
+			DangerLevel:    seed.level,
+			DangerScore:    seed.score,
+			ProcessingTime: seed.time,
+			UserID:         userID,
+		}
+		if seed.level == "" {
+			checks[i].Status = "processing"
+		}
+	}
+	if err := db.CreateInBatches(checks, 500).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetUserStats(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+
+	other := createTestUser(t, db, "other")
+	seedChecks(t, db, other.ID, []seededCheck{{level: "critical", score: 0.99, time: 1000}})
+
+	tests := []struct {
+		name  string
+		seeds []seededCheck
+		want  models.UserStats
+	}{
+		{name: "no checks", want: models.UserStats{ByLevel: map[string]int64{}}},
+		{
+			name: "all levels",
+			seeds: []seededCheck{
+				{level: "low", score: 0.1, time: 100},
+				{level: "low", score: 0.2, time: 120},
+				{level: "medium", score: 0.5, time: 90},
+				{level: "high", score: 0.8, time: 110},
+				{level: "critical", score: 0.95, time: 130},
+				// Проверка в обработке входит в итог, но не в уровни
+				{time: 0},
+			},
+			want: models.UserStats{
+				TotalAnalyses:         6,
+				SafeCount:             2,
+				SuspiciousCount:       1,
+				DangerousCount:        2,
+				AverageRiskScore:      2.55 / 6,
+				AverageProcessingTime: 91,
+				ByLevel:               map[string]int64{"low": 2, "medium": 1, "high": 1, "critical": 1},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, db, fmt.Sprintf("user%d", i))
+			seedChecks(t, db, user.ID, tt.seeds)
+
+			got, err := repo.GetUserStats(context.Background(), user.ID)
+			if err != nil {
+				t.Fatalf("GetUserStats: %v", err)
+			}
+			assertUserStats(t, got, &tt.want)
+
+			legacy, err := loadUserStats(db, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertUserStats(t, got, legacy)
+		})
+	}
+}
+
+func assertUserStats(t *testing.T, got, want *models.UserStats) {
+	t.Helper()
+
+	if got.TotalAnalyses != want.TotalAnalyses || got.SafeCount != want.SafeCount ||
+		got.SuspiciousCount != want.SuspiciousCount || got.DangerousCount != want.DangerousCount ||
+		got.AverageProcessingTime != want.AverageProcessingTime ||
+		math.Abs(got.AverageRiskScore-want.AverageRiskScore) > 1e-9 {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if len(got.ByLevel) != len(want.ByLevel) {
+		t.Fatalf("by level = %v, want %v", got.ByLevel, want.ByLevel)
+	}
+	for level, count := range want.ByLevel {
+		if got.ByLevel[level] != count {
+			t.Fatalf("by level = %v, want %v", got.ByLevel, want.ByLevel)
+		}
+	}
+}
+
+// BenchmarkGetUserStats сравнивает группировку в SQL с загрузкой всех
+// проверок пользователя.
+func BenchmarkGetUserStats(b *testing.B) {
+	db := newTestDB(b)
+	repo := NewCheckRepository(db)
+	user := createTestUser(b, db, "heavy")
+
+	levels := []string{"low", "medium", "high", "critical"}
+	seeds := make([]seededCheck, 20000)
+	for i := range seeds {
+		seeds[i] = seededCheck{level: levels[i%len(levels)], score: float64(i%100) / 100, time: 50 + i%200}
+	}
+	seedChecks(b, db, user.ID, seeds)
+
+	b.Run("grouped", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.GetUserStats(context.Background(), user.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("load all", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := loadUserStats(db, user.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+type timedCheck struct {
+	at      string
+	level   string
+	score   float64
+	time    int
+	content string
+	rules   []string
+}
+
+// seedTimedChecks создаёт проверки с заданным временем создания (RFC3339)
+// и сработавшими правилами.
+func seedTimedChecks(t *testing.T, db *gorm.DB, userID uint, seeds []timedCheck) {
+	t.Helper()
+
+	for i, seed := range seeds {
+		createdAt, err := time.Parse(time.RFC3339, seed.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check := &models.Check{
+			Title:          fmt.Sprintf("check %d", i),
+			ContentType:    "text",
+			Content:        seed.content,
+			Status:         "completed",
+			DangerLevel:    seed.level,
+			DangerScore:    seed.score,
+			ProcessingTime: seed.time,
+			UserID:         userID,
+			CreatedAt:      createdAt,
+		}
+		if err := db.Create(check).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, rule := range seed.rules {
+			detail := &models.CheckDetail{CheckID: check.ID, FeatureName: models.FeatureRuleMatch, FeatureValue: rule}
+			if err := db.Create(detail).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestGetStatsTimeSeriesEmptyBuckets(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "series")
+	other := createTestUser(t, db, "other")
+
+	seedTimedChecks(t, db, user.ID, []timedCheck{
+		{at: "2026-03-01T08:00:00Z", level: "low", score: 0.1, time: 100},
+		{at: "2026-03-01T20:00:00Z", level: "high", score: 0.7, time: 300},
+		{at: "2026-03-03T12:00:00Z", level: "critical", score: 0.9, time: 50},
+		// За границами периода
+		{at: "2026-02-28T23:59:59Z", level: "low", score: 0.1},
+		{at: "2026-03-05T00:00:00Z", level: "low", score: 0.1},
+	})
+	seedTimedChecks(t, db, other.ID, []timedCheck{{at: "2026-03-02T12:00:00Z", level: "low", score: 0.1}})
+
+	series, err := repo.GetStatsTimeSeries(context.Background(), user.ID, models.StatsTimeSeriesQuery{
+		From:     mustTime(t, "2026-03-01T00:00:00Z"),
+		To:       mustTime(t, "2026-03-05T00:00:00Z"),
+		Bucket:   models.StatsBucketDay,
+		Timezone: "UTC",
+		Top:      10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		start  string
+		total  int64
+		risk   float64
+		time   float64
+		levels map[string]int64
+	}{
+		{start: "2026-03-01T00:00:00Z", total: 2, risk: 0.4, time: 200, levels: map[string]int64{"low": 1, "high": 1}},
+		{start: "2026-03-02T00:00:00Z", levels: map[string]int64{}},
+		{start: "2026-03-03T00:00:00Z", total: 1, risk: 0.9, time: 50, levels: map[string]int64{"critical": 1}},
+		{start: "2026-03-04T00:00:00Z", levels: map[string]int64{}},
+	}
+	if len(series.Buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d: %+v", len(series.Buckets), len(want), series.Buckets)
+	}
+	for i, w := range want {
+		got := series.Buckets[i]
+		if !got.Start.Equal(mustTime(t, w.start)) || got.Total != w.total ||
+			math.Abs(got.AverageRiskScore-w.risk) > 1e-9 || got.AverageProcessingTime != w.time || !maps.Equal(got.ByLevel, w.levels) {
+			t.Errorf("bucket %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestGetStatsTimeSeriesDaylightSavingFallBack(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "dst")
+
+	// 1 ноября 2026 в Нью-Йорке час 01:00-02:00 повторяется: 05:30Z и
+	// 06:30Z - оба 01:30 по местному времени
+	seedTimedChecks(t, db, user.ID, []timedCheck{
+		{at: "2026-11-01T04:30:00Z", level: "low"},
+		{at: "2026-11-01T05:30:00Z", level: "low"},
+		{at: "2026-11-01T06:30:00Z", level: "medium"},
+		{at: "2026-11-01T07:30:00Z", level: "high"},
+	})
+
+	series, err := repo.GetStatsTimeSeries(context.Background(), user.ID, models.StatsTimeSeriesQuery{
+		From:     mustTime(t, "2026-11-01T04:00:00Z"),
+		To:       mustTime(t, "2026-11-01T08:00:00Z"),
+		Bucket:   models.StatsBucketHour,
+		Timezone: "America/New_York",
+		Top:      10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var totals []int64
+	var hours []int
+	for _, bucket := range series.Buckets {
+		totals = append(totals, bucket.Total)
+		hours = append(hours, bucket.Start.Hour())
+		if bucket.Start.Location().String() != "America/New_York" {
+			t.Fatalf("bucket start %v is not in the requested timezone", bucket.Start)
+		}
+	}
+	if !slices.Equal(hours, []int{0, 1, 2}) || !slices.Equal(totals, []int64{1, 2, 1}) {
+		t.Fatalf("buckets: hours %v, totals %v; want [0 1 2] and [1 2 1]", hours, totals)
+	}
+}
+
+func TestGetStatsTimeSeriesTop(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "top")
+
+	seedTimedChecks(t, db, user.ID, []timedCheck{
+		{
+			at: "2026-03-01T10:00:00Z", level: "high", score: 0.8,
+			// Один домен дважды в проверке считается один раз
+			content: "Срочно: https://Evil.example/pay и http://evil.example/login",
+			rules:   []string{"срочно", "перейдите по ссылке"},
+		},
+		{
+			at: "2026-03-01T11:00:00Z", level: "critical", score: 0.95,
+			content: "Подтвердите https://evil.example/x и https://bank-secure.test:8443/a",
+			rules:   []string{"срочно"},
+		},
+		{
+			// Ссылки в безопасных проверках не индикаторы
+			at: "2026-03-01T12:00:00Z", level: "low", score: 0.1,
+			content: "Отчёт на https://docs.example/report",
+			rules:   []string{"срочно"},
+		},
+		{
+			at: "2026-03-09T12:00:00Z", level: "high", score: 0.8,
+			content: "За периодом https://late.example/",
+			rules:   []string{"поздно"},
+		},
+	})
+
+	series, err := repo.GetStatsTimeSeries(context.Background(), user.ID, models.StatsTimeSeriesQuery{
+		From:     mustTime(t, "2026-03-01T00:00:00Z"),
+		To:       mustTime(t, "2026-03-08T00:00:00Z"),
+		Bucket:   models.StatsBucketWeek,
+		Timezone: "UTC",
+		Top:      1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []models.StatsCount{{Value: "срочно", Count: 3}}; !slices.Equal(series.TopRules, want) {
+		t.Fatalf("top rules = %+v, want %+v", series.TopRules, want)
+	}
+	if want := []models.StatsCount{{Value: "evil.example", Count: 2}}; !slices.Equal(series.TopIndicators, want) {
+		t.Fatalf("top indicators = %+v, want %+v", series.TopIndicators, want)
+	}
+	// Неделя начинается с понедельника: 1 марта 2026 - воскресенье
+	if len(series.Buckets) != 2 || !series.Buckets[0].Start.Equal(mustTime(t, "2026-02-23T00:00:00Z")) || series.Buckets[0].Total != 3 {
+		t.Fatalf("week buckets = %+v", series.Buckets)
+	}
+
+	series, err = repo.GetStatsTimeSeries(context.Background(), user.ID, models.StatsTimeSeriesQuery{
+		From:     mustTime(t, "2026-03-01T00:00:00Z"),
+		To:       mustTime(t, "2026-03-08T00:00:00Z"),
+		Bucket:   models.StatsBucketWeek,
+		Timezone: "UTC",
+		Top:      10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIndicators := []models.StatsCount{{Value: "evil.example", Count: 2}, {Value: "bank-secure.test", Count: 1}}
+	if !slices.Equal(series.TopIndicators, wantIndicators) {
+		t.Fatalf("top indicators = %+v, want %+v", series.TopIndicators, wantIndicators)
+	}
+}
+
+// createHistory создаёт проверки истории в заданном порядке; ID
+// возрастают вместе с индексом.
+func createHistory(t *testing.T, db *gorm.DB, checks []*models.Check) {
+	t.Helper()
+
+	for _, check := range checks {
+		if check.Title == "" {
+			check.Title = "check"
+		}
+		if check.ContentType == "" {
+			check.ContentType = "text"
+		}
+		if check.Status == "" {
+			check.Status = "completed"
+		}
+		if err := db.Create(check).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkIDs(checks []models.Check) []uint {
+	ids := make([]uint, len(checks))
+	for i, check := range checks {
+		ids[i] = check.ID
+	}
+	return ids
+}
+
+func TestListChecksFilters(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "history")
+	other := createTestUser(t, db, "other")
+
+	day := func(n int) time.Time { return time.Date(2026, 3, n, 12, 0, 0, 0, time.UTC) }
+	scam := true
+	checks := []*models.Check{
+		{UserID: user.ID, DangerLevel: "low", DangerScore: 0.1, CreatedAt: day(1)},
+		{UserID: user.ID, DangerLevel: "high", DangerScore: 0.7, CreatedAt: day(2), UserVerdict: &scam},
+		{UserID: user.ID, DangerLevel: "critical", DangerScore: 0.95, CreatedAt: day(3), ContentType: "url"},
+		{UserID: user.ID, Status: "failed", CreatedAt: day(4)},
+		{UserID: user.ID, DangerLevel: "medium", DangerScore: 0.4, CreatedAt: day(5)},
+		{UserID: other.ID, DangerLevel: "high", DangerScore: 0.7, CreatedAt: day(2)},
+	}
+	createHistory(t, db, checks)
+	id := func(i int) uint { return checks[i].ID }
+
+	score := func(v float64) *float64 { return &v }
+	at := func(v time.Time) *time.Time { return &v }
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter models.CheckHistoryFilter
+		want   []uint
+	}{
+		{name: "all checks of the user", want: []uint{id(4), id(3), id(2), id(1), id(0)}},
+		{name: "risk levels", filter: models.CheckHistoryFilter{DangerLevels: []string{"high", "critical"}}, want: []uint{id(2), id(1)}},
+		{name: "status", filter: models.CheckHistoryFilter{Statuses: []string{"failed"}}, want: []uint{id(3)}},
+		{name: "content type", filter: models.CheckHistoryFilter{ContentType: "url"}, want: []uint{id(2)}},
+		{name: "score range", filter: models.CheckHistoryFilter{MinScore: score(0.4), MaxScore: score(0.7)}, want: []uint{id(4), id(1)}},
+		{
+			// Начало включается, конец нет
+			name:   "date range",
+			filter: models.CheckHistoryFilter{From: at(day(2)), To: at(day(4))},
+			want:   []uint{id(2), id(1)},
+		},
+		{name: "with feedback", filter: models.CheckHistoryFilter{HasFeedback: &yes}, want: []uint{id(1)}},
+		{name: "without feedback", filter: models.CheckHistoryFilter{HasFeedback: &no}, want: []uint{id(4), id(3), id(2), id(0)}},
+		{
+			name:   "combined",
+			filter: models.CheckHistoryFilter{DangerLevels: []string{"low", "high"}, From: at(day(2))},
+			want:   []uint{id(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := repo.ListChecks(context.Background(), user.ID, tt.filter, models.CheckPage{Limit: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := checkIDs(list.Checks); !slices.Equal(got, tt.want) {
+				t.Fatalf("checks = %v, want %v", got, tt.want)
+			}
+			if list.Total == nil || *list.Total != int64(len(tt.want)) || list.HasMore {
+				t.Fatalf("total %v, has more %v; want %d and false", list.Total, list.HasMore, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListChecksSearch(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "search")
+
+	checks := []*models.Check{
+		{UserID: user.ID, Content: "Ваша карта заблокирована, срочно позвоните в банк"},
+		{UserID: user.ID, Content: "Please verify your account at the bank"},
+		{UserID: user.ID, Content: "Карту можно забрать в отделении банка"},
+		{UserID: user.ID, Content: "Встреча перенесена на пятницу"},
+	}
+	createHistory(t, db, checks)
+	id := func(i int) uint { return checks[i].ID }
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+	}{
+		// Русская морфология: «карты» находит «карта» и «карту»
+		{name: "russian word forms", query: "карты", want: []uint{id(2), id(0)}},
+		{name: "english word forms", query: "verified accounts", want: []uint{id(1)}},
+		{name: "phrase", query: `"карта заблокирована"`, want: []uint{id(0)}},
+		{name: "exclusion", query: "карта -заблокирована", want: []uint{id(2)}},
+		{name: "or", query: "пятницу or verify", want: []uint{id(3), id(1)}},
+		{name: "no match", query: "криптовалюта", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := models.CheckHistoryFilter{Query: tt.query, Sort: models.HistorySortCreatedAt}
+			list, err := repo.ListChecks(context.Background(), user.ID, filter, models.CheckPage{Limit: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := checkIDs(list.Checks); !slices.Equal(got, tt.want) {
+				t.Fatalf("checks = %v, want %v", got, tt.want)
+			}
+			for _, check := range list.Checks {
+				if !strings.Contains(check.Snippet, "<mark>") {
+					t.Errorf("check %d: snippet %q has no highlighted words", check.ID, check.Snippet)
+				}
+			}
+		})
+	}
+}
+
+func TestListChecksSnippetEscapesContent(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "snippet")
+
+	createHistory(t, db, []*models.Check{{
+		UserID:  user.ID,
+		Content: `<script>alert("x")</script> Срочно переведите деньги & <b>код</b>`,
+	}})
+
+	filter := models.CheckHistoryFilter{Query: "переведите", Sort: models.HistorySortRelevance}
+	list, err := repo.ListChecks(context.Background(), user.ID, filter, models.CheckPage{Limit: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Checks) != 1 {
+		t.Fatalf("found %d checks, want 1", len(list.Checks))
+	}
+
+	snippet := list.Checks[0].Snippet
+	for _, unsafe := range []string{"<script>", "<b>", "</b>", " & "} {
+		if strings.Contains(snippet, unsafe) {
+			t.Errorf("snippet %q contains unescaped %q", snippet, unsafe)
+		}
+	}
+	for _, want := range []string{"&lt;script&gt;", "&amp;", "<mark>переведите</mark>"} {
+		if !strings.Contains(snippet, want) {
+			t.Errorf("snippet %q does not contain %q", snippet, want)
+		}
+	}
+	// Без поиска фрагмент не вычисляется
+	list, err = repo.ListChecks(context.Background(), user.ID, models.CheckHistoryFilter{}, models.CheckPage{Limit: 20})
+	if err != nil || list.Checks[0].Snippet != "" {
+		t.Fatalf("snippet without search = %q, %v", list.Checks[0].Snippet, err)
+	}
+}
+
+func TestListChecksOrder(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "order")
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	checks := []*models.Check{
+		{UserID: user.ID, DangerScore: 0.5, ProcessingTime: 30, CreatedAt: created, Content: "банк"},
+		{UserID: user.ID, DangerScore: 0.9, ProcessingTime: 10, CreatedAt: created.Add(time.Hour), Content: "банк банк банк карта"},
+		{UserID: user.ID, DangerScore: 0.5, ProcessingTime: 20, CreatedAt: created.Add(time.Hour), Content: "банк банк"},
+		{UserID: user.ID, DangerScore: 0.1, ProcessingTime: 20, CreatedAt: created.Add(-time.Hour), Content: "погода"},
+	}
+	createHistory(t, db, checks)
+	id := func(i int) uint { return checks[i].ID }
+
+	tests := []struct {
+		name   string
+		filter models.CheckHistoryFilter
+		want   []uint
+	}{
+		// При равенстве ключа порядок задаёт id в том же направлении
+		{name: "created_at desc", filter: models.CheckHistoryFilter{Sort: models.HistorySortCreatedAt}, want: []uint{id(2), id(1), id(0), id(3)}},
+		{name: "created_at asc", filter: models.CheckHistoryFilter{Sort: models.HistorySortCreatedAt, Asc: true}, want: []uint{id(3), id(0), id(1), id(2)}},
+		{name: "danger_score desc", filter: models.CheckHistoryFilter{Sort: models.HistorySortDangerScore}, want: []uint{id(1), id(2), id(0), id(3)}},
+		{name: "danger_score asc", filter: models.CheckHistoryFilter{Sort: models.HistorySortDangerScore, Asc: true}, want: []uint{id(3), id(0), id(2), id(1)}},
+		{name: "processing_time desc", filter: models.CheckHistoryFilter{Sort: models.HistorySortProcessingTime}, want: []uint{id(0), id(3), id(2), id(1)}},
+		{
+			name:   "relevance",
+			filter: models.CheckHistoryFilter{Sort: models.HistorySortRelevance, Query: "банк"},
+			want:   []uint{id(1), id(2), id(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := repo.ListChecks(context.Background(), user.ID, tt.filter, models.CheckPage{Limit: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := checkIDs(list.Checks); !slices.Equal(got, tt.want) {
+				t.Fatalf("checks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Страницы по смещению не пересекаются и при равных ключах
+	var paged []uint
+	for offset := 0; offset < len(checks); offset += 2 {
+		filter := models.CheckHistoryFilter{Sort: models.HistorySortDangerScore}
+		list, err := repo.ListChecks(context.Background(), user.ID, filter, models.CheckPage{Limit: 2, Offset: offset, SkipTotal: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Total != nil {
+			t.Fatalf("total = %d with SkipTotal", *list.Total)
+		}
+		paged = append(paged, checkIDs(list.Checks)...)
+	}
+	if want := []uint{id(1), id(2), id(0), id(3)}; !slices.Equal(paged, want) {
+		t.Fatalf("paged checks = %v, want %v", paged, want)
+	}
+}
+
+func TestListChecksKeyset(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewCheckRepository(db)
+	user := createTestUser(t, db, "keyset")
+
+	// Три пары проверок с одинаковым created_at: курсор различает их по id
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var checks []*models.Check
+	for i := range 7 {
+		checks = append(checks, &models.Check{UserID: user.ID, CreatedAt: created.Add(time.Duration(i/2) * time.Minute)})
+	}
+	createHistory(t, db, checks)
+
+	for _, asc := range []bool{false, true} {
+		filter := models.CheckHistoryFilter{Sort: models.HistorySortCreatedAt, Asc: asc}
+		all, err := repo.ListChecks(context.Background(), user.ID, filter, models.CheckPage{Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := checkIDs(all.Checks)
+		if len(want) != len(checks) {
+			t.Fatalf("asc %v: listed %d checks, want %d", asc, len(want), len(checks))
+		}
+
+		// Вперёд от начала: каждая страница начинается после последней
+		// проверки предыдущей
+		var forward []uint
+		page := models.CheckPage{Limit: 2, SkipTotal: true}
+		for {
+			list, err := repo.ListChecks(context.Background(), user.ID, filter, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			forward = append(forward, checkIDs(list.Checks)...)
+			if !list.HasMore {
+				break
+			}
+			last := list.Checks[len(list.Checks)-1]
+			page.After = &models.CheckCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+		if !slices.Equal(forward, want) {
+			t.Fatalf("asc %v: forward pages %v, want %v", asc, forward, want)
+		}
+
+		// Назад от конца: страница перед первой проверкой предыдущей идёт
+		// в том же порядке сортировки
+		var backward []uint
+		last := all.Checks[len(all.Checks)-1]
+		page = models.CheckPage{Limit: 2, SkipTotal: true, Before: &models.CheckCursor{CreatedAt: last.CreatedAt, ID: last.ID}}
+		for {
+			list, err := repo.ListChecks(context.Background(), user.ID, filter, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			backward = append(checkIDs(list.Checks), backward...)
+			if !list.HasMore {
+				break
+			}
+			first := list.Checks[0]
+			page.Before = &models.CheckCursor{CreatedAt: first.CreatedAt, ID: first.ID}
+		}
+		if !slices.Equal(backward, want[:len(want)-1]) {
+			t.Fatalf("asc %v: backward pages %v, want %v", asc, backward, want[:len(want)-1])
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if identity == nil {
+		return gorm.ErrInvalidData
+	}
+
+	err := r.db.WithContext(ctx).Create(identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return gorm.ErrDuplicatedKey
+		}
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *identityRepository) CreateWithUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	if user == nil || identity == nil {
+		return gorm.ErrInvalidData
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return gorm.ErrDuplicatedKey
+			}
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		// GORM подставляет default:true вместо нулевого значения bool при Create
+		if !user.HasPassword {
+			if err := tx.Model(user).Update("has_password", false).Error; err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		identity.UserID = user.ID
+		if err := tx.Create(identity).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return gorm.ErrDuplicatedKey
+			}
+			return fmt.Errorf("failed to create identity: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *identityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+func (r *identityRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count identities: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete identity: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type oidcStateRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCStateRepository(db *gorm.DB) OIDCStateRepository {
+	return &oidcStateRepository{db: db}
+}
+
+func (r *oidcStateRepository) Create(ctx context.Context, state *models.OIDCAuthState) error {
+	if state == nil {
+		return gorm.ErrInvalidData
+	}
+
+	if err := r.db.WithContext(ctx).Create(state).Error; err != nil {
+		return fmt.Errorf("failed to create oidc state: %w", err)
+	}
+
+	return nil
+}
+
+// Consume удаляет state и возвращает его, так что один state нельзя
+// использовать повторно.
+func (r *oidcStateRepository) Consume(ctx context.Context, state string, now time.Time) (*models.OIDCAuthState, error) {
+	if state == "" {
+		return nil, gorm.ErrInvalidData
+	}
+
+	var consumed []models.OIDCAuthState
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("state = ? AND expires_at > ?", state, now).
+		Delete(&consumed)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to consume oidc state: %w", result.Error)
+	}
+
+	if len(consumed) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &consumed[0], nil
+}
+
+func (r *oidcStateRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.OIDCAuthState{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired oidc states: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -14,18 +14,32 @@ type UserRepository interface {
 	GetByEmail(email string) (*models.User, error)
 	GetByUsernameOrEmail(login string) (*models.User, error)
 	Update(id uint, data *models.UpdateUserRequest) error
+	UpdatePassword(id uint, passwordHash string) error
+	SetRoleByUsername(username, role string) error
 	Delete(id uint) error
+	ScheduleDeletion(id uint, at time.Time) error
+	CancelDeletion(id uint) error
+	ListDueForPurge(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	Purge(ctx context.Context, id uint, now time.Time) error
 }
 
 type CheckRepository interface {
 	CreateCheck(check *models.Check) error
 	GetCheckByID(id uint) (*models.Check, error)
-	GetChecksByUserID(userID uint, limit, offset int) ([]models.Check, int64, error)
+	ListChecks(ctx context.Context, userID uint, filter models.CheckHistoryFilter, page models.CheckPage) (*models.CheckList, error)
 	UpdateCheckStatus(id uint, status string, dangerScore float64, dangerLevel string, processingTime int) error
-	AddCheckDetail(detail *models.CheckDetail) error
+	CompleteCheck(ctx context.Context, check *models.Check, details []*models.CheckDetail) error
+	ListDegradedChecks(ctx context.Context, limit int) ([]models.Check, error)
+	SetUserVerdict(ctx context.Context, id, userID uint, isScam bool) error
+	ApplyRescore(ctx context.Context, id uint, dangerScore float64, dangerLevel, modelName, modelVersion string, details []*models.CheckDetail) error
+	CountForReanalysis(ctx context.Context, filter models.CheckFilter) (int64, error)
+	ListForReanalysis(ctx context.Context, filter models.CheckFilter, afterID uint, limit int) ([]models.Check, error)
+	ApplyRevision(ctx context.Context, revision *models.CheckRevision, details []*models.CheckDetail) error
+	ListRevisions(ctx context.Context, checkID, userID uint) ([]models.CheckRevision, error)
 	GetCheckDetails(checkID uint) ([]models.CheckDetail, error)
-	DeleteCheck(id uint, userID uint) error
-	GetUserStats(userID uint) (map[string]interface{}, error)
+	DeleteCheck(ctx context.Context, id, userID uint) error
+	GetUserStats(ctx context.Context, userID uint) (*models.UserStats, error)
+	GetStatsTimeSeries(ctx context.Context, userID uint, query models.StatsTimeSeriesQuery) (*models.StatsTimeSeries, error)
 }
 
 type SessionRepository interface {
@@ -35,3 +49,66 @@ type SessionRepository interface {
 	InvalidateAllByUser(ctx context.Context, userID uint) error
 	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 }
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	CreateWithUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	Delete(ctx context.Context, userID uint, provider string) error
+}
+
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *models.OIDCAuthState) error
+	Consume(ctx context.Context, state string, now time.Time) (*models.OIDCAuthState, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter models.AuditEventFilter, limit, offset int) ([]models.AuditEvent, int64, error)
+}
+
+type ModelEvaluationRepository interface {
+	Create(ctx context.Context, evaluations []*models.ModelEvaluation) error
+	Compare(ctx context.Context, since time.Time) (*models.ModelComparisonReport, error)
+}
+
+type ReanalysisJobRepository interface {
+	Create(ctx context.Context, job *models.ReanalysisJob) error
+	Update(ctx context.Context, job *models.ReanalysisJob) error
+	GetByID(ctx context.Context, id uint) (*models.ReanalysisJob, error)
+	List(ctx context.Context, limit, offset int) ([]models.ReanalysisJob, int64, error)
+	FailInterrupted(ctx context.Context, now time.Time) (int64, error)
+}
+
+type BatchJobRepository interface {
+	Create(ctx context.Context, job *models.BatchJob) error
+	Update(ctx context.Context, job *models.BatchJob) error
+	GetByID(ctx context.Context, id uint) (*models.BatchJob, error)
+	SaveChunk(ctx context.Context, jobID uint, items []*models.BatchItem, checks []*models.Check, details [][]*models.CheckDetail) error
+	ListItems(ctx context.Context, jobID uint, afterSeq uint, limit int) ([]models.BatchItem, error)
+	FailInterrupted(ctx context.Context, now time.Time) (int64, error)
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	ListByUser(ctx context.Context, userID uint) ([]models.APIKey, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	Delete(ctx context.Context, id, userID uint) error
+	GetActiveByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	MarkUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id, userID uint) (*models.Webhook, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.Webhook, error)
+	Delete(ctx context.Context, id, userID uint) error
+	CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id, webhookID uint) (*models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID uint, limit, offset int) ([]models.WebhookDelivery, int64, error)
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const scoreHistogramBuckets = 10
+
+type modelEvaluationRepository struct {
+	db *gorm.DB
+}
+
+func NewModelEvaluationRepository(db *gorm.DB) ModelEvaluationRepository {
+	return &modelEvaluationRepository{db: db}
+}
+
+// Create не перезаписывает существующую оценку той же модели для проверки.
+func (r *modelEvaluationRepository) Create(ctx context.Context, evaluations []*models.ModelEvaluation) error {
+	if len(evaluations) == 0 {
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(evaluations).Error; err != nil {
+		return fmt.Errorf("failed to create model evaluations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *modelEvaluationRepository) Compare(ctx context.Context, since time.Time) (*models.ModelComparisonReport, error) {
+	db := r.db.WithContext(ctx)
+	report := &models.ModelComparisonReport{Since: since}
+
+	var paired struct {
+		Paired     int64
+		Agreements int64
+	}
+	err := db.Raw(`
+		SELECT COUNT(*) AS paired,
+		       COUNT(*) FILTER (WHERE l.is_scam = c.is_scam) AS agreements
+		FROM model_evaluations l
+		JOIN model_evaluations c ON c.check_id = l.check_id AND c.variant = ?
+		WHERE l.variant = ? AND l.error = '' AND c.error = '' AND l.created_at >= ?`,
+		models.VariantCandidate, models.VariantLive, since,
+	).Scan(&paired).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute agreement: %w", err)
+	}
+
+	report.PairedChecks = paired.Paired
+	report.Agreements = paired.Agreements
+	if paired.Paired > 0 {
+		rate := float64(paired.Agreements) / float64(paired.Paired)
+		report.AgreementRate = &rate
+	}
+
+	var variants []struct {
+		Variant        string
+		Model          string
+		Evaluations    int64
+		Served         int64
+		Errors         int64
+		ScamRate       float64
+		AvgDangerScore float64
+		AvgLatencyMs   float64
+		FeedbackCount  int64
+		FeedbackHits   int64
+	}
+	err = db.Raw(`
+		SELECT e.variant,
+		       MAX(e.model) AS model,
+		       COUNT(*) AS evaluations,
+		       COUNT(*) FILTER (WHERE e.served) AS served,
+		       COUNT(*) FILTER (WHERE e.error <> '') AS errors,
+		       COALESCE(AVG(CASE WHEN e.is_scam THEN 1.0 ELSE 0.0 END) FILTER (WHERE e.error = ''), 0) AS scam_rate,
+		       COALESCE(AVG(e.danger_score) FILTER (WHERE e.error = ''), 0) AS avg_danger_score,
+		       COALESCE(AVG(e.latency_ms) FILTER (WHERE e.error = ''), 0) AS avg_latency_ms,
+		       COUNT(ch.user_verdict) FILTER (WHERE e.error = '') AS feedback_count,
+		       COUNT(*) FILTER (WHERE e.error = '' AND ch.user_verdict = e.is_scam) AS feedback_hits
+		FROM model_evaluations e
+		LEFT JOIN checks ch ON ch.id = e.check_id
+		WHERE e.created_at >= ?
+		GROUP BY e.variant
+		ORDER BY e.variant DESC`, since,
+	).Scan(&variants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute variant stats: %w", err)
+	}
+
+	var buckets []struct {
+		Variant string
+		Bucket  int
+		Count   int64
+	}
+	err = db.Raw(`
+		SELECT variant,
+		       LEAST(FLOOR(danger_score * ?)::int, ?) AS bucket,
+		       COUNT(*) AS count
+		FROM model_evaluations
+		WHERE created_at >= ? AND error = ''
+		GROUP BY variant, bucket`,
+		scoreHistogramBuckets, scoreHistogramBuckets-1, since,
+	).Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute score distribution: %w", err)
+	}
+
+	histograms := make(map[string][]models.ScoreBucket)
+	for _, v := range variants {
+		histogram := make([]models.ScoreBucket, scoreHistogramBuckets)
+		for i := range histogram {
+			histogram[i].From = float64(i) / scoreHistogramBuckets
+			histogram[i].To = float64(i+1) / scoreHistogramBuckets
+		}
+		histograms[v.Variant] = histogram
+	}
+	for _, b := range buckets {
+		if histogram, ok := histograms[b.Variant]; ok && b.Bucket >= 0 && b.Bucket < scoreHistogramBuckets {
+			histogram[b.Bucket].Count = b.Count
+		}
+	}
+
+	for _, v := range variants {
+		variant := models.VariantReport{
+			Variant:        v.Variant,
+			Model:          v.Model,
+			Evaluations:    v.Evaluations,
+			Served:         v.Served,
+			Errors:         v.Errors,
+			ScamRate:       v.ScamRate,
+			AvgDangerScore: v.AvgDangerScore,
+			AvgLatencyMs:   v.AvgLatencyMs,
+			ScoreHistogram: histograms[v.Variant],
+			FeedbackCount:  v.FeedbackCount,
+		}
+		if v.FeedbackCount > 0 {
+			accuracy := float64(v.FeedbackHits) / float64(v.FeedbackCount)
+			variant.FeedbackAccuracy = &accuracy
+		}
+		report.Variants = append(report.Variants, variant)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type reanalysisJobRepository struct {
+	db *gorm.DB
+}
+
+func NewReanalysisJobRepository(db *gorm.DB) ReanalysisJobRepository {
+	return &reanalysisJobRepository{db: db}
+}
+
+func (r *reanalysisJobRepository) Create(ctx context.Context, job *models.ReanalysisJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create reanalysis job: %w", err)
+	}
+	return nil
+}
+
+func (r *reanalysisJobRepository) Update(ctx context.Context, job *models.ReanalysisJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update reanalysis job: %w", err)
+	}
+	return nil
+}
+
+func (r *reanalysisJobRepository) GetByID(ctx context.Context, id uint) (*models.ReanalysisJob, error) {
+	var job models.ReanalysisJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *reanalysisJobRepository) List(ctx context.Context, limit, offset int) ([]models.ReanalysisJob, int64, error) {
+	var jobs []models.ReanalysisJob
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.ReanalysisJob{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reanalysis jobs: %w", err)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list reanalysis jobs: %w", err)
+	}
+
+	return jobs, total, nil
+}
+
+// FailInterrupted помечает задачи, прерванные перезапуском сервера: задачи
+// выполняются в памяти процесса и сами не возобновляются.
+func (r *reanalysisJobRepository) FailInterrupted(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ReanalysisJob{}).
+		Where("status IN ?", []string{models.ReanalysisPending, models.ReanalysisRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ReanalysisFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"scam-detection-backend/internal/dbtest"
+	"scam-detection-backend/internal/migrations"
+	"scam-detection-backend/internal/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newTestDB - пустая схема с применёнными миграциями.
+func newTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	return db
+}
+
+func createTestUser(t testing.TB, db *gorm.DB, username string) *models.User {
+	t.Helper()
+
+	user := &models.User{Username: username, PasswordHash: "hash", Role: models.RoleUser}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
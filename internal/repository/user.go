@@ -1,11 +1,14 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"scam-detection-backend/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -118,6 +121,37 @@ func (r *userRepository) Update(id uint, data *models.UpdateUserRequest) error {
 	return nil
 }
 
+func (r *userRepository) UpdatePassword(id uint, passwordHash string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password_hash": passwordHash,
+		"has_password":  true,
+	})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update password: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetRoleByUsername(username, role string) error {
+	result := r.db.Model(&models.User{}).Where("username = ?", username).Update("role", role)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set user role: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 func (r *userRepository) Delete(id uint) error {
 	result := r.db.Delete(&models.User{}, id)
 
@@ -130,3 +164,119 @@ func (r *userRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+func (r *userRepository) ScheduleDeletion(id uint, at time.Time) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("deletion_scheduled_at", at)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to schedule user deletion: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CancelDeletion блокирует строку пользователя так же, как Purge: отмена и
+// удаление выполняются по очереди. Если Purge успел первым, возвращается
+// gorm.ErrRecordNotFound.
+func (r *userRepository) CancelDeletion(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return gorm.ErrRecordNotFound
+			}
+			return fmt.Errorf("failed to lock user for restore: %w", err)
+		}
+
+		if err := tx.Model(&user).Update("deletion_scheduled_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to cancel user deletion: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) ListDueForPurge(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for purge: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Purge удаляет пользователя и все его данные в одной транзакции. Журнал
+// аудита не трогается: он только дополняется.
+func (r *userRepository) Purge(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Та же блокировка, что в CancelDeletion: вход с восстановлением и
+		// удаление не пересекаются
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", id, now).
+			First(&user).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return gorm.ErrRecordNotFound
+			}
+			return fmt.Errorf("failed to lock user for purge: %w", err)
+		}
+
+		userChecks := tx.Model(&models.Check{}).Select("id").Where("user_id = ?", id)
+
+		if err := tx.Where("check_id IN (?)", userChecks).Delete(&models.CheckDetail{}).Error; err != nil {
+			return fmt.Errorf("failed to purge check details: %w", err)
+		}
+		if err := tx.Where("check_id IN (?)", userChecks).Delete(&models.ModelEvaluation{}).Error; err != nil {
+			return fmt.Errorf("failed to purge model evaluations: %w", err)
+		}
+		if err := tx.Where("check_id IN (?)", userChecks).Delete(&models.CheckRevision{}).Error; err != nil {
+			return fmt.Errorf("failed to purge check revisions: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Check{}).Error; err != nil {
+			return fmt.Errorf("failed to purge checks: %w", err)
+		}
+		userWebhooks := tx.Model(&models.Webhook{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("webhook_id IN (?)", userWebhooks).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("failed to purge webhook deliveries: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Webhook{}).Error; err != nil {
+			return fmt.Errorf("failed to purge webhooks: %w", err)
+		}
+		userBatches := tx.Model(&models.BatchJob{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("batch_id IN (?)", userBatches).Delete(&models.BatchItem{}).Error; err != nil {
+			return fmt.Errorf("failed to purge batch items: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.BatchJob{}).Error; err != nil {
+			return fmt.Errorf("failed to purge batch jobs: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.APIKey{}).Error; err != nil {
+			return fmt.Errorf("failed to purge api keys: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserSessions{}).Error; err != nil {
+			return fmt.Errorf("failed to purge sessions: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserIdentity{}).Error; err != nil {
+			return fmt.Errorf("failed to purge identities: %w", err)
+		}
+		if err := tx.Where("link_user_id = ?", id).Delete(&models.OIDCAuthState{}).Error; err != nil {
+			return fmt.Errorf("failed to purge oidc states: %w", err)
+		}
+
+		if err := tx.Delete(&user).Error; err != nil {
+			return fmt.Errorf("failed to purge user: %w", err)
+		}
+
+		return nil
+	})
+}
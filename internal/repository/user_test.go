@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// seedAccount заполняет для пользователя все таблицы, которые стирает Purge.
+func seedAccount(t *testing.T, db *gorm.DB, user *models.User) {
+	t.Helper()
+
+	now := time.Now()
+	check := &models.Check{Title: "check", ContentType: "text", Content: "текст", UserID: user.ID}
+	batch := &models.BatchJob{UserID: user.ID, Status: models.BatchCompleted, Total: 1}
+	webhook := &models.Webhook{UserID: user.ID, URL: "https://example.com/hook", Secret: "whsec", Events: "check.completed"}
+	create := func(value interface{}) {
+		t.Helper()
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed %T: %v", value, err)
+		}
+	}
+
+	create(check)
+	create(&models.CheckDetail{CheckID: check.ID, FeatureName: "ml_prediction"})
+	create(&models.CheckRevision{CheckID: check.ID, Revision: 1, Source: "rescore"})
+	create(&models.ModelEvaluation{CheckID: check.ID, Variant: "live", Model: "live", Served: true})
+	create(batch)
+	create(&models.BatchItem{BatchID: batch.ID, Seq: 1, Index: 0, Status: models.BatchItemOK, CheckID: &check.ID})
+	create(webhook)
+	create(&models.WebhookDelivery{WebhookID: webhook.ID, EventType: models.WebhookEventTest, Payload: "{}", Status: "pending", NextAttemptAt: now})
+	create(&models.APIKey{UserID: user.ID, Name: "ci", Prefix: "sdk_", KeyHash: fmt.Sprintf("%064d", user.ID)})
+	create(&models.UserSessions{UserId: user.ID, TokenHash: "hash", ExpiresAt: now.Add(time.Hour)})
+	create(&models.UserIdentity{UserID: user.ID, Provider: "google", Subject: fmt.Sprintf("subject-%d", user.ID)})
+	create(&models.OIDCAuthState{State: fmt.Sprintf("state-%d", user.ID), Provider: "google", CodeVerifier: "v", Nonce: "n", LinkUserID: &user.ID, ExpiresAt: now.Add(time.Minute)})
+	create(&models.AuditEvent{UserID: &user.ID, EventType: models.AuditAccountDeleted})
+}
+
+// userRows считает строки пользователя в каждой таблице, которую стирает Purge.
+func userRows(t *testing.T, db *gorm.DB, userID uint) map[string]int64 {
+	t.Helper()
+
+	checks := db.Model(&models.Check{}).Select("id").Where("user_id = ?", userID)
+	batches := db.Model(&models.BatchJob{}).Select("id").Where("user_id = ?", userID)
+	webhooks := db.Model(&models.Webhook{}).Select("id").Where("user_id = ?", userID)
+	queries := map[string]*gorm.DB{
+		"users":              db.Model(&models.User{}).Where("id = ?", userID),
+		"checks":             db.Model(&models.Check{}).Where("user_id = ?", userID),
+		"check_details":      db.Model(&models.CheckDetail{}).Where("check_id IN (?)", checks),
+		"check_revisions":    db.Model(&models.CheckRevision{}).Where("check_id IN (?)", checks),
+		"model_evaluations":  db.Model(&models.ModelEvaluation{}).Where("check_id IN (?)", checks),
+		"batch_jobs":         db.Model(&models.BatchJob{}).Where("user_id = ?", userID),
+		"batch_items":        db.Model(&models.BatchItem{}).Where("batch_id IN (?)", batches),
+		"webhooks":           db.Model(&models.Webhook{}).Where("user_id = ?", userID),
+		"webhook_deliveries": db.Model(&models.WebhookDelivery{}).Where("webhook_id IN (?)", webhooks),
+		"api_keys":           db.Model(&models.APIKey{}).Where("user_id = ?", userID),
+		"user_sessions":      db.Model(&models.UserSessions{}).Where("user_id = ?", userID),
+		"user_identities":    db.Model(&models.UserIdentity{}).Where("user_id = ?", userID),
+		"oidc_auth_states":   db.Model(&models.OIDCAuthState{}).Where("link_user_id = ?", userID),
+	}
+
+	counts := make(map[string]int64, len(queries))
+	for table, query := range queries {
+		var n int64
+		if err := query.Count(&n).Error; err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		counts[table] = n
+	}
+	return counts
+}
+
+func TestUserPurge(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	doomed := createTestUser(t, db, "doomed")
+	kept := createTestUser(t, db, "kept")
+	seedAccount(t, db, doomed)
+	seedAccount(t, db, kept)
+
+	for table, n := range userRows(t, db, doomed.ID) {
+		if n == 0 {
+			t.Fatalf("%s is not seeded", table)
+		}
+	}
+
+	if err := repo.Purge(ctx, doomed.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Purge without scheduled deletion = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+	if err := repo.ScheduleDeletion(doomed.ID, now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Purge(ctx, doomed.ID, now); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	for table, n := range userRows(t, db, doomed.ID) {
+		if n != 0 {
+			t.Errorf("%s still has %d rows of the purged user", table, n)
+		}
+	}
+	for table, n := range userRows(t, db, kept.ID) {
+		if n == 0 {
+			t.Errorf("%s lost rows of another user", table)
+		}
+	}
+
+	// Журнал аудита только дополняется
+	var audit int64
+	if err := db.Model(&models.AuditEvent{}).Where("user_id = ?", doomed.ID).Count(&audit).Error; err != nil || audit != 1 {
+		t.Fatalf("audit events of purged user: %d, %v", audit, err)
+	}
+
+	if err := repo.CancelDeletion(doomed.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("CancelDeletion after Purge = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestUserCancelDeletionBeforePurge(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	now := time.Now()
+
+	user := createTestUser(t, db, "restored")
+	if err := repo.ScheduleDeletion(user.ID, now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CancelDeletion(user.ID); err != nil {
+		t.Fatalf("CancelDeletion: %v", err)
+	}
+	if err := repo.Purge(context.Background(), user.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Purge after restore = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	if err := r.db.WithContext(ctx).Create(webhook).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id, userID uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) ListByUser(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Delete удаляет вебхук вместе с журналом доставок.
+func (r *webhookRepository) Delete(ctx context.Context, id, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Доставки ссылаются на webhook внешним ключом и удаляются первыми
+		owned := tx.Model(&models.Webhook{}).Select("id").Where("id = ? AND user_id = ?", id, userID)
+		if err := tx.Where("webhook_id IN (?)", owned).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(deliveries).Error; err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id, webhookID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).Where("id = ? AND webhook_id = ?", id, webhookID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ClaimDueDeliveries выбирает доставки, время попытки которых наступило, и
+// откладывает их следующую попытку на lease: другой экземпляр сервиса не
+// возьмёт их, пока идёт отправка. Если экземпляр упадёт, доставка будет
+// повторена по истечении lease.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Preload("Webhook").
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"scam-detection-backend/internal/repository"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// accountStatusTTL - сколько помнится, что аккаунт может работать с API.
+// Удаление, запрошенное на этом экземпляре, действует сразу, на остальных -
+// не позже чем через accountStatusTTL.
+const accountStatusTTL = 30 * time.Second
+
+var (
+	ErrAccountPendingDeletion = errors.New("аккаунт ожидает удаления")
+	ErrAccountCheckFailed     = errors.New("не удалось проверить аккаунт")
+)
+
+// AccountGuard проверяет владельца access токена: JWT остаётся валидным до
+// истечения, даже если аккаунт деактивирован или ждёт удаления.
+type AccountGuard struct {
+	userRepo repository.UserRepository
+	// now подменяется в тестах
+	now func() time.Time
+
+	mu      sync.Mutex
+	allowed map[uint]time.Time
+	swept   time.Time
+}
+
+func NewAccountGuard(userRepo repository.UserRepository) *AccountGuard {
+	return &AccountGuard{
+		userRepo: userRepo,
+		now:      time.Now,
+		allowed:  make(map[uint]time.Time),
+	}
+}
+
+// Check возвращает ErrAccountDisabled, если аккаунта нет или он
+// деактивирован, и ErrAccountPendingDeletion, если запрошено удаление.
+// Кешируется только разрешение: восстановленный вход сразу получает доступ.
+func (g *AccountGuard) Check(userID uint) error {
+	now := g.now()
+
+	g.mu.Lock()
+	checkedAt, ok := g.allowed[userID]
+	g.mu.Unlock()
+	if ok && now.Sub(checkedAt) < accountStatusTTL {
+		return nil
+	}
+
+	user, err := g.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountDisabled
+		}
+		return fmt.Errorf("%w: %v", ErrAccountCheckFailed, err)
+	}
+
+	if !user.IsActive {
+		g.Forget(userID)
+		return ErrAccountDisabled
+	}
+	if user.DeletionScheduledAt != nil {
+		g.Forget(userID)
+		return ErrAccountPendingDeletion
+	}
+
+	g.mu.Lock()
+	g.allowed[userID] = now
+	// Устаревшие записи удаляются не чаще раза за accountStatusTTL
+	if now.Sub(g.swept) >= accountStatusTTL {
+		for id, at := range g.allowed {
+			if now.Sub(at) >= accountStatusTTL {
+				delete(g.allowed, id)
+			}
+		}
+		g.swept = now
+	}
+	g.mu.Unlock()
+
+	return nil
+}
+
+// Forget сбрасывает кеш пользователя после изменения статуса аккаунта.
+func (g *AccountGuard) Forget(userID uint) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.allowed, userID)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/crypto"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (stubSessions) ValidateAccessToken(token string) (uint, error) {
+	return 1, nil
+}
+
+func (stubSessions) InvalidateAllUserSessions(ctx context.Context, userID uint) error {
+	return nil
+}
+
+func (stubSessions) CleanupExpiredSessions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryUsers) GetByUsernameOrEmail(login string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == login || (u.Email != nil && *u.Email == login) })
+}
+
+func (r *memoryUsers) ScheduleDeletion(id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].DeletionScheduledAt = &at
+	return nil
+}
+
+type brokenUsers struct {
+	repository.UserRepository
+}
+
+func (brokenUsers) GetByID(id uint) (*models.User, error) {
+	return nil, errors.New("connection reset")
+}
+
+// purgedUsers - пользователь удалён между проверкой пароля и восстановлением.
+type purgedUsers struct {
+	*memoryUsers
+}
+
+func (purgedUsers) CancelDeletion(id uint) error {
+	return gorm.ErrRecordNotFound
+}
+
+func TestAccountGuardCheck(t *testing.T) {
+	scheduled := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		users   repository.UserRepository
+		wantErr error
+	}{
+		{name: "active", users: newMemoryUsers(&models.User{ID: 1, IsActive: true})},
+		{name: "disabled", users: newMemoryUsers(&models.User{ID: 1}), wantErr: ErrAccountDisabled},
+		{
+			name:    "pending deletion",
+			users:   newMemoryUsers(&models.User{ID: 1, IsActive: true, DeletionScheduledAt: &scheduled}),
+			wantErr: ErrAccountPendingDeletion,
+		},
+		{name: "purged", users: newMemoryUsers(), wantErr: ErrAccountDisabled},
+		{name: "storage failure", users: brokenUsers{}, wantErr: ErrAccountCheckFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAccountGuard(tt.users).Check(1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccountGuardCache(t *testing.T) {
+	users := newMemoryUsers(&models.User{ID: 1, IsActive: true})
+	guard := NewAccountGuard(users)
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+
+	if err := guard.Check(1); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	// Удаление, запрошенное на другом экземпляре, видно после accountStatusTTL
+	scheduled := now.Add(time.Hour)
+	users.users[1].DeletionScheduledAt = &scheduled
+	if err := guard.Check(1); err != nil {
+		t.Fatalf("Check within TTL = %v, want cached access", err)
+	}
+	now = now.Add(accountStatusTTL)
+	if err := guard.Check(1); !errors.Is(err, ErrAccountPendingDeletion) {
+		t.Fatalf("Check after TTL = %v, want %v", err, ErrAccountPendingDeletion)
+	}
+
+	// Отказ не кешируется: после восстановления доступ возвращается сразу
+	users.users[1].DeletionScheduledAt = nil
+	if err := guard.Check(1); err != nil {
+		t.Fatalf("Check after restore: %v", err)
+	}
+}
+
+func TestUserServiceRequestDeletionRevokesAccess(t *testing.T) {
+	users := newMemoryUsers(&models.User{ID: 1, IsActive: true})
+	guard := NewAccountGuard(users)
+	service, err := NewUserService(users, stubSessions{}, guard, "720h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := NewAuthService(users, stubSessions{}, guard, nil, &recordedAudit{})
+
+	if _, err := auth.ValidateToken("access"); err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if _, err := service.RequestDeletion(context.Background(), 1); err != nil {
+		t.Fatalf("RequestDeletion: %v", err)
+	}
+	if _, err := auth.ValidateToken("access"); !errors.Is(err, ErrAccountPendingDeletion) {
+		t.Fatalf("ValidateToken after deletion request = %v, want %v", err, ErrAccountPendingDeletion)
+	}
+}
+
+func TestAuthServiceLoginAfterPurge(t *testing.T) {
+	hash, err := crypto.HashPassword("Tq7#vLm2$Rz9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduled := time.Now().Add(-time.Minute)
+	users := purgedUsers{newMemoryUsers(&models.User{
+		ID:                  1,
+		Username:            "ivanovich",
+		PasswordHash:        hash,
+		IsActive:            true,
+		DeletionScheduledAt: &scheduled,
+	})}
+	auth := NewAuthService(users, stubSessions{}, NewAccountGuard(users), nil, &recordedAudit{})
+
+	if _, _, err := auth.Login(context.Background(), "ivanovich", "Tq7#vLm2$Rz9"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
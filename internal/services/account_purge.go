@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const accountPurgeBatchSize = 100
+
+type AccountPurgeJob struct {
+	userRepo     repository.UserRepository
+	auditService AuditService
+	interval     time.Duration
+}
+
+func NewAccountPurgeJob(userRepo repository.UserRepository, auditService AuditService, interval string) (*AccountPurgeJob, error) {
+	every, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountPurgeJob{
+		userRepo:     userRepo,
+		auditService: auditService,
+		interval:     every,
+	}, nil
+}
+
+// Run периодически удаляет аккаунты с истёкшим grace period, пока ctx не
+// будет отменён.
+func (j *AccountPurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := j.PurgeDue(ctx); err != nil {
+			log.Printf("account purge: %v", err)
+		} else if purged > 0 {
+			log.Printf("account purge: удалено аккаунтов: %d", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *AccountPurgeJob) PurgeDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	purged := 0
+
+	for {
+		ids, err := j.userRepo.ListDueForPurge(ctx, now, accountPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		progressed := false
+		for _, id := range ids {
+			if err := j.userRepo.Purge(ctx, id, now); err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Printf("account purge: не удалось удалить пользователя %d: %v", id, err)
+				}
+				continue
+			}
+
+			j.auditService.Record(ctx, models.AuditAccountPurged, id, nil)
+			purged++
+			progressed = true
+		}
+
+		if !progressed || len(ids) < accountPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// MaxTextLength - максимальная длина анализируемого текста в символах
+const MaxTextLength = 5000
+
+var (
+	dangerLevels  = []string{"low", "medium", "high", "critical"}
+	checkStatuses = []string{"processing", "completed", "failed"}
+)
+
+const (
+	// maxStatsBuckets ограничивает число интервалов в одном ответе
+	maxStatsBuckets       = 1000
+	defaultStatsPeriod    = 30 * 24 * time.Hour
+	defaultStatsTopLength = 10
+)
+
+var (
+	ErrEmptyText     = errors.New("текст не может быть пустым")
+	ErrTextTooLong   = fmt.Errorf("текст длиннее %d символов", MaxTextLength)
+	ErrEmptyBatch    = errors.New("пакет не содержит текстов")
+	ErrTooManyTexts  = errors.New("слишком много текстов в пакете")
+	ErrCheckNotFound = errors.New("проверка не найдена")
+
+	ErrInvalidHistoryFilter = errors.New("неверный фильтр истории")
+	ErrInvalidCursor        = errors.New("неверный курсор истории")
+
+	ErrInvalidStatsBucket = errors.New("интервал должен быть hour, day или week")
+	ErrInvalidStatsRange  = errors.New("начало периода должно быть раньше конца")
+	ErrInvalidTimezone    = errors.New("неизвестный часовой пояс")
+	ErrStatsRangeTooLong  = fmt.Errorf("период содержит больше %d интервалов", maxStatsBuckets)
+
+	// Ошибки ML сервиса оборачивают исходную ошибку клиента
+	ErrMLUnavailable = errors.New("ML сервис недоступен")
+	ErrMLTimeout     = errors.New("ML сервис не ответил вовремя")
+	ErrMLFailed      = errors.New("ошибка ML сервиса")
+)
+
+// TextAnalysis - результат анализа одного текста. Degraded означает, что
+// ML сервис был недоступен и текст оценён только правилами.
+type TextAnalysis struct {
+	Check          *models.Check
+	Success        bool
+	Degraded       bool
+	Prediction     mlclient.PredictionResult
+	ProcessingTime float64
+}
+
+// BatchAnalysis - результат синхронного пакета. Err - первая ошибка среди
+// текстов пакета, nil если обработаны все.
+type BatchAnalysis struct {
+	Job            *models.BatchJob
+	Items          []BatchItem
+	Succeeded      int
+	Degraded       bool
+	Err            error
+	ProcessingTime float64
+}
+
+// Partial: часть текстов обработана, часть нет.
+func (b *BatchAnalysis) Partial() bool {
+	return b.Err != nil && b.Succeeded > 0
+}
+
+// AnalysisService - анализ текстов и работа с историей проверок
+// пользователя. Общий для HTTP и gRPC API.
+type AnalysisService struct {
+	checkRepo  repository.CheckRepository
+	experiment *ModelExperiment
+	batches    *BatchService
+	bus        events.Bus
+	// degradeOnFailure: при сбое ML сервиса оценивать текст только правилами
+	// вместо ошибки
+	degradeOnFailure bool
+}
+
+func NewAnalysisService(checkRepo repository.CheckRepository, experiment *ModelExperiment, batches *BatchService, bus events.Bus, degradeOnFailure bool) *AnalysisService {
+	return &AnalysisService{
+		checkRepo:        checkRepo,
+		experiment:       experiment,
+		batches:          batches,
+		bus:              bus,
+		degradeOnFailure: degradeOnFailure,
+	}
+}
+
+// ValidateText проверяет текст так же, как HTTP API проверяет тело запроса.
+func ValidateText(text string) error {
+	if text == "" {
+		return ErrEmptyText
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return ErrTextTooLong
+	}
+	return nil
+}
+
+// AnalyzeText создаёт проверку и оценивает текст моделью, назначенной
+// пользователю экспериментом. Ошибка ML сервиса возвращается обёрнутой в
+// ErrMLUnavailable, ErrMLTimeout или ErrMLFailed.
+func (s *AnalysisService) AnalyzeText(ctx context.Context, userID uint, text string) (*TextAnalysis, error) {
+	if err := ValidateText(text); err != nil {
+		return nil, err
+	}
+
+	check := &models.Check{
+		Title:       checkTitle(text),
+		ContentType: "text",
+		Content:     text,
+		Status:      "processing",
+		UserID:      userID,
+	}
+
+	if err := s.checkRepo.CreateCheck(check); err != nil {
+		return nil, fmt.Errorf("failed to save check: %w", err)
+	}
+	s.bus.Publish(ctx, events.CheckStatusChanged(check))
+
+	analyzer, variant := s.experiment.AnalyzerFor(userID)
+
+	startTime := time.Now()
+	result, err := analyzer.AnalyzeText(ctx, text)
+	latency := time.Since(startTime)
+	check.ProcessingTime = int(latency.Milliseconds())
+
+	if err != nil {
+		// Деградировать имеет смысл, только если клиент ещё ждёт ответа
+		if s.degradeOnFailure && ctx.Err() == nil {
+			return s.degrade(ctx, check)
+		}
+
+		if s.checkRepo.UpdateCheckStatus(check.ID, "failed", 0, "", check.ProcessingTime) == nil {
+			check.Status = "failed"
+			s.bus.Publish(ctx, events.CheckStatusChanged(check))
+		}
+		return nil, mlError(err)
+	}
+
+	check.DangerScore = CombinedScore(result.Prediction, text)
+	check.DangerLevel = DangerLevel(check.DangerScore)
+	check.ModelName, check.ModelVersion = result.Prediction.ModelName, result.Prediction.ModelVersion
+
+	if err := s.complete(ctx, check, PredictionDetails(check.ID, result.Prediction)); err != nil {
+		return nil, err
+	}
+
+	s.experiment.Record(ctx, check.ID, text, variant, result.Prediction, latency)
+
+	return &TextAnalysis{
+		Check:          check,
+		Success:        result.Success,
+		Prediction:     result.Prediction,
+		ProcessingTime: result.ProcessingTime,
+	}, nil
+}
+
+// degrade оценивает текст правилами, когда ML сервис недоступен. Проверка
+// помечается degraded и позже пересчитывается RescoreJob.
+func (s *AnalysisService) degrade(ctx context.Context, check *models.Check) (*TextAnalysis, error) {
+	ruleScore := RuleScore(check.Content)
+	prediction := RulePrediction(ruleScore)
+
+	check.Degraded = true
+	check.DangerScore, check.DangerLevel = ruleScore, DangerLevel(ruleScore)
+	check.ModelName, check.ModelVersion = models.RulesModelName, models.RulesModelVersion
+
+	if err := s.complete(ctx, check, []*models.CheckDetail{RuleDetail(check.ID, ruleScore, prediction)}); err != nil {
+		return nil, err
+	}
+
+	return &TextAnalysis{
+		Check:          check,
+		Success:        true,
+		Degraded:       true,
+		Prediction:     prediction,
+		ProcessingTime: float64(check.ProcessingTime) / 1000,
+	}, nil
+}
+
+func (s *AnalysisService) complete(ctx context.Context, check *models.Check, details []*models.CheckDetail) error {
+	details = append(details, RuleMatchDetails(check.ID, check.Content)...)
+
+	// Результат уже получен: сохраняем его, даже если клиент отключился
+	if err := s.checkRepo.CompleteCheck(context.WithoutCancel(ctx), check, details); err != nil {
+		return fmt.Errorf("failed to update check: %w", err)
+	}
+
+	check.Status = "completed"
+	s.bus.Publish(ctx, events.CheckStatusChanged(check))
+	return nil
+}
+
+func (s *AnalysisService) validateBatch(texts []string) error {
+	if len(texts) == 0 {
+		return ErrEmptyBatch
+	}
+	if len(texts) > s.batches.MaxTexts() {
+		return fmt.Errorf("%w: не больше %d", ErrTooManyTexts, s.batches.MaxTexts())
+	}
+	for _, text := range texts {
+		if err := ValidateText(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnalyzeBatch обрабатывает пакет синхронно и возвращает результат для
+// каждого текста. Ошибка возвращается, только если пакет не удалось начать.
+func (s *AnalysisService) AnalyzeBatch(ctx context.Context, userID uint, texts []string) (*BatchAnalysis, error) {
+	if err := s.validateBatch(texts); err != nil {
+		return nil, err
+	}
+
+	job, err := s.batches.Create(ctx, userID, len(texts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	startTime := time.Now()
+	items := s.batches.Run(ctx, job, texts)
+
+	result := &BatchAnalysis{
+		Job:            job,
+		Items:          items,
+		ProcessingTime: time.Since(startTime).Seconds(),
+	}
+	for _, item := range items {
+		if item.Status == models.BatchItemFailed {
+			if result.Err == nil {
+				result.Err = batchItemError(item)
+			}
+			continue
+		}
+		result.Succeeded++
+		result.Degraded = result.Degraded || item.Status == models.BatchItemDegraded
+	}
+
+	return result, nil
+}
+
+// StartBatch создаёт пакет и обрабатывает его в фоне. Прогресс доступен
+// через BatchService.
+func (s *AnalysisService) StartBatch(ctx context.Context, userID uint, texts []string) (*models.BatchJob, error) {
+	if err := s.validateBatch(texts); err != nil {
+		return nil, err
+	}
+
+	job, err := s.batches.Create(ctx, userID, len(texts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	s.batches.Start(job, texts)
+	return job, nil
+}
+
+func validateHistoryFilter(filter *models.CheckHistoryFilter) error {
+	for _, level := range filter.DangerLevels {
+		if !slices.Contains(dangerLevels, level) {
+			return fmt.Errorf("%w: неизвестный уровень опасности %q", ErrInvalidHistoryFilter, level)
+		}
+	}
+	for _, status := range filter.Statuses {
+		if !slices.Contains(checkStatuses, status) {
+			return fmt.Errorf("%w: неизвестный статус %q", ErrInvalidHistoryFilter, status)
+		}
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return fmt.Errorf("%w: min_score больше max_score", ErrInvalidHistoryFilter)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: начало периода должно быть раньше конца", ErrInvalidHistoryFilter)
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = models.HistorySortCreatedAt
+		if filter.Query != "" {
+			filter.Sort = models.HistorySortRelevance
+		}
+	case models.HistorySortCreatedAt, models.HistorySortDangerScore, models.HistorySortProcessingTime:
+	case models.HistorySortRelevance:
+		if filter.Query == "" {
+			return fmt.Errorf("%w: сортировка по релевантности требует строку поиска", ErrInvalidHistoryFilter)
+		}
+	default:
+		return fmt.Errorf("%w: неизвестная сортировка %q", ErrInvalidHistoryFilter, filter.Sort)
+	}
+
+	return nil
+}
+
+func (s *AnalysisService) Stats(ctx context.Context, userID uint) (*models.UserStats, error) {
+	return s.checkRepo.GetUserStats(ctx, userID)
+}
+
+// StatsTimeSeries возвращает статистику проверок пользователя по интервалам.
+// Без границ берутся последние 30 дней, без часового пояса - UTC.
+func (s *AnalysisService) StatsTimeSeries(ctx context.Context, userID uint, query models.StatsTimeSeriesQuery) (*models.StatsTimeSeries, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultStatsPeriod)
+	}
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidStatsRange
+	}
+
+	var bucketSize time.Duration
+	switch query.Bucket {
+	case models.StatsBucketHour:
+		bucketSize = time.Hour
+	case models.StatsBucketDay, "":
+		query.Bucket, bucketSize = models.StatsBucketDay, 24*time.Hour
+	case models.StatsBucketWeek:
+		bucketSize = 7 * 24 * time.Hour
+	default:
+		return nil, ErrInvalidStatsBucket
+	}
+	if query.To.Sub(query.From)/bucketSize >= maxStatsBuckets {
+		return nil, ErrStatsRangeTooLong
+	}
+
+	if query.Timezone == "" {
+		query.Timezone = "UTC"
+	}
+	// Local понимает только Go, Postgres нужно имя IANA
+	if _, err := time.LoadLocation(query.Timezone); err != nil || query.Timezone == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+
+	if query.Top <= 0 {
+		query.Top = defaultStatsTopLength
+	}
+
+	return s.checkRepo.GetStatsTimeSeries(ctx, userID, query)
+}
+
+func (s *AnalysisService) Revisions(ctx context.Context, userID, checkID uint) ([]models.CheckRevision, error) {
+	revisions, err := s.checkRepo.ListRevisions(ctx, checkID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCheckNotFound
+	}
+	return revisions, err
+}
+
+func (s *AnalysisService) DeleteCheck(ctx context.Context, userID, checkID uint) error {
+	err := s.checkRepo.DeleteCheck(ctx, checkID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCheckNotFound
+	}
+	return err
+}
+
+// SubmitFeedback сохраняет мнение пользователя о том, было ли сообщение
+// мошенническим.
+func (s *AnalysisService) SubmitFeedback(ctx context.Context, userID, checkID uint, isScam bool) error {
+	if err := s.checkRepo.SetUserVerdict(ctx, checkID, userID, isScam); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCheckNotFound
+		}
+		return err
+	}
+
+	s.bus.Publish(ctx, events.FeedbackReceived(userID, checkID, isScam))
+	return nil
+}
+
+// AnalysisErrorCode - код ошибки анализа текста в тех же терминах, что и у
+// результатов пакета.
+func AnalysisErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrMLUnavailable), errors.Is(err, ErrMLTimeout),
+		errors.Is(err, ErrMLFailed), errors.Is(err, context.Canceled):
+		return batchErrorCode(err)
+	default:
+		return models.BatchErrorStorage
+	}
+}
+
+// batchItemError восстанавливает тип ошибки текста пакета по её коду.
+func batchItemError(item BatchItem) error {
+	switch item.ErrorCode {
+	case models.BatchErrorMLUnavailable:
+		return fmt.Errorf("%w: %w", ErrMLUnavailable, item.Err)
+	case models.BatchErrorMLTimeout:
+		return fmt.Errorf("%w: %w", ErrMLTimeout, item.Err)
+	case models.BatchErrorML, models.BatchErrorMissingPrediction:
+		return fmt.Errorf("%w: %w", ErrMLFailed, item.Err)
+	default:
+		return item.Err
+	}
+}
+
+// mlError относит ошибку ML клиента к одной из ошибок сервиса, сохраняя
+// исходную ошибку в цепочке.
+func mlError(err error) error {
+	switch {
+	case errors.Is(err, mlclient.ErrCircuitOpen):
+		return fmt.Errorf("%w: %w", ErrMLUnavailable, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrMLTimeout, err)
+	case errors.Is(err, context.Canceled):
+		return err
+	default:
+		return fmt.Errorf("%w: %w", ErrMLFailed, err)
+	}
+}
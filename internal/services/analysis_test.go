@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// recordedChecks запоминает, как AnalysisService сохраняет проверку.
+// completeErr имитирует сбой транзакции CompleteCheck.
+type recordedChecks struct {
+	repository.CheckRepository
+
+	created     []models.Check
+	statuses    []string
+	completed   []models.Check
+	details     [][]*models.CheckDetail
+	completeErr error
+}
+
+func (r *recordedChecks) CreateCheck(check *models.Check) error {
+	check.ID = uint(len(r.created) + 1)
+	r.created = append(r.created, *check)
+	return nil
+}
+
+func (r *recordedChecks) UpdateCheckStatus(id uint, status string, dangerScore float64, dangerLevel string, processingTime int) error {
+	r.statuses = append(r.statuses, status)
+	return nil
+}
+
+func (r *recordedChecks) CompleteCheck(ctx context.Context, check *models.Check, details []*models.CheckDetail) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	r.completed = append(r.completed, *check)
+	r.details = append(r.details, details)
+	return nil
+}
+
+func featureNames(details []*models.CheckDetail) map[string]int {
+	names := make(map[string]int)
+	for _, detail := range details {
+		names[detail.FeatureName]++
+	}
+	return names
+}
+
+func TestAnalysisServiceAnalyzeTextErrors(t *testing.T) {
+	const text = "Ваша карта заблокирована, перейдите по ссылке"
+
+	tests := []struct {
+		name    string
+		mlErr   error
+		cancel  bool
+		degrade bool
+		wantErr error
+		notErr  []error
+	}{
+		{name: "circuit open", mlErr: mlclient.ErrCircuitOpen, wantErr: ErrMLUnavailable},
+		{name: "deadline exceeded", mlErr: context.DeadlineExceeded, wantErr: ErrMLTimeout},
+		{name: "service error", mlErr: &mlclient.StatusError{StatusCode: 500}, wantErr: ErrMLFailed},
+		{
+			// Отменённый клиентом запрос - не сбой ML сервиса и не повод
+			// деградировать
+			name: "canceled by client", cancel: true, degrade: true, wantErr: context.Canceled,
+			notErr: []error{ErrMLUnavailable, ErrMLTimeout, ErrMLFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := mlclient.NewFakeAnalyzer()
+			analyzer.Err = tt.mlErr
+			checks := &recordedChecks{}
+			experiment := NewModelExperiment(nil, analyzer, nil, ExperimentConfig{})
+			service := NewAnalysisService(checks, experiment, nil, events.NewHub(0), tt.degrade)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			}
+			defer cancel()
+
+			result, err := service.AnalyzeText(ctx, 1, text)
+			if result != nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AnalyzeText = %v, %v; want error %v", result, err, tt.wantErr)
+			}
+			if tt.mlErr != nil && !errors.Is(err, tt.mlErr) {
+				t.Fatalf("error %v does not wrap the client error %v", err, tt.mlErr)
+			}
+			for _, notErr := range tt.notErr {
+				if errors.Is(err, notErr) {
+					t.Fatalf("error %v must not be %v", err, notErr)
+				}
+			}
+
+			if len(checks.created) != 1 || len(checks.completed) != 0 {
+				t.Fatalf("created %d checks, completed %d; want 1 and 0", len(checks.created), len(checks.completed))
+			}
+			if len(checks.statuses) != 1 || checks.statuses[0] != "failed" {
+				t.Fatalf("status updates = %v, want [failed]", checks.statuses)
+			}
+		})
+	}
+}
+
+func TestAnalysisServiceAnalyzeTextDegraded(t *testing.T) {
+	const text = "Ваша карта заблокирована, перейдите по ссылке"
+
+	analyzer := mlclient.NewFakeAnalyzer()
+	analyzer.Err = mlclient.ErrCircuitOpen
+	checks := &recordedChecks{}
+	hub := events.NewHub(0)
+	sub := hub.Subscribe(1)
+	defer sub.Close()
+
+	experiment := NewModelExperiment(nil, analyzer, nil, ExperimentConfig{})
+	service := NewAnalysisService(checks, experiment, nil, hub, true)
+
+	result, err := service.AnalyzeText(context.Background(), 1, text)
+	if err != nil {
+		t.Fatalf("AnalyzeText: %v", err)
+	}
+	want := RulePrediction(RuleScore(text))
+	if !result.Success || !result.Degraded || result.Prediction.Label != want.Label || result.Prediction.Confidence != want.Confidence {
+		t.Fatalf("result = %+v, want a degraded rules prediction", *result)
+	}
+
+	// Оценка правилами сохраняется одним CompleteCheck вместе с деталями,
+	// статус отдельно не обновляется
+	if len(checks.statuses) != 0 || len(checks.completed) != 1 {
+		t.Fatalf("status updates %v, completed %d; want none and 1", checks.statuses, len(checks.completed))
+	}
+	stored := checks.completed[0]
+	if !stored.Degraded || stored.ModelName != models.RulesModelName || stored.ModelVersion != models.RulesModelVersion {
+		t.Fatalf("stored check = %+v, want degraded and scored by rules", stored)
+	}
+	if stored.DangerScore != RuleScore(text) || stored.DangerLevel != DangerLevel(stored.DangerScore) {
+		t.Fatalf("stored score %v/%s, want rule score %v", stored.DangerScore, stored.DangerLevel, RuleScore(text))
+	}
+	names := featureNames(checks.details[0])
+	if names["rule_prediction"] != 1 || names[models.FeatureRuleMatch] == 0 || names["ml_prediction"] != 0 {
+		t.Fatalf("stored details = %v, want the rule prediction and rule matches", names)
+	}
+
+	var statuses []string
+	for len(sub.C) > 0 {
+		event := <-sub.C
+		data := event.Data.(events.CheckStatusData)
+		statuses = append(statuses, data.Status)
+		if data.Status == "completed" && !data.Degraded {
+			t.Fatal("completed event is not marked degraded")
+		}
+	}
+	if len(statuses) != 2 || statuses[0] != "processing" || statuses[1] != "completed" {
+		t.Fatalf("published statuses %v, want [processing completed]", statuses)
+	}
+}
+
+func TestAnalysisServiceAnalyzeTextStorageFailure(t *testing.T) {
+	storage := errors.New("connection reset")
+
+	for _, mlErr := range []error{nil, mlclient.ErrCircuitOpen} {
+		analyzer := mlclient.NewFakeAnalyzer()
+		analyzer.Err = mlErr
+		checks := &recordedChecks{completeErr: storage}
+		experiment := NewModelExperiment(nil, analyzer, nil, ExperimentConfig{})
+		service := NewAnalysisService(checks, experiment, nil, events.NewHub(0), true)
+
+		// Сбой сохранения результата - ошибка БД, а не ML сервиса
+		_, err := service.AnalyzeText(context.Background(), 1, "текст")
+		if !errors.Is(err, storage) || errors.Is(err, ErrMLUnavailable) || errors.Is(err, ErrMLFailed) {
+			t.Fatalf("ML error %v: AnalyzeText error = %v, want %v", mlErr, err, storage)
+		}
+	}
+}
+
+// seriesChecks запоминает запрос, дошедший до хранилища.
+type seriesChecks struct {
+	repository.CheckRepository
+	query *models.StatsTimeSeriesQuery
+}
+
+func (r *seriesChecks) GetStatsTimeSeries(ctx context.Context, userID uint, query models.StatsTimeSeriesQuery) (*models.StatsTimeSeries, error) {
+	r.query = &query
+	return &models.StatsTimeSeries{}, nil
+}
+
+func TestAnalysisServiceStatsTimeSeries(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   models.StatsTimeSeriesQuery
+		wantErr error
+		want    func(q models.StatsTimeSeriesQuery) bool
+	}{
+		{
+			name: "defaults",
+			want: func(q models.StatsTimeSeriesQuery) bool {
+				return q.Bucket == models.StatsBucketDay && q.Timezone == "UTC" && q.Top == defaultStatsTopLength &&
+					q.To.Sub(q.From) == defaultStatsPeriod
+			},
+		},
+		{
+			name:  "IANA timezone",
+			query: models.StatsTimeSeriesQuery{From: from, To: from.Add(48 * time.Hour), Bucket: models.StatsBucketHour, Timezone: "Europe/Moscow"},
+			want:  func(q models.StatsTimeSeriesQuery) bool { return q.Timezone == "Europe/Moscow" },
+		},
+		{name: "unknown timezone", query: models.StatsTimeSeriesQuery{Timezone: "Mars/Olympus_Mons"}, wantErr: ErrInvalidTimezone},
+		{name: "Go local timezone", query: models.StatsTimeSeriesQuery{Timezone: "Local"}, wantErr: ErrInvalidTimezone},
+		{name: "offset instead of name", query: models.StatsTimeSeriesQuery{Timezone: "+03:00"}, wantErr: ErrInvalidTimezone},
+		{name: "unknown bucket", query: models.StatsTimeSeriesQuery{Bucket: "month"}, wantErr: ErrInvalidStatsBucket},
+		{name: "empty range", query: models.StatsTimeSeriesQuery{From: from, To: from}, wantErr: ErrInvalidStatsRange},
+		{
+			name:    "too many buckets",
+			query:   models.StatsTimeSeriesQuery{From: from, To: from.Add(maxStatsBuckets * time.Hour), Bucket: models.StatsBucketHour},
+			wantErr: ErrStatsRangeTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := &seriesChecks{}
+			service := NewAnalysisService(checks, nil, nil, events.NewHub(0), false)
+
+			_, err := service.StatsTimeSeries(context.Background(), 1, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StatsTimeSeries error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if checks.query != nil {
+					t.Fatal("invalid query reached the repository")
+				}
+				return
+			}
+			if checks.query == nil || !tt.want(*checks.query) {
+				t.Fatalf("repository query = %+v", checks.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix      = "sdk_"
+	apiKeyPrefixShown = 12
+	maxAPIKeysPerUser = 20
+	maxAPIKeyName     = 128
+	// Время последнего использования обновляется не чаще раза в минуту,
+	// чтобы частые вызовы не писали в БД на каждый запрос
+	apiKeyUsageInterval = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound    = errors.New("ключ не найден")
+	ErrInvalidAPIKey     = errors.New("невалидный ключ")
+	ErrInvalidAPIKeyName = fmt.Errorf("название ключа должно быть от 1 до %d символов", maxAPIKeyName)
+	ErrTooManyAPIKeys    = fmt.Errorf("можно создать не больше %d ключей", maxAPIKeysPerUser)
+)
+
+// APIKeyService выдаёт ключи доступа к gRPC API и проверяет их. Ключ
+// действует от имени создавшего его пользователя.
+type APIKeyService struct {
+	repo         repository.APIKeyRepository
+	auditService AuditService
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, auditService AuditService) *APIKeyService {
+	return &APIKeyService{repo: repo, auditService: auditService}
+}
+
+// Create выпускает ключ и возвращает его. Ключ больше нигде не показывается.
+func (s *APIKeyService) Create(ctx context.Context, userID uint, name string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyName {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", err
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(secretBytes)
+
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  secret[:apiKeyPrefixShown],
+		KeyHash: hashToken(secret),
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	s.auditService.Record(ctx, models.AuditAPIKeyCreated, userID, map[string]interface{}{
+		"api_key_id": key.ID,
+		"name":       key.Name,
+	})
+	return key, secret, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Delete отзывает ключ: запросы с ним сразу перестают проходить.
+func (s *APIKeyService) Delete(ctx context.Context, userID, id uint) error {
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditAPIKeyRevoked, userID, map[string]interface{}{
+		"api_key_id": id,
+	})
+	return nil
+}
+
+// Authenticate возвращает пользователя, которому принадлежит ключ.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (uint, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return 0, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetActiveByHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidAPIKey
+		}
+		return 0, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUsageInterval {
+		if err := s.repo.MarkUsed(context.WithoutCancel(ctx), key.ID, now); err != nil {
+			log.Printf("api keys: %v", err)
+		}
+	}
+	return key.UserID, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type memoryAPIKeys struct {
+	repository.APIKeyRepository
+
+	keys   []*models.APIKey
+	nextID uint
+	used   int
+}
+
+func (m *memoryAPIKeys) Create(ctx context.Context, key *models.APIKey) error {
+	m.nextID++
+	key.ID = m.nextID
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *memoryAPIKeys) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var n int64
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memoryAPIKeys) Delete(ctx context.Context, id, userID uint) error {
+	for i, key := range m.keys {
+		if key.ID == id && key.UserID == userID {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *memoryAPIKeys) GetActiveByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == hash {
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryAPIKeys) MarkUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	m.used++
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	keys := &memoryAPIKeys{}
+	audit := &recordedAudit{}
+	service := NewAPIKeyService(keys, audit)
+	ctx := context.Background()
+
+	key, secret, err := service.Create(ctx, 7, "  ci  ")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if key.Name != "ci" || !strings.HasPrefix(secret, key.Prefix) || key.KeyHash == secret {
+		t.Fatalf("created key %+v for secret %q", key, secret)
+	}
+
+	for range 2 {
+		userID, err := service.Authenticate(ctx, secret)
+		if err != nil || userID != 7 {
+			t.Fatalf("Authenticate = %d, %v", userID, err)
+		}
+	}
+	if keys.used != 1 {
+		t.Fatalf("last use recorded %d times, want 1", keys.used)
+	}
+
+	if err := service.Delete(ctx, 8, key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("Delete by another user = %v, want %v", err, ErrAPIKeyNotFound)
+	}
+	if err := service.Delete(ctx, 7, key.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate revoked key = %v, want %v", err, ErrInvalidAPIKey)
+	}
+
+	want := []string{models.AuditAPIKeyCreated, models.AuditAPIKeyRevoked}
+	if strings.Join(audit.events, ",") != strings.Join(want, ",") {
+		t.Fatalf("audit events = %v, want %v", audit.events, want)
+	}
+}
+
+func TestAPIKeyCreateValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing int
+		keyName  string
+		wantErr  error
+	}{
+		{name: "valid", keyName: "ci"},
+		{name: "blank name", keyName: "   ", wantErr: ErrInvalidAPIKeyName},
+		{name: "name too long", keyName: strings.Repeat("к", maxAPIKeyName+1), wantErr: ErrInvalidAPIKeyName},
+		{name: "limit reached", existing: maxAPIKeysPerUser, keyName: "ci", wantErr: ErrTooManyAPIKeys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := &memoryAPIKeys{}
+			for range tt.existing {
+				keys.Create(context.Background(), &models.APIKey{UserID: 1})
+			}
+			service := NewAPIKeyService(keys, &recordedAudit{})
+
+			_, _, err := service.Create(context.Background(), 1, tt.keyName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticateRejectsForeignTokens(t *testing.T) {
+	service := NewAPIKeyService(&memoryAPIKeys{}, &recordedAudit{})
+
+	for _, secret := range []string{"", "Bearer access", "sdk_unknown"} {
+		if _, err := service.Authenticate(context.Background(), secret); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) = %v, want %v", secret, err, ErrInvalidAPIKey)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+)
+
+type requestMetaKey struct{}
+
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+}
+
+// WithRequestMeta сохраняет IP и User-Agent запроса в контексте, чтобы
+// сервисы могли записывать их в журнал аудита без зависимости от gin.
+func WithRequestMeta(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, RequestMeta{IPAddress: ip, UserAgent: userAgent})
+}
+
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *auditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record пишет событие в журнал. Ошибка записи только логируется: сбой
+// аудита не должен ломать вход или выход пользователя.
+func (s *auditService) Record(ctx context.Context, eventType string, userID uint, metadata map[string]interface{}) {
+	meta := RequestMetaFromContext(ctx)
+
+	event := &models.AuditEvent{
+		EventType: eventType,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+	}
+
+	if userID != 0 {
+		event.UserID = &userID
+		event.ActorID = &userID
+	}
+
+	if len(metadata) > 0 {
+		if encoded, err := json.Marshal(metadata); err == nil {
+			event.Metadata = string(encoded)
+		}
+	}
+
+	if err := s.auditRepo.Create(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("audit: не удалось записать событие %s: %v", eventType, err)
+	}
+}
+
+func (s *auditService) ListForUser(ctx context.Context, userID uint, limit, offset int) ([]models.AuditEvent, int64, error) {
+	return s.auditRepo.List(ctx, models.AuditEventFilter{UserID: &userID}, limit, offset)
+}
+
+func (s *auditService) List(ctx context.Context, filter models.AuditEventFilter, limit, offset int) ([]models.AuditEvent, int64, error) {
+	return s.auditRepo.List(ctx, filter, limit, offset)
+}
@@ -5,23 +5,35 @@ import (
 	"errors"
 	"scam-detection-backend/internal/crypto"
 	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/passwordpolicy"
 	"scam-detection-backend/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("неверные учётные данные")
 	ErrUserAlreadyExists  = errors.New("пользователь уже существует")
+	ErrWrongPassword      = errors.New("неверный текущий пароль")
+	ErrSamePassword       = errors.New("новый пароль совпадает с текущим")
+	ErrAccountDisabled    = errors.New("аккаунт деактивирован")
 )
 
 type AuthService struct {
 	userRepo       repository.UserRepository
 	sessionService SessionService
+	accounts       *AccountGuard
+	passwordPolicy *passwordpolicy.Policy
+	auditService   AuditService
 }
 
-func NewAuthService(userRepo repository.UserRepository, sessionService SessionService) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, sessionService SessionService, accounts *AccountGuard, passwordPolicy *passwordpolicy.Policy, auditService AuditService) *AuthService {
 	return &AuthService{
 		userRepo:       userRepo,
 		sessionService: sessionService,
+		accounts:       accounts,
+		passwordPolicy: passwordPolicy,
+		auditService:   auditService,
 	}
 }
 
@@ -38,6 +50,10 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 		}
 	}
 
+	if err := s.validatePassword(req.Password, req.Username, req.Email); err != nil {
+		return nil, nil, err
+	}
+
 	hashedPassword, err := crypto.HashPassword(req.Password)
 	if err != nil {
 		return nil, nil, err
@@ -59,24 +75,44 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 		return nil, nil, err
 	}
 
+	s.auditService.Record(ctx, models.AuditRegister, user.ID, nil)
+
 	return user, tokens, nil
 }
 
 func (s *AuthService) Login(ctx context.Context, username, password string) (*models.User, *models.TokenPair, error) {
 	user, err := s.userRepo.GetByUsernameOrEmail(username)
 	if err != nil {
+		s.auditService.Record(ctx, models.AuditLoginFailed, 0, map[string]interface{}{
+			"login":  username,
+			"reason": "user_not_found",
+		})
 		return nil, nil, ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
-		return nil, nil, errors.New("аккаунт деактивирован")
+		s.auditService.Record(ctx, models.AuditLoginFailed, user.ID, map[string]interface{}{
+			"reason": "account_disabled",
+		})
+		return nil, nil, ErrAccountDisabled
 	}
 
 	match, err := crypto.ComparePasswordAndHash(password, user.PasswordHash)
 	if err != nil || !match {
+		s.auditService.Record(ctx, models.AuditLoginFailed, user.ID, map[string]interface{}{
+			"reason": "invalid_password",
+		})
 		return nil, nil, ErrInvalidCredentials
 	}
 
+	if err := restorePendingDeletion(ctx, s.userRepo, s.auditService, user); err != nil {
+		// Аккаунт удалили между проверкой пароля и восстановлением
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
 	s.sessionService.CleanupExpiredSessions(ctx)
 
 	tokens, err := s.sessionService.GenerateSession(ctx, user.ID)
@@ -84,11 +120,22 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*mo
 		return nil, nil, err
 	}
 
+	s.auditService.Record(ctx, models.AuditLoginSuccess, user.ID, nil)
+
 	return user, tokens, nil
 }
 
+// ValidateToken проверяет access токен и то, что его владелец по-прежнему
+// может работать с API.
 func (s *AuthService) ValidateToken(token string) (uint, error) {
-	return s.sessionService.ValidateAccessToken(token)
+	userID, err := s.sessionService.ValidateAccessToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.accounts.Check(userID); err != nil {
+		return 0, err
+	}
+	return userID, nil
 }
 
 func (s *AuthService) GetUserIDFromRefreshToken(refreshToken string) (uint, error) {
@@ -100,5 +147,58 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 }
 
 func (s *AuthService) LogoutAllDevices(ctx context.Context, userID uint) error {
-	return s.sessionService.InvalidateAllUserSessions(ctx, userID)
+	if err := s.sessionService.InvalidateAllUserSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditLogout, userID, nil)
+
+	return nil
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, userID uint, req *models.UpdatePasswordRequest) (*models.TokenPair, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пользователи, созданные через внешнего провайдера, задают пароль впервые
+	if user.HasPassword {
+		match, err := crypto.ComparePasswordAndHash(req.CurrentPassword, user.PasswordHash)
+		if err != nil || !match {
+			return nil, ErrWrongPassword
+		}
+
+		if req.CurrentPassword == req.NewPassword {
+			return nil, ErrSamePassword
+		}
+	}
+
+	if err := s.validatePassword(req.NewPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.InvalidateAllUserSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditPasswordChanged, userID, nil)
+
+	return s.sessionService.GenerateSession(ctx, userID)
+}
+
+func (s *AuthService) validatePassword(password, username string, email *string) error {
+	if s.passwordPolicy == nil {
+		return nil
+	}
+	return s.passwordPolicy.Validate(password, username, email)
 }
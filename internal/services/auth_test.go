@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/crypto"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/passwordpolicy"
+	"testing"
+)
+
+func (r *memoryUsers) Create(user *models.User) error {
+	r.add(user)
+	return nil
+}
+
+func (r *memoryUsers) UpdatePassword(id uint, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].PasswordHash = passwordHash
+	return nil
+}
+
+func testPasswordPolicy() *passwordpolicy.Policy {
+	return passwordpolicy.New(passwordpolicy.Config{
+		MinLength:      10,
+		RequireDigit:   true,
+		MinStrength:    3,
+		ForbidIdentity: true,
+	}, nil)
+}
+
+func assertViolation(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var violationErr *passwordpolicy.ViolationError
+	if !errors.As(err, &violationErr) {
+		t.Fatalf("error = %v, want a password policy violation", err)
+	}
+	for _, v := range violationErr.Violations {
+		if v.Code == code {
+			return
+		}
+	}
+	t.Fatalf("violations = %+v, want %s", violationErr.Violations, code)
+}
+
+func TestAuthServiceRegisterPasswordPolicy(t *testing.T) {
+	email := "ivan@example.com"
+
+	tests := []struct {
+		name     string
+		password string
+		wantCode string
+	}{
+		{name: "accepted", password: "Tq7#vLm2$Rz9"},
+		{name: "too short", password: "Tq7#vL", wantCode: passwordpolicy.CodeTooShort},
+		{name: "no digit", password: "Tqx#vLmw$Rzk", wantCode: passwordpolicy.CodeMissingDigit},
+		{name: "contains username", password: "ivanovich-2024", wantCode: passwordpolicy.CodeContainsUsername},
+		{name: "too weak", password: "password123", wantCode: passwordpolicy.CodeTooWeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMemoryUsers()
+			service := NewAuthService(users, stubSessions{}, NewAccountGuard(users), testPasswordPolicy(), &recordedAudit{})
+
+			user, _, err := service.Register(context.Background(), &models.CreateUserRequest{
+				Username: "ivanovich",
+				Email:    &email,
+				Password: tt.password,
+			})
+			if tt.wantCode == "" {
+				if err != nil || user == nil {
+					t.Fatalf("Register = %v, %v", user, err)
+				}
+				return
+			}
+			assertViolation(t, err, tt.wantCode)
+			if len(users.users) != 0 {
+				t.Fatal("user created with a rejected password")
+			}
+		})
+	}
+}
+
+func TestAuthServiceChangePasswordPolicy(t *testing.T) {
+	const current = "Old-Pa55word#x"
+	hash, err := crypto.HashPassword(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hasPassword bool
+		current     string
+		newPassword string
+		wantErr     error
+		wantCode    string
+	}{
+		{name: "wrong current password", hasPassword: true, current: "wrong", newPassword: "Tq7#vLm2$Rz9", wantErr: ErrWrongPassword},
+		{name: "same password", hasPassword: true, current: current, newPassword: current, wantErr: ErrSamePassword},
+		{name: "too short", hasPassword: true, current: current, newPassword: "Tq7#", wantCode: passwordpolicy.CodeTooShort},
+		{name: "contains username", hasPassword: true, current: current, newPassword: "ivanovich-2024", wantCode: passwordpolicy.CodeContainsUsername},
+		// Пароль, заданный впервые после входа через провайдера, проходит ту же политику
+		{name: "first password too weak", newPassword: "qwerty12345", wantCode: passwordpolicy.CodeTooWeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMemoryUsers(&models.User{ID: 1, Username: "ivanovich", PasswordHash: hash, HasPassword: tt.hasPassword})
+			service := NewAuthService(users, stubSessions{}, NewAccountGuard(users), testPasswordPolicy(), &recordedAudit{})
+
+			_, err := service.ChangePassword(context.Background(), 1, &models.UpdatePasswordRequest{
+				CurrentPassword: tt.current,
+				NewPassword:     tt.newPassword,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ChangePassword error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				assertViolation(t, err, tt.wantCode)
+			}
+			if users.users[1].PasswordHash != hash {
+				t.Fatal("password changed despite the error")
+			}
+		})
+	}
+}
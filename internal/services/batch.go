@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrBatchNotFound = errors.New("пакет не найден")
+
+type BatchConfig struct {
+	// MaxTexts - максимальный размер пакета
+	MaxTexts int
+	// ChunkSize - число текстов в одном запросе к ML сервису
+	ChunkSize int
+	// Workers - сколько частей пакетов обрабатывается одновременно во всём
+	// сервисе
+	Workers int
+	// DegradeOnFailure - оценивать часть правилами, если ML сервис недоступен
+	DegradeOnFailure bool
+}
+
+// BatchItem - результат анализа одного текста пакета.
+type BatchItem struct {
+	Index int
+	// Status - models.BatchItemOK, BatchItemDegraded или BatchItemFailed
+	Status     string
+	CheckID    uint
+	Prediction mlclient.PredictionResult
+	ErrorCode  string
+	Err        error
+}
+
+// BatchService разбивает пакет на части размером ChunkSize и обрабатывает
+// их параллельно. Проверки каждой части сохраняются одной транзакцией,
+// прогресс задачи обновляется по мере завершения частей.
+type BatchService struct {
+	batchRepo  repository.BatchJobRepository
+	experiment *ModelExperiment
+	bus        events.Bus
+	cfg        BatchConfig
+	workers    chan struct{}
+
+	mu      sync.Mutex
+	changed map[uint]chan struct{}
+}
+
+func NewBatchService(batchRepo repository.BatchJobRepository, experiment *ModelExperiment, bus events.Bus, cfg BatchConfig) *BatchService {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 100
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.MaxTexts < cfg.ChunkSize {
+		cfg.MaxTexts = cfg.ChunkSize
+	}
+
+	return &BatchService{
+		batchRepo:  batchRepo,
+		experiment: experiment,
+		bus:        bus,
+		cfg:        cfg,
+		workers:    make(chan struct{}, cfg.Workers),
+		changed:    make(map[uint]chan struct{}),
+	}
+}
+
+func (s *BatchService) MaxTexts() int {
+	return s.cfg.MaxTexts
+}
+
+func (s *BatchService) ChunkSize() int {
+	return s.cfg.ChunkSize
+}
+
+// RecoverInterrupted закрывает пакеты, оставшиеся незавершёнными после
+// перезапуска сервера.
+func (s *BatchService) RecoverInterrupted(ctx context.Context) {
+	if n, err := s.batchRepo.FailInterrupted(ctx, time.Now()); err != nil {
+		log.Printf("batch: %v", err)
+	} else if n > 0 {
+		log.Printf("batch: прервано перезапуском пакетов: %d", n)
+	}
+}
+
+// Create регистрирует пакет. Обработку запускает Run (синхронно) или Start.
+func (s *BatchService) Create(ctx context.Context, userID uint, total int) (*models.BatchJob, error) {
+	job := &models.BatchJob{
+		UserID: userID,
+		Status: models.BatchPending,
+		Total:  total,
+	}
+	if err := s.batchRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.notify(job.ID, false)
+	return job, nil
+}
+
+// Start обрабатывает пакет в фоне, независимо от запроса, который его создал.
+func (s *BatchService) Start(job *models.BatchJob, texts []string) {
+	go s.Run(context.Background(), job, texts)
+}
+
+func (s *BatchService) Get(ctx context.Context, userID, id uint) (*models.BatchJob, error) {
+	job, err := s.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, ErrBatchNotFound
+	}
+	return job, nil
+}
+
+// Changes возвращает канал, который закрывается, когда у пакета появляются
+// новые результаты или он завершается. Для пакетов, которые не
+// обрабатываются этим процессом, возвращается nil: ожидающие должны также
+// периодически перечитывать пакет.
+func (s *BatchService) Changes(id uint) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changed[id]
+}
+
+func (s *BatchService) notify(id uint, done bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.changed[id]; ok {
+		close(ch)
+		delete(s.changed, id)
+	}
+	if !done {
+		s.changed[id] = make(chan struct{})
+	}
+}
+
+// ListItems возвращает результаты пакета в порядке записи, начиная после
+// результата с номером afterSeq.
+func (s *BatchService) ListItems(ctx context.Context, userID, id, afterSeq uint, limit int) ([]models.BatchItem, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.batchRepo.ListItems(ctx, id, afterSeq, limit)
+}
+
+// Run обрабатывает пакет и возвращает результат для каждого текста в
+// исходном порядке. Отмена ctx прекращает выдачу новых частей, уже
+// отправленные в ML сервис дорабатываются.
+func (s *BatchService) Run(ctx context.Context, job *models.BatchJob, texts []string) []BatchItem {
+	startedAt := time.Now()
+	job.Status = models.BatchRunning
+	job.StartedAt = &startedAt
+	s.saveJob(ctx, job)
+	s.notify(job.ID, false)
+
+	analyzer, variant := s.experiment.AnalyzerFor(job.UserID)
+	items := make([]BatchItem, len(texts))
+	for i := range items {
+		items[i].Index = i
+	}
+
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.cfg.Workers, (len(texts)+s.cfg.ChunkSize-1)/s.cfg.ChunkSize) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range chunks {
+				end := min(offset+s.cfg.ChunkSize, len(texts))
+				s.processChunk(ctx, job, analyzer, variant, texts[offset:end], items[offset:end])
+			}
+		}()
+	}
+
+	var runErr error
+feed:
+	for offset := 0; offset < len(texts); offset += s.cfg.ChunkSize {
+		select {
+		case chunks <- offset:
+		case <-ctx.Done():
+			runErr = ctx.Err()
+			for i := offset; i < len(texts); i++ {
+				items[i].fail(models.BatchErrorCanceled, runErr)
+			}
+			s.saveChunk(job.ID, items[offset:], nil, nil)
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = models.BatchCompleted
+	if runErr != nil {
+		job.Status = models.BatchFailed
+		job.Error = runErr.Error()
+	}
+	s.saveJob(context.WithoutCancel(ctx), job)
+	s.notify(job.ID, true)
+	s.publishProgress(job.ID)
+
+	return items
+}
+
+// processChunk анализирует часть пакета. Ошибка ML сервиса затрагивает всю
+// часть (или, при DegradeOnFailure, переводит её на правила); тексты, для
+// которых сервис не вернул предсказание, завершаются ошибкой по отдельности.
+func (s *BatchService) processChunk(ctx context.Context, job *models.BatchJob, analyzer mlclient.Analyzer, variant string, texts []string, items []BatchItem) {
+	s.workers <- struct{}{}
+	defer func() { <-s.workers }()
+
+	start := time.Now()
+	result, err := analyzer.AnalyzeBatch(ctx, texts)
+	latency := time.Since(start)
+
+	degraded := err != nil
+	if degraded && (!s.cfg.DegradeOnFailure || ctx.Err() != nil) {
+		for i := range items {
+			items[i].fail(batchErrorCode(err), err)
+		}
+		s.saveChunk(job.ID, items, nil, nil)
+		return
+	}
+
+	// ML сервис анализирует часть одним запросом и не сообщает время
+	// отдельных текстов, поэтому время проверки - оценка: доля запроса,
+	// как у ModelExperiment.RecordBatch. Время всего запроса исказило бы
+	// среднее время анализа в статистике
+	itemTime := int(latency.Milliseconds()) / len(texts)
+
+	checks := make([]*models.Check, len(texts))
+	details := make([][]*models.CheckDetail, len(texts))
+	var servedIdxs []int
+	for i, text := range texts {
+		index := items[i].Index
+		check := &models.Check{
+			Title:          checkTitle(text),
+			ContentType:    "text",
+			Content:        text,
+			Status:         "completed",
+			UserID:         job.UserID,
+			BatchID:        &job.ID,
+			BatchIndex:     &index,
+			ProcessingTime: itemTime,
+		}
+
+		var pred mlclient.PredictionResult
+		switch {
+		case degraded:
+			ruleScore := RuleScore(text)
+			pred = RulePrediction(ruleScore)
+			check.Degraded = true
+			check.DangerScore = ruleScore
+			check.ModelName, check.ModelVersion = models.RulesModelName, models.RulesModelVersion
+			details[i] = append([]*models.CheckDetail{RuleDetail(0, ruleScore, pred)}, RuleMatchDetails(0, text)...)
+			items[i].Status = models.BatchItemDegraded
+		case i < len(result.Predictions):
+			pred = result.Predictions[i]
+			check.DangerScore = CombinedScore(pred, text)
+			check.ModelName, check.ModelVersion = pred.ModelName, pred.ModelVersion
+			details[i] = append(PredictionDetails(0, pred), RuleMatchDetails(0, text)...)
+			if pred.Cached {
+				check.ProcessingTime = 0
+			}
+			items[i].Status = models.BatchItemOK
+			servedIdxs = append(servedIdxs, i)
+		default:
+			items[i].fail(models.BatchErrorMissingPrediction,
+				fmt.Errorf("ML service returned %d predictions for %d texts", len(result.Predictions), len(texts)))
+			continue
+		}
+
+		check.DangerLevel = DangerLevel(check.DangerScore)
+		checks[i] = check
+		items[i].Prediction = pred
+	}
+
+	if err := s.saveChunk(job.ID, items, checks, details); err != nil {
+		for i := range items {
+			items[i].fail(models.BatchErrorStorage, err)
+		}
+		// Результаты всё же пытаемся записать, чтобы ошибки были видны в
+		// списке результатов пакета
+		s.saveChunk(job.ID, items, nil, nil)
+		return
+	}
+
+	for i, check := range checks {
+		if check != nil {
+			items[i].CheckID = check.ID
+			s.bus.Publish(ctx, events.CheckStatusChanged(check))
+		}
+	}
+
+	if len(servedIdxs) > 0 {
+		checkIDs := make([]uint, len(servedIdxs))
+		servedTexts := make([]string, len(servedIdxs))
+		preds := make([]mlclient.PredictionResult, len(servedIdxs))
+		for j, i := range servedIdxs {
+			checkIDs[j], servedTexts[j], preds[j] = checks[i].ID, texts[i], items[i].Prediction
+		}
+		s.experiment.RecordBatch(ctx, checkIDs, servedTexts, variant, preds, latency)
+	}
+}
+
+func (s *BatchService) saveChunk(jobID uint, items []BatchItem, checks []*models.Check, details [][]*models.CheckDetail) error {
+	rows := make([]*models.BatchItem, len(items))
+	for i, item := range items {
+		rows[i] = &models.BatchItem{
+			Index:     item.Index,
+			Status:    item.Status,
+			ErrorCode: item.ErrorCode,
+		}
+		if item.Err != nil {
+			rows[i].Error = item.Err.Error()
+		}
+		if i < len(checks) && checks[i] != nil {
+			rows[i].DangerScore = checks[i].DangerScore
+			rows[i].DangerLevel = checks[i].DangerLevel
+		}
+	}
+
+	// Сохраняем и после отмены запроса: часть уже обработана ML сервисом
+	err := s.batchRepo.SaveChunk(context.Background(), jobID, rows, checks, details)
+	if err != nil {
+		log.Printf("batch %d: %v", jobID, err)
+		return err
+	}
+
+	s.notify(jobID, false)
+	s.publishProgress(jobID)
+	return nil
+}
+
+func (s *BatchService) publishProgress(jobID uint) {
+	job, err := s.batchRepo.GetByID(context.Background(), jobID)
+	if err != nil {
+		log.Printf("batch %d: %v", jobID, err)
+		return
+	}
+	s.bus.Publish(context.Background(), events.BatchChanged(job))
+}
+
+func (item *BatchItem) fail(code string, err error) {
+	item.Status = models.BatchItemFailed
+	item.ErrorCode = code
+	item.Err = err
+}
+
+// batchErrorCode переводит ошибку ML сервиса в код результата текста.
+func batchErrorCode(err error) string {
+	switch {
+	case errors.Is(err, mlclient.ErrCircuitOpen):
+		return models.BatchErrorMLUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.BatchErrorMLTimeout
+	case errors.Is(err, context.Canceled):
+		return models.BatchErrorCanceled
+	default:
+		return models.BatchErrorML
+	}
+}
+
+func (s *BatchService) saveJob(ctx context.Context, job *models.BatchJob) {
+	if err := s.batchRepo.Update(ctx, job); err != nil {
+		log.Printf("batch %d: %v", job.ID, err)
+	}
+}
+
+func checkTitle(text string) string {
+	if runes := []rune(text); len(runes) > 50 {
+		return string(runes[:50])
+	}
+	return text
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBatches хранит пакеты в памяти. saveErr вызывается перед
+// сохранением части: ошибка откатывает часть целиком, как транзакция
+// SaveChunk.
+type memoryBatches struct {
+	repository.BatchJobRepository
+
+	mu      sync.Mutex
+	nextID  uint
+	jobs    map[uint]*models.BatchJob
+	items   []models.BatchItem
+	checks  []*models.Check
+	saveErr func(checks []*models.Check, details [][]*models.CheckDetail) error
+}
+
+func newMemoryBatches() *memoryBatches {
+	return &memoryBatches{jobs: make(map[uint]*models.BatchJob)}
+}
+
+func (r *memoryBatches) Create(ctx context.Context, job *models.BatchJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	job.ID = r.nextID
+	stored := *job
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+func (r *memoryBatches) Update(ctx context.Context, job *models.BatchJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.jobs[job.ID]
+	stored.Status, stored.Error = job.Status, job.Error
+	stored.StartedAt, stored.FinishedAt = job.StartedAt, job.FinishedAt
+	return nil
+}
+
+func (r *memoryBatches) GetByID(ctx context.Context, id uint) (*models.BatchJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := *r.jobs[id]
+	return &job, nil
+}
+
+func (r *memoryBatches) SaveChunk(ctx context.Context, jobID uint, items []*models.BatchItem, checks []*models.Check, details [][]*models.CheckDetail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.saveErr != nil {
+		if err := r.saveErr(checks, details); err != nil {
+			return err
+		}
+	}
+
+	job := r.jobs[jobID]
+	for i, item := range items {
+		if i < len(checks) && checks[i] != nil {
+			r.nextID++
+			checks[i].ID = r.nextID
+			r.checks = append(r.checks, checks[i])
+			item.CheckID = &checks[i].ID
+		}
+
+		r.nextID++
+		item.ID = r.nextID
+		item.BatchID = jobID
+		r.items = append(r.items, *item)
+
+		switch item.Status {
+		case models.BatchItemFailed:
+			job.Failed++
+		case models.BatchItemDegraded:
+			job.Degraded++
+			job.Processed++
+		default:
+			job.Processed++
+		}
+	}
+	return nil
+}
+
+// storedItem - последняя запись результата текста index.
+func (r *memoryBatches) storedItem(index int) (models.BatchItem, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.items) - 1; i >= 0; i-- {
+		if r.items[i].Index == index {
+			return r.items[i], true
+		}
+	}
+	return models.BatchItem{}, false
+}
+
+// failChecksOf отклоняет часть, в которой есть проверка текста.
+func failChecksOf(text string) func([]*models.Check, [][]*models.CheckDetail) error {
+	return func(checks []*models.Check, details [][]*models.CheckDetail) error {
+		for _, check := range checks {
+			if check != nil && check.Content == text {
+				return errors.New("insert checks: connection reset")
+			}
+		}
+		return nil
+	}
+}
+
+// failDetailsOf отклоняет часть, в которой есть детали проверки текста.
+func failDetailsOf(text string) func([]*models.Check, [][]*models.CheckDetail) error {
+	return func(checks []*models.Check, details [][]*models.CheckDetail) error {
+		for i, check := range checks {
+			if check != nil && check.Content == text && len(details[i]) > 0 {
+				return errors.New("insert check details: connection reset")
+			}
+		}
+		return nil
+	}
+}
+
+func newTestAnalysisService(analyzer mlclient.Analyzer, batches *memoryBatches, chunkSize int) *AnalysisService {
+	experiment := NewModelExperiment(newSavedEvaluations(), analyzer, nil, ExperimentConfig{})
+	bus := events.NewHub(0)
+	batchService := NewBatchService(batches, experiment, bus, BatchConfig{MaxTexts: 100, ChunkSize: chunkSize, Workers: 2})
+	return NewAnalysisService(nil, experiment, batchService, bus, false)
+}
+
+func TestAnalysisServiceAnalyzeBatch(t *testing.T) {
+	texts := []string{
+		"Привет, как дела?",
+		"Ваша карта заблокирована",
+		"Встреча перенесена на завтра",
+		"Вы выиграли приз, переведите комиссию",
+	}
+
+	tests := []struct {
+		name       string
+		analyzer   func() mlclient.Analyzer
+		saveErr    func([]*models.Check, [][]*models.CheckDetail) error
+		wantStatus []string
+		wantCodes  []string
+		wantErr    error
+		wantResult func(result *BatchAnalysis) bool
+	}{
+		{
+			name:       "all texts processed",
+			analyzer:   func() mlclient.Analyzer { return mlclient.NewFakeAnalyzer() },
+			wantStatus: []string{models.BatchItemOK, models.BatchItemOK, models.BatchItemOK, models.BatchItemOK},
+			wantCodes:  []string{"", "", "", ""},
+			wantResult: func(r *BatchAnalysis) bool { return r.Err == nil && !r.Partial() && r.Succeeded == 4 },
+		},
+		{
+			name:       "failed check insert fails its chunk only",
+			analyzer:   func() mlclient.Analyzer { return mlclient.NewFakeAnalyzer() },
+			saveErr:    failChecksOf(texts[2]),
+			wantStatus: []string{models.BatchItemOK, models.BatchItemOK, models.BatchItemFailed, models.BatchItemFailed},
+			wantCodes:  []string{"", "", models.BatchErrorStorage, models.BatchErrorStorage},
+			wantResult: func(r *BatchAnalysis) bool { return r.Partial() && r.Succeeded == 2 },
+		},
+		{
+			name:       "failed detail insert fails its chunk only",
+			analyzer:   func() mlclient.Analyzer { return mlclient.NewFakeAnalyzer() },
+			saveErr:    failDetailsOf(texts[1]),
+			wantStatus: []string{models.BatchItemFailed, models.BatchItemFailed, models.BatchItemOK, models.BatchItemOK},
+			wantCodes:  []string{models.BatchErrorStorage, models.BatchErrorStorage, "", ""},
+			wantResult: func(r *BatchAnalysis) bool { return r.Partial() && r.Succeeded == 2 },
+		},
+		{
+			name:       "missing predictions fail only their texts",
+			analyzer:   func() mlclient.Analyzer { return shortBatchAnalyzer{mlclient.NewFakeAnalyzer()} },
+			wantStatus: []string{models.BatchItemOK, models.BatchItemFailed, models.BatchItemOK, models.BatchItemFailed},
+			wantCodes:  []string{"", models.BatchErrorMissingPrediction, "", models.BatchErrorMissingPrediction},
+			wantErr:    ErrMLFailed,
+			wantResult: func(r *BatchAnalysis) bool { return r.Partial() && r.Succeeded == 2 },
+		},
+		{
+			name: "ML service failure fails every text",
+			analyzer: func() mlclient.Analyzer {
+				fake := mlclient.NewFakeAnalyzer()
+				fake.Err = errors.New("internal server error")
+				return fake
+			},
+			wantStatus: []string{models.BatchItemFailed, models.BatchItemFailed, models.BatchItemFailed, models.BatchItemFailed},
+			wantCodes:  []string{models.BatchErrorML, models.BatchErrorML, models.BatchErrorML, models.BatchErrorML},
+			wantErr:    ErrMLFailed,
+			wantResult: func(r *BatchAnalysis) bool { return !r.Partial() && r.Succeeded == 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := newMemoryBatches()
+			batches.saveErr = tt.saveErr
+			service := newTestAnalysisService(tt.analyzer(), batches, 2)
+
+			result, err := service.AnalyzeBatch(context.Background(), 7, texts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantResult(result) {
+				t.Fatalf("result: succeeded = %d, partial = %v, err = %v", result.Succeeded, result.Partial(), result.Err)
+			}
+			if tt.wantErr != nil && !errors.Is(result.Err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", result.Err, tt.wantErr)
+			}
+			if len(result.Items) != len(texts) {
+				t.Fatalf("items = %d, want %d", len(result.Items), len(texts))
+			}
+
+			failed := 0
+			for i, item := range result.Items {
+				if item.Index != i || item.Status != tt.wantStatus[i] || item.ErrorCode != tt.wantCodes[i] {
+					t.Fatalf("item %d = {index %d, status %s, code %s}, want {%d, %s, %s}",
+						i, item.Index, item.Status, item.ErrorCode, i, tt.wantStatus[i], tt.wantCodes[i])
+				}
+				if (item.Status == models.BatchItemOK) != (item.CheckID != 0) {
+					t.Fatalf("item %d status %s with check %d", i, item.Status, item.CheckID)
+				}
+				if item.Status == models.BatchItemFailed {
+					failed++
+					if item.Err == nil {
+						t.Fatalf("failed item %d has no error", i)
+					}
+				}
+
+				// Результат каждого текста, в том числе неудавшегося,
+				// записан в пакет
+				stored, ok := batches.storedItem(i)
+				if !ok || stored.Status != item.Status || stored.ErrorCode != item.ErrorCode {
+					t.Fatalf("stored item %d = %+v, %v, want status %s", i, stored, ok, item.Status)
+				}
+			}
+
+			job, _ := batches.GetByID(context.Background(), result.Job.ID)
+			if job.Status != models.BatchCompleted || job.Failed != failed || job.Processed != len(texts)-failed {
+				t.Fatalf("job = %s, processed %d, failed %d", job.Status, job.Processed, job.Failed)
+			}
+		})
+	}
+}
+
+func TestAnalysisServiceAnalyzeBatchCanceled(t *testing.T) {
+	batches := newMemoryBatches()
+	service := newTestAnalysisService(mlclient.NewFakeAnalyzer(), batches, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := service.AnalyzeBatch(ctx, 7, []string{"первый", "второй", "третий"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Succeeded != 0 || !errors.Is(result.Err, context.Canceled) {
+		t.Fatalf("result: succeeded = %d, err = %v", result.Succeeded, result.Err)
+	}
+	for i, item := range result.Items {
+		if item.Status != models.BatchItemFailed || item.ErrorCode != models.BatchErrorCanceled {
+			t.Fatalf("item %d = %s/%s, want failed/canceled", i, item.Status, item.ErrorCode)
+		}
+	}
+}
+
+// slowBatchAnalyzer отвечает на пакет не раньше чем через delay.
+type slowBatchAnalyzer struct {
+	*mlclient.FakeAnalyzer
+	delay time.Duration
+}
+
+func (a slowBatchAnalyzer) AnalyzeBatch(ctx context.Context, texts []string) (*mlclient.BatchTextAnalysisResponse, error) {
+	time.Sleep(a.delay)
+	return a.FakeAnalyzer.AnalyzeBatch(ctx, texts)
+}
+
+func TestAnalysisServiceAnalyzeBatchSplitsChunkTime(t *testing.T) {
+	const delay = 100 * time.Millisecond
+
+	batches := newMemoryBatches()
+	service := newTestAnalysisService(slowBatchAnalyzer{mlclient.NewFakeAnalyzer(), delay}, batches, 4)
+
+	texts := []string{"первый", "второй", "третий", "четвёртый"}
+	if _, err := service.AnalyzeBatch(context.Background(), 7, texts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Время части делится между её текстами, а не записывается каждому:
+	// иначе среднее время анализа выросло бы в размер части
+	total := 0
+	for _, check := range batches.checks {
+		if check.ProcessingTime >= int(delay.Milliseconds()) {
+			t.Errorf("check %q: processing time %d ms is the whole chunk latency", check.Content, check.ProcessingTime)
+		}
+		total += check.ProcessingTime
+	}
+	if len(batches.checks) != len(texts) || total < int(delay.Milliseconds()) {
+		t.Fatalf("%d checks with total processing time %d ms, want %d checks sharing at least %v", len(batches.checks), total, len(texts), delay)
+	}
+}
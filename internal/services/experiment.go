@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"strconv"
+	"time"
+)
+
+// Ограничение на число одновременных теневых запросов: при перегрузке
+// теневой прогон пропускается, а не копится в памяти.
+const maxShadowInFlight = 32
+
+type ExperimentConfig struct {
+	LiveModel      string
+	CandidateModel string
+	// TrafficPercent - доля пользователей (0-100), которым отвечает кандидат
+	TrafficPercent int
+	// Shadow - прогонять асинхронно вторую модель для каждой проверки
+	Shadow        bool
+	ShadowTimeout time.Duration
+}
+
+// ModelExperiment распределяет пользователей между live и candidate моделями
+// и сохраняет их предсказания для отчёта сравнения. Без кандидата все методы
+// сводятся к использованию live модели.
+type ModelExperiment struct {
+	live      mlclient.Analyzer
+	candidate mlclient.Analyzer
+	cfg       ExperimentConfig
+	evalRepo  repository.ModelEvaluationRepository
+	shadowSem chan struct{}
+}
+
+func NewModelExperiment(evalRepo repository.ModelEvaluationRepository, live, candidate mlclient.Analyzer, cfg ExperimentConfig) *ModelExperiment {
+	if cfg.ShadowTimeout <= 0 {
+		cfg.ShadowTimeout = 30 * time.Second
+	}
+	cfg.TrafficPercent = min(max(cfg.TrafficPercent, 0), 100)
+
+	return &ModelExperiment{
+		live:      live,
+		candidate: candidate,
+		cfg:       cfg,
+		evalRepo:  evalRepo,
+		shadowSem: make(chan struct{}, maxShadowInFlight),
+	}
+}
+
+func (e *ModelExperiment) Enabled() bool {
+	return e.candidate != nil
+}
+
+// AnalyzerFor выбирает модель для пользователя. Распределение стабильно:
+// пользователь всегда попадает в одну и ту же группу при неизменном проценте.
+func (e *ModelExperiment) AnalyzerFor(userID uint) (mlclient.Analyzer, string) {
+	if e.Enabled() && userBucket(userID) < e.cfg.TrafficPercent {
+		return e.candidate, models.VariantCandidate
+	}
+	return e.live, models.VariantLive
+}
+
+// Record сохраняет предсказание, которое получил пользователь, и запускает
+// теневой прогон второй модели. Не блокирует ответ пользователю.
+func (e *ModelExperiment) Record(ctx context.Context, checkID uint, text, variant string, pred mlclient.PredictionResult, latency time.Duration) {
+	e.RecordBatch(ctx, []uint{checkID}, []string{text}, variant, []mlclient.PredictionResult{pred}, latency)
+}
+
+func (e *ModelExperiment) RecordBatch(ctx context.Context, checkIDs []uint, texts []string, variant string, preds []mlclient.PredictionResult, latency time.Duration) {
+	if !e.Enabled() || len(checkIDs) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	perItem := int(latency.Milliseconds()) / len(checkIDs)
+
+	served := make([]*models.ModelEvaluation, 0, len(checkIDs))
+	for i, checkID := range checkIDs {
+		served = append(served, e.evaluation(checkID, texts[i], variant, true, preds[i], perItem))
+	}
+
+	if !e.cfg.Shadow {
+		go e.save(ctx, served)
+		return
+	}
+
+	select {
+	case e.shadowSem <- struct{}{}:
+	default:
+		log.Printf("model experiment: теневой прогон пропущен, слишком много запросов в обработке")
+		go e.save(ctx, served)
+		return
+	}
+
+	go func() {
+		defer func() { <-e.shadowSem }()
+		e.save(ctx, append(served, e.runShadow(ctx, checkIDs, texts, variant)...))
+	}()
+}
+
+func (e *ModelExperiment) Report(ctx context.Context, since time.Time) (*models.ModelComparisonReport, error) {
+	return e.evalRepo.Compare(ctx, since)
+}
+
+func (e *ModelExperiment) runShadow(ctx context.Context, checkIDs []uint, texts []string, servedVariant string) []*models.ModelEvaluation {
+	analyzer, variant := e.candidate, models.VariantCandidate
+	if servedVariant == models.VariantCandidate {
+		analyzer, variant = e.live, models.VariantLive
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.ShadowTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var preds []mlclient.PredictionResult
+	var err error
+	if len(texts) == 1 {
+		var resp *mlclient.TextAnalysisResponse
+		if resp, err = analyzer.AnalyzeText(ctx, texts[0]); err == nil {
+			preds = []mlclient.PredictionResult{resp.Prediction}
+		}
+	} else {
+		var resp *mlclient.BatchTextAnalysisResponse
+		if resp, err = analyzer.AnalyzeBatch(ctx, texts); err == nil {
+			preds = resp.Predictions
+		}
+	}
+	perItem := int(time.Since(start).Milliseconds()) / len(texts)
+
+	shadow := make([]*models.ModelEvaluation, 0, len(checkIDs))
+	for i, checkID := range checkIDs {
+		if err != nil || i >= len(preds) {
+			failed := e.evaluation(checkID, texts[i], variant, false, mlclient.PredictionResult{}, perItem)
+			failed.DangerScore = 0
+			failed.Error = "no prediction"
+			if err != nil {
+				failed.Error = err.Error()
+			}
+			shadow = append(shadow, failed)
+			continue
+		}
+		shadow = append(shadow, e.evaluation(checkID, texts[i], variant, false, preds[i], perItem))
+	}
+
+	return shadow
+}
+
+func (e *ModelExperiment) evaluation(checkID uint, text, variant string, served bool, pred mlclient.PredictionResult, latencyMs int) *models.ModelEvaluation {
+	model := e.cfg.LiveModel
+	if variant == models.VariantCandidate {
+		model = e.cfg.CandidateModel
+	}
+
+	return &models.ModelEvaluation{
+		CheckID:     checkID,
+		Variant:     variant,
+		Model:       model,
+		Served:      served,
+		Label:       pred.Label,
+		IsScam:      pred.IsScam,
+		Confidence:  pred.Confidence,
+		DangerScore: CombinedScore(pred, text),
+		LatencyMs:   latencyMs,
+	}
+}
+
+func (e *ModelExperiment) save(ctx context.Context, evaluations []*models.ModelEvaluation) {
+	if err := e.evalRepo.Create(ctx, evaluations); err != nil {
+		log.Printf("model experiment: %v", err)
+	}
+}
+
+func userBucket(userID uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"testing"
+	"time"
+)
+
+// savedEvaluations передаёт каждый вызов Create в канал: оценки
+// сохраняются в фоне.
+type savedEvaluations struct {
+	saved chan []*models.ModelEvaluation
+}
+
+func newSavedEvaluations() *savedEvaluations {
+	return &savedEvaluations{saved: make(chan []*models.ModelEvaluation, 16)}
+}
+
+func (r *savedEvaluations) Create(ctx context.Context, evaluations []*models.ModelEvaluation) error {
+	r.saved <- evaluations
+	return nil
+}
+
+func (r *savedEvaluations) Compare(ctx context.Context, since time.Time) (*models.ModelComparisonReport, error) {
+	return &models.ModelComparisonReport{}, nil
+}
+
+func (r *savedEvaluations) next(t *testing.T) []*models.ModelEvaluation {
+	t.Helper()
+	select {
+	case evaluations := <-r.saved:
+		return evaluations
+	case <-time.After(5 * time.Second):
+		t.Fatal("evaluations were not saved")
+		return nil
+	}
+}
+
+func (r *savedEvaluations) none(t *testing.T) {
+	t.Helper()
+	select {
+	case evaluations := <-r.saved:
+		t.Fatalf("unexpected evaluations saved: %+v", evaluations)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestModelExperimentAnalyzerFor(t *testing.T) {
+	live, candidate := mlclient.NewFakeAnalyzer(), mlclient.NewFakeAnalyzer()
+
+	tests := []struct {
+		name           string
+		candidate      mlclient.Analyzer
+		percent        int
+		wantCandidates func(n int) bool
+	}{
+		{name: "no candidate", percent: 100, wantCandidates: func(n int) bool { return n == 0 }},
+		{name: "zero percent", candidate: candidate, percent: 0, wantCandidates: func(n int) bool { return n == 0 }},
+		{name: "full traffic", candidate: candidate, percent: 100, wantCandidates: func(n int) bool { return n == 1000 }},
+		{name: "percent above 100 is clamped", candidate: candidate, percent: 150, wantCandidates: func(n int) bool { return n == 1000 }},
+		{name: "partial traffic", candidate: candidate, percent: 30, wantCandidates: func(n int) bool { return n > 200 && n < 400 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := NewModelExperiment(newSavedEvaluations(), live, tt.candidate, ExperimentConfig{TrafficPercent: tt.percent})
+
+			candidates := 0
+			for userID := uint(1); userID <= 1000; userID++ {
+				analyzer, variant := experiment.AnalyzerFor(userID)
+				if variant == models.VariantCandidate {
+					candidates++
+					if analyzer != tt.candidate {
+						t.Fatal("candidate variant served by another analyzer")
+					}
+				}
+
+				// Группа пользователя не меняется между запросами
+				if _, again := experiment.AnalyzerFor(userID); again != variant {
+					t.Fatalf("user %d moved from %s to %s", userID, variant, again)
+				}
+			}
+			if !tt.wantCandidates(candidates) {
+				t.Fatalf("%d of 1000 users got the candidate", candidates)
+			}
+		})
+	}
+}
+
+func TestModelExperimentRecord(t *testing.T) {
+	const text = "Ваша карта заблокирована, перейдите по ссылке"
+	served := mlclient.PredictionResult{Label: mlclient.LabelPhishing, Confidence: 0.9, IsScam: true}
+
+	tests := []struct {
+		name          string
+		shadow        bool
+		variant       string
+		candidateErr  error
+		wantVariants  []string
+		wantShadowErr bool
+	}{
+		{name: "without shadow only the served prediction is saved", variant: models.VariantLive, wantVariants: []string{models.VariantLive}},
+		{name: "shadow runs the candidate for live traffic", shadow: true, variant: models.VariantLive, wantVariants: []string{models.VariantLive, models.VariantCandidate}},
+		{name: "shadow runs live for candidate traffic", shadow: true, variant: models.VariantCandidate, wantVariants: []string{models.VariantCandidate, models.VariantLive}},
+		{
+			name: "failed shadow run is saved with its error", shadow: true, variant: models.VariantLive,
+			candidateErr: errors.New("candidate is down"), wantVariants: []string{models.VariantLive, models.VariantCandidate}, wantShadowErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, candidate := mlclient.NewFakeAnalyzer(), mlclient.NewFakeAnalyzer()
+			candidate.Err = tt.candidateErr
+			repo := newSavedEvaluations()
+			experiment := NewModelExperiment(repo, live, candidate, ExperimentConfig{
+				LiveModel:      "live-model",
+				CandidateModel: "candidate-model",
+				Shadow:         tt.shadow,
+			})
+
+			experiment.Record(context.Background(), 42, text, tt.variant, served, 20*time.Millisecond)
+
+			evaluations := repo.next(t)
+			if len(evaluations) != len(tt.wantVariants) {
+				t.Fatalf("saved %d evaluations, want %d", len(evaluations), len(tt.wantVariants))
+			}
+			for i, evaluation := range evaluations {
+				if evaluation.CheckID != 42 || evaluation.Variant != tt.wantVariants[i] {
+					t.Fatalf("evaluation %d = %+v, want variant %s for check 42", i, evaluation, tt.wantVariants[i])
+				}
+				wantModel := "live-model"
+				if evaluation.Variant == models.VariantCandidate {
+					wantModel = "candidate-model"
+				}
+				if evaluation.Model != wantModel {
+					t.Fatalf("evaluation %d model = %s, want %s", i, evaluation.Model, wantModel)
+				}
+			}
+
+			first := evaluations[0]
+			if !first.Served || first.LatencyMs != 20 || first.Label != mlclient.LabelPhishing {
+				t.Fatalf("served evaluation = %+v", first)
+			}
+			if len(evaluations) == 1 {
+				return
+			}
+
+			shadow := evaluations[1]
+			if shadow.Served {
+				t.Fatal("shadow evaluation marked as served")
+			}
+			if tt.wantShadowErr {
+				if shadow.Error == "" || shadow.DangerScore != 0 {
+					t.Fatalf("failed shadow evaluation = %+v", shadow)
+				}
+			} else if shadow.Error != "" || shadow.Label == "" {
+				t.Fatalf("shadow evaluation = %+v", shadow)
+			}
+		})
+	}
+}
+
+// shortBatchAnalyzer возвращает на одно предсказание меньше, чем текстов.
+type shortBatchAnalyzer struct {
+	*mlclient.FakeAnalyzer
+}
+
+func (a shortBatchAnalyzer) AnalyzeBatch(ctx context.Context, texts []string) (*mlclient.BatchTextAnalysisResponse, error) {
+	resp, err := a.FakeAnalyzer.AnalyzeBatch(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	resp.Predictions = resp.Predictions[:len(resp.Predictions)-1]
+	return resp, nil
+}
+
+func TestModelExperimentRecordBatchShortShadow(t *testing.T) {
+	repo := newSavedEvaluations()
+	experiment := NewModelExperiment(repo, mlclient.NewFakeAnalyzer(), shortBatchAnalyzer{mlclient.NewFakeAnalyzer()}, ExperimentConfig{Shadow: true})
+
+	texts := []string{"первый", "второй"}
+	preds := []mlclient.PredictionResult{{Label: mlclient.LabelLegitimate, Confidence: 0.9}, {Label: mlclient.LabelLegitimate, Confidence: 0.8}}
+	experiment.RecordBatch(context.Background(), []uint{1, 2}, texts, models.VariantLive, preds, 10*time.Millisecond)
+
+	evaluations := repo.next(t)
+	if len(evaluations) != 4 {
+		t.Fatalf("saved %d evaluations, want 4", len(evaluations))
+	}
+	if shadow := evaluations[2]; shadow.CheckID != 1 || shadow.Error != "" {
+		t.Fatalf("first shadow evaluation = %+v", shadow)
+	}
+	if shadow := evaluations[3]; shadow.CheckID != 2 || shadow.Error != "no prediction" {
+		t.Fatalf("second shadow evaluation = %+v, want no prediction", shadow)
+	}
+}
+
+func TestModelExperimentDisabled(t *testing.T) {
+	repo := newSavedEvaluations()
+	experiment := NewModelExperiment(repo, mlclient.NewFakeAnalyzer(), nil, ExperimentConfig{Shadow: true})
+
+	experiment.Record(context.Background(), 1, "text", models.VariantLive, mlclient.PredictionResult{}, time.Millisecond)
+	repo.none(t)
+}
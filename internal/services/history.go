@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"scam-detection-backend/internal/models"
+	"time"
+)
+
+// HistoryQuery - какую страницу истории вернуть. Cursor из предыдущего
+// ответа имеет приоритет над Offset. SkipTotal отключает подсчёт общего
+// числа проверок, который на больших историях стоит дороже самой страницы.
+type HistoryQuery struct {
+	Limit     int
+	Offset    int
+	Cursor    string
+	SkipTotal bool
+}
+
+// HistoryPage - страница истории. Курсоры выдаются только при сортировке
+// по created_at; пустой курсор означает, что в эту сторону проверок нет.
+type HistoryPage struct {
+	Checks     []models.Check
+	Total      *int64
+	NextCursor string
+	PrevCursor string
+}
+
+// historyCursor - содержимое курсора. Asc фиксирует порядок, в котором
+// курсор выдан, Backward - что он указывает на предыдущую страницу.
+type historyCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+	Asc       bool      `json:"asc,omitempty"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+func encodeHistoryCursor(check models.Check, asc, backward bool) string {
+	data, _ := json.Marshal(historyCursor{CreatedAt: check.CreatedAt, ID: check.ID, Asc: asc, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeHistoryCursor(s string) (*historyCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor historyCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// History возвращает страницу проверок пользователя по фильтру. Без
+// сортировки результаты поиска упорядочены по релевантности, остальные - от
+// новых к старым.
+func (s *AnalysisService) History(ctx context.Context, userID uint, filter models.CheckHistoryFilter, query HistoryQuery) (*HistoryPage, error) {
+	if err := validateHistoryFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	page := models.CheckPage{Limit: query.Limit, Offset: query.Offset, SkipTotal: query.SkipTotal}
+	var cursor *historyCursor
+	if query.Cursor != "" {
+		var err error
+		if cursor, err = decodeHistoryCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+		if filter.Sort != models.HistorySortCreatedAt {
+			return nil, fmt.Errorf("%w: курсор применим только к сортировке по created_at", ErrInvalidCursor)
+		}
+		if cursor.Asc != filter.Asc {
+			return nil, fmt.Errorf("%w: курсор выдан для другого порядка сортировки", ErrInvalidCursor)
+		}
+
+		position := &models.CheckCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+		if cursor.Backward {
+			page.Before = position
+		} else {
+			page.After = position
+		}
+		page.Offset = 0
+	}
+
+	list, err := s.checkRepo.ListChecks(ctx, userID, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryPage{Checks: list.Checks, Total: list.Total}
+	if filter.Sort != models.HistorySortCreatedAt || len(list.Checks) == 0 {
+		return result, nil
+	}
+
+	first, last := list.Checks[0], list.Checks[len(list.Checks)-1]
+	backward := cursor != nil && cursor.Backward
+	// При движении назад страница за последней проверкой точно есть: с неё
+	// пришли; при движении вперёд то же верно для страницы перед первой
+	hasNext := list.HasMore || backward
+	hasPrev := backward && list.HasMore || !backward && (cursor != nil || query.Offset > 0)
+	if hasNext {
+		result.NextCursor = encodeHistoryCursor(last, filter.Asc, false)
+	}
+	if hasPrev {
+		result.PrevCursor = encodeHistoryCursor(first, filter.Asc, true)
+	}
+
+	return result, nil
+}
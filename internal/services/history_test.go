@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// pagedChecks отдаёт заранее заданную страницу и запоминает запрос.
+type pagedChecks struct {
+	repository.CheckRepository
+
+	list   models.CheckList
+	filter models.CheckHistoryFilter
+	page   *models.CheckPage
+}
+
+func (r *pagedChecks) ListChecks(ctx context.Context, userID uint, filter models.CheckHistoryFilter, page models.CheckPage) (*models.CheckList, error) {
+	r.filter, r.page = filter, &page
+	list := r.list
+	return &list, nil
+}
+
+func TestHistoryCursorEncoding(t *testing.T) {
+	check := models.Check{ID: 42, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)}
+
+	for _, tt := range []struct{ asc, backward bool }{{false, false}, {true, false}, {false, true}, {true, true}} {
+		cursor, err := decodeHistoryCursor(encodeHistoryCursor(check, tt.asc, tt.backward))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cursor.ID != check.ID || !cursor.CreatedAt.Equal(check.CreatedAt) || cursor.Asc != tt.asc || cursor.Backward != tt.backward {
+			t.Fatalf("decoded %+v, want check %d at %v, asc %v, backward %v", *cursor, check.ID, check.CreatedAt, tt.asc, tt.backward)
+		}
+	}
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for name, raw := range map[string]string{
+		"not base64":      "!!!",
+		"padded base64":   base64.URLEncoding.EncodeToString([]byte(`{"t":"2026-03-01T12:00:00Z","id":1}`)),
+		"not JSON":        encode("created_at=1"),
+		"missing id":      encode(`{"t":"2026-03-01T12:00:00Z"}`),
+		"missing time":    encode(`{"id":1}`),
+		"wrong time type": encode(`{"t":1,"id":1}`),
+	} {
+		if _, err := decodeHistoryCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: decode error = %v, want %v", name, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestAnalysisServiceHistoryCursors(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := models.Check{ID: 5, CreatedAt: created}
+	last := models.Check{ID: 4, CreatedAt: created}
+	forward := encodeHistoryCursor(first, false, false)
+	backward := encodeHistoryCursor(first, false, true)
+
+	tests := []struct {
+		name       string
+		query      HistoryQuery
+		hasMore    bool
+		wantAfter  bool
+		wantBefore bool
+		wantNext   bool
+		wantPrev   bool
+	}{
+		{name: "first page", query: HistoryQuery{Limit: 2}, hasMore: true, wantNext: true},
+		{name: "only page", query: HistoryQuery{Limit: 2}},
+		{name: "page by offset", query: HistoryQuery{Limit: 2, Offset: 2}, wantPrev: true},
+		{name: "forward with more", query: HistoryQuery{Limit: 2, Cursor: forward}, hasMore: true, wantAfter: true, wantNext: true, wantPrev: true},
+		{name: "forward to the end", query: HistoryQuery{Limit: 2, Cursor: forward}, wantAfter: true, wantPrev: true},
+		{name: "backward with more", query: HistoryQuery{Limit: 2, Cursor: backward}, hasMore: true, wantBefore: true, wantNext: true, wantPrev: true},
+		{name: "backward to the start", query: HistoryQuery{Limit: 2, Cursor: backward}, wantBefore: true, wantNext: true},
+		{
+			// Курсор заменяет смещение
+			name: "cursor ignores offset", query: HistoryQuery{Limit: 2, Offset: 10, Cursor: forward},
+			wantAfter: true, wantPrev: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := &pagedChecks{list: models.CheckList{Checks: []models.Check{first, last}, HasMore: tt.hasMore}}
+			service := NewAnalysisService(checks, nil, nil, nil, false)
+
+			result, err := service.History(context.Background(), 1, models.CheckHistoryFilter{}, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			page := checks.page
+			if (page.After != nil) != tt.wantAfter || (page.Before != nil) != tt.wantBefore {
+				t.Fatalf("page after %v, before %v; want %v, %v", page.After, page.Before, tt.wantAfter, tt.wantBefore)
+			}
+			if tt.query.Cursor != "" {
+				position := page.After
+				if position == nil {
+					position = page.Before
+				}
+				if page.Offset != 0 || position.ID != first.ID || !position.CreatedAt.Equal(created) {
+					t.Fatalf("page = %+v, want position of check %d without offset", *page, first.ID)
+				}
+			}
+
+			if (result.NextCursor != "") != tt.wantNext || (result.PrevCursor != "") != tt.wantPrev {
+				t.Fatalf("next %q, prev %q; want next %v, prev %v", result.NextCursor, result.PrevCursor, tt.wantNext, tt.wantPrev)
+			}
+			// Следующая страница начинается после последней проверки,
+			// предыдущая - перед первой
+			if tt.wantNext {
+				next, err := decodeHistoryCursor(result.NextCursor)
+				if err != nil || next.ID != last.ID || next.Backward {
+					t.Fatalf("next cursor = %+v, %v", next, err)
+				}
+			}
+			if tt.wantPrev {
+				prev, err := decodeHistoryCursor(result.PrevCursor)
+				if err != nil || prev.ID != first.ID || !prev.Backward {
+					t.Fatalf("prev cursor = %+v, %v", prev, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalysisServiceHistoryRejectsCursor(t *testing.T) {
+	check := models.Check{ID: 5, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name   string
+		filter models.CheckHistoryFilter
+		cursor string
+	}{
+		{name: "issued for descending order", filter: models.CheckHistoryFilter{Asc: true}, cursor: encodeHistoryCursor(check, false, false)},
+		{name: "issued for ascending order", cursor: encodeHistoryCursor(check, true, true)},
+		{name: "sort by score", filter: models.CheckHistoryFilter{Sort: models.HistorySortDangerScore}, cursor: encodeHistoryCursor(check, false, false)},
+		{name: "relevance", filter: models.CheckHistoryFilter{Query: "карта"}, cursor: encodeHistoryCursor(check, false, false)},
+		{name: "tampered", cursor: encodeHistoryCursor(check, false, false)[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := &pagedChecks{}
+			service := NewAnalysisService(checks, nil, nil, nil, false)
+
+			_, err := service.History(context.Background(), 1, tt.filter, HistoryQuery{Limit: 2, Cursor: tt.cursor})
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("History error = %v, want %v", err, ErrInvalidCursor)
+			}
+			if checks.page != nil {
+				t.Fatal("invalid cursor reached the repository")
+			}
+		})
+	}
+}
+
+func TestAnalysisServiceHistorySkipTotal(t *testing.T) {
+	total := int64(3)
+	for _, skip := range []bool{false, true} {
+		checks := &pagedChecks{list: models.CheckList{Checks: []models.Check{{ID: 1, CreatedAt: time.Now()}}}}
+		if !skip {
+			checks.list.Total = &total
+		}
+		service := NewAnalysisService(checks, nil, nil, nil, false)
+
+		result, err := service.History(context.Background(), 1, models.CheckHistoryFilter{}, HistoryQuery{Limit: 2, SkipTotal: skip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checks.page.SkipTotal != skip || (result.Total == nil) != skip {
+			t.Fatalf("SkipTotal %v: page %+v, total %v", skip, *checks.page, result.Total)
+		}
+	}
+}
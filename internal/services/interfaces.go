@@ -3,12 +3,13 @@ package services
 import (
 	"context"
 	"scam-detection-backend/internal/models"
+	"time"
 )
 
 type UserService interface {
 	GetByID(id uint) (*models.User, error)
 	Update(id uint, data *models.UpdateUserRequest) error
-	Delete(id uint) error
+	RequestDeletion(ctx context.Context, id uint) (time.Time, error)
 }
 
 type SessionService interface {
@@ -20,3 +21,9 @@ type SessionService interface {
 	InvalidateSession(ctx context.Context, sessionId uint) error
 	CleanupExpiredSessions(ctx context.Context) (int64, error)
 }
+
+type AuditService interface {
+	Record(ctx context.Context, eventType string, userID uint, metadata map[string]interface{})
+	ListForUser(ctx context.Context, userID uint, limit, offset int) ([]models.AuditEvent, int64, error)
+	List(ctx context.Context, filter models.AuditEventFilter, limit, offset int) ([]models.AuditEvent, int64, error)
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"scam-detection-backend/internal/crypto"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/oidc"
+	"scam-detection-backend/internal/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider      = errors.New("неизвестный провайдер")
+	ErrInvalidOIDCState     = errors.New("невалидный или просроченный state")
+	ErrIdentityAlreadyTaken = errors.New("внешний аккаунт уже привязан к другому пользователю")
+	ErrIdentityNotFound     = errors.New("внешний аккаунт не привязан")
+	ErrLastLoginMethod      = errors.New("нельзя отвязать единственный способ входа, сначала задайте пароль")
+)
+
+var usernameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+type OIDCService struct {
+	providers      map[string]*oidc.Provider
+	userRepo       repository.UserRepository
+	identityRepo   repository.IdentityRepository
+	stateRepo      repository.OIDCStateRepository
+	sessionService SessionService
+	auditService   AuditService
+}
+
+func NewOIDCService(
+	providers []*oidc.Provider,
+	userRepo repository.UserRepository,
+	identityRepo repository.IdentityRepository,
+	stateRepo repository.OIDCStateRepository,
+	sessionService SessionService,
+	auditService AuditService,
+) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	return &OIDCService{
+		providers:      byName,
+		userRepo:       userRepo,
+		identityRepo:   identityRepo,
+		stateRepo:      stateRepo,
+		sessionService: sessionService,
+		auditService:   auditService,
+	}
+}
+
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeginAuth создаёт state с PKCE verifier и nonce и возвращает URL
+// авторизации у провайдера. linkUserID != 0 означает привязку внешнего
+// аккаунта к уже вошедшему пользователю.
+func (s *OIDCService) BeginAuth(ctx context.Context, providerName string, linkUserID uint) (string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, err := oidc.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.RandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	authState := &models.OIDCAuthState{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(oidcStateTTL),
+		CreatedAt:    now,
+	}
+	if linkUserID != 0 {
+		authState.LinkUserID = &linkUserID
+	}
+
+	s.stateRepo.DeleteExpired(ctx, now)
+
+	if err := s.stateRepo.Create(ctx, authState); err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+}
+
+// CompleteAuth обменивает code на токены, проверяет id_token и находит,
+// привязывает или создаёт пользователя, после чего выдаёт обычную сессию.
+func (s *OIDCService) CompleteAuth(ctx context.Context, providerName, code, state string) (*models.User, *models.TokenPair, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+
+	authState, err := s.stateRepo.Consume(ctx, state, time.Now())
+	if err != nil || authState.Provider != providerName {
+		return nil, nil, ErrInvalidOIDCState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, authState.CodeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, authState.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.resolveUser(ctx, providerName, claims, authState.LinkUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.IsActive {
+		return nil, nil, ErrAccountDisabled
+	}
+
+	if err := restorePendingDeletion(ctx, s.userRepo, s.auditService, user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAccountDisabled
+		}
+		return nil, nil, err
+	}
+
+	sessionTokens, err := s.sessionService.GenerateSession(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditOIDCLogin, user.ID, map[string]interface{}{
+		"provider": providerName,
+	})
+
+	return user, sessionTokens, nil
+}
+
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, claims *oidc.IDTokenClaims, linkUserID *uint) (*models.User, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, providerName, claims.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity != nil {
+		if linkUserID != nil && identity.UserID != *linkUserID {
+			return nil, ErrIdentityAlreadyTaken
+		}
+		return s.userRepo.GetByID(identity.UserID)
+	}
+
+	newIdentity := &models.UserIdentity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+	}
+	if claims.Email != "" {
+		email := claims.Email
+		newIdentity.Email = &email
+	}
+
+	if linkUserID != nil {
+		return s.linkIdentity(ctx, *linkUserID, newIdentity)
+	}
+
+	// Автоматически привязываем только подтверждённый провайдером email
+	if claims.Email != "" && claims.EmailVerified {
+		existing, err := s.userRepo.GetByEmail(claims.Email)
+		if err == nil {
+			return s.linkIdentity(ctx, existing.ID, newIdentity)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	return s.createUser(ctx, claims, newIdentity)
+}
+
+func (s *OIDCService) linkIdentity(ctx context.Context, userID uint, identity *models.UserIdentity) (*models.User, error) {
+	identity.UserID = userID
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrIdentityAlreadyTaken
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditIdentityLinked, userID, map[string]interface{}{
+		"provider": identity.Provider,
+	})
+
+	return s.userRepo.GetByID(userID)
+}
+
+func (s *OIDCService) createUser(ctx context.Context, claims *oidc.IDTokenClaims, identity *models.UserIdentity) (*models.User, error) {
+	username, err := s.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пароль неизвестен пользователю: войти можно только через провайдера,
+	// пока пароль не будет задан в профиле
+	randomPassword, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := crypto.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:     username,
+		PasswordHash: hashedPassword,
+		HasPassword:  false,
+		IsActive:     true,
+	}
+	if claims.Email != "" && claims.EmailVerified {
+		email := claims.Email
+		user.Email = &email
+	}
+
+	if err := s.identityRepo.CreateWithUser(ctx, user, identity); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *OIDCService) availableUsername(claims *oidc.IDTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" && claims.Email != "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameSanitizer.ReplaceAllString(base, "")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 32 {
+		base = base[:32]
+	}
+
+	candidate := base
+	for i := 1; i <= 100; i++ {
+		_, err := s.userRepo.GetByUsername(candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	suffix, err := oidc.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + usernameSanitizer.ReplaceAllString(suffix[:8], ""), nil
+}
+
+func (s *OIDCService) ListIdentities(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+func (s *OIDCService) Unlink(ctx context.Context, userID uint, providerName string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.HasPassword {
+		count, err := s.identityRepo.CountByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	if err := s.identityRepo.Delete(ctx, userID, providerName); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrIdentityNotFound
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditIdentityUnlinked, userID, map[string]interface{}{
+		"provider": providerName,
+	})
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/oidc"
+	"scam-detection-backend/internal/oidc/oidctest"
+	"scam-detection-backend/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// memoryUsers, memoryIdentities и memoryStates хранят данные в памяти.
+// Встроенный интерфейс покрывает методы, которые тесты не вызывают.
+type memoryUsers struct {
+	repository.UserRepository
+
+	mu    sync.Mutex
+	users map[uint]*models.User
+}
+
+func newMemoryUsers(users ...*models.User) *memoryUsers {
+	repo := &memoryUsers{users: make(map[uint]*models.User)}
+	for _, user := range users {
+		repo.add(user)
+	}
+	return repo
+}
+
+func (r *memoryUsers) add(user *models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user.ID == 0 {
+		user.ID = uint(len(r.users) + 1)
+	}
+	r.users[user.ID] = user
+}
+
+func (r *memoryUsers) find(match func(*models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUsers) GetByID(id uint) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *memoryUsers) GetByUsername(username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *memoryUsers) GetByEmail(email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email != nil && *u.Email == email })
+}
+
+func (r *memoryUsers) CancelDeletion(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].DeletionScheduledAt = nil
+	return nil
+}
+
+type memoryIdentities struct {
+	users *memoryUsers
+
+	mu         sync.Mutex
+	identities []models.UserIdentity
+}
+
+func (r *memoryIdentities) Create(ctx context.Context, identity *models.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryIdentities) CreateWithUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	r.users.add(user)
+	identity.UserID = user.ID
+	return r.Create(ctx, identity)
+}
+
+func (r *memoryIdentities) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryIdentities) ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			result = append(result, identity)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryIdentities) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	identities, _ := r.ListByUser(ctx, userID)
+	return int64(len(identities)), nil
+}
+
+func (r *memoryIdentities) Delete(ctx context.Context, userID uint, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type memoryStates struct {
+	mu     sync.Mutex
+	states map[string]models.OIDCAuthState
+}
+
+func (r *memoryStates) Create(ctx context.Context, state *models.OIDCAuthState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.State] = *state
+	return nil
+}
+
+func (r *memoryStates) Consume(ctx context.Context, state string, now time.Time) (*models.OIDCAuthState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	authState, ok := r.states[state]
+	delete(r.states, state)
+	if !ok || !authState.ExpiresAt.After(now) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &authState, nil
+}
+
+func (r *memoryStates) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+type stubSessions struct {
+	SessionService
+}
+
+func (stubSessions) GenerateSession(ctx context.Context, userID uint) (*models.TokenPair, error) {
+	return &models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+type recordedAudit struct {
+	AuditService
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (a *recordedAudit) Record(ctx context.Context, eventType string, userID uint, metadata map[string]interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, eventType)
+}
+
+type oidcFixture struct {
+	service    *OIDCService
+	issuer     *oidctest.Issuer
+	users      *memoryUsers
+	identities *memoryIdentities
+	states     *memoryStates
+}
+
+func newOIDCFixture(t *testing.T, users ...*models.User) *oidcFixture {
+	t.Helper()
+
+	issuer := oidctest.NewIssuer(t, "client")
+	provider := oidc.NewProvider(oidc.Config{Name: "test", Issuer: issuer.URL, ClientID: "client", RedirectURL: "http://app/callback"})
+
+	f := &oidcFixture{
+		issuer: issuer,
+		users:  newMemoryUsers(users...),
+		states: &memoryStates{states: make(map[string]models.OIDCAuthState)},
+	}
+	f.identities = &memoryIdentities{users: f.users}
+	f.service = NewOIDCService([]*oidc.Provider{provider}, f.users, f.identities, f.states, stubSessions{}, &recordedAudit{})
+	return f
+}
+
+// login проходит весь поток: BeginAuth, вход у провайдера и CompleteAuth.
+func (f *oidcFixture) login(t *testing.T, identity oidctest.Identity, linkUserID uint) (*models.User, error) {
+	t.Helper()
+
+	authURL, err := f.service.BeginAuth(context.Background(), "test", linkUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := f.issuer.Authorize(authURL, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, _, err := f.service.CompleteAuth(context.Background(), "test", code, state)
+	return user, err
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestOIDCCompleteAuthRejectsBadState(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		state func(f *oidcFixture) string
+	}{
+		{name: "unknown state", state: func(f *oidcFixture) string { return "forged" }},
+		{name: "expired state", state: func(f *oidcFixture) string {
+			f.states.Create(ctx, &models.OIDCAuthState{State: "old", Provider: "test", ExpiresAt: time.Now().Add(-time.Second)})
+			return "old"
+		}},
+		{name: "state of another provider", state: func(f *oidcFixture) string {
+			f.states.Create(ctx, &models.OIDCAuthState{State: "other", Provider: "other", ExpiresAt: time.Now().Add(time.Minute)})
+			return "other"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			_, _, err := f.service.CompleteAuth(ctx, "test", "code", tt.state(f))
+			if !errors.Is(err, ErrInvalidOIDCState) {
+				t.Fatalf("CompleteAuth error = %v, want %v", err, ErrInvalidOIDCState)
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteAuthStateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, err := f.service.BeginAuth(ctx, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := f.issuer.Authorize(authURL, oidctest.Identity{Subject: "sub", PreferredUsername: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := f.service.CompleteAuth(ctx, "test", code, state); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := f.service.CompleteAuth(ctx, "test", code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("replayed CompleteAuth error = %v, want %v", err, ErrInvalidOIDCState)
+	}
+}
+
+func TestOIDCCompleteAuthRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity oidctest.Identity
+		wantErr  error
+	}{
+		{name: "nonce mismatch", identity: oidctest.Identity{Subject: "sub", Nonce: "replayed"}, wantErr: oidc.ErrNonceMismatch},
+		{name: "expired id_token", identity: oidctest.Identity{Subject: "sub", ExpiresIn: -5 * time.Minute}, wantErr: jwt.ErrTokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			_, err := f.login(t, tt.identity, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteAuth error = %v, want %v", err, tt.wantErr)
+			}
+			if identities, _ := f.identities.ListByUser(context.Background(), 1); len(identities) != 0 {
+				t.Fatalf("identity created for rejected token: %+v", identities)
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteAuthResolvesUser(t *testing.T) {
+	existing := func() *models.User {
+		return &models.User{ID: 7, Username: "bob", Email: stringPtr("bob@example.com"), HasPassword: true, IsActive: true}
+	}
+
+	tests := []struct {
+		name       string
+		identity   oidctest.Identity
+		wantUserID uint
+		wantNew    bool
+	}{
+		{
+			name:       "verified email links to existing account",
+			identity:   oidctest.Identity{Subject: "sub-1", Email: "bob@example.com", EmailVerified: true},
+			wantUserID: 7,
+		},
+		{
+			name:     "unverified email creates a new account",
+			identity: oidctest.Identity{Subject: "sub-2", Email: "bob@example.com", PreferredUsername: "bob"},
+			wantNew:  true,
+		},
+		{
+			name:     "unknown email creates a new account",
+			identity: oidctest.Identity{Subject: "sub-3", Email: "carol@example.com", EmailVerified: true},
+			wantNew:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t, existing())
+
+			user, err := f.login(t, tt.identity, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantNew {
+				if user.ID == 7 || user.HasPassword {
+					t.Fatalf("expected a new passwordless user, got %+v", user)
+				}
+				if user.Username == "bob" {
+					t.Fatal("new user took the username of an existing one")
+				}
+			} else if user.ID != tt.wantUserID {
+				t.Fatalf("user id = %d, want %d", user.ID, tt.wantUserID)
+			}
+
+			identity, err := f.identities.GetByProviderSubject(context.Background(), "test", tt.identity.Subject)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity.UserID != user.ID {
+				t.Fatalf("identity linked to %d, want %d", identity.UserID, user.ID)
+			}
+
+			// Повторный вход находит пользователя по привязке
+			again, err := f.login(t, tt.identity, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.ID != user.ID {
+				t.Fatalf("second login resolved user %d, want %d", again.ID, user.ID)
+			}
+		})
+	}
+}
+
+func TestOIDCLinkToCurrentUser(t *testing.T) {
+	f := newOIDCFixture(t,
+		&models.User{ID: 1, Username: "alice", HasPassword: true, IsActive: true},
+		&models.User{ID: 2, Username: "bob", HasPassword: true, IsActive: true},
+	)
+
+	user, err := f.login(t, oidctest.Identity{Subject: "sub"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 {
+		t.Fatalf("linked to user %d, want 1", user.ID)
+	}
+
+	// Тот же внешний аккаунт нельзя привязать ко второму пользователю
+	if _, err := f.login(t, oidctest.Identity{Subject: "sub"}, 2); !errors.Is(err, ErrIdentityAlreadyTaken) {
+		t.Fatalf("second link error = %v, want %v", err, ErrIdentityAlreadyTaken)
+	}
+}
+
+func TestOIDCUnlinkKeepsLastLoginMethod(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	user, err := f.login(t, oidctest.Identity{Subject: "sub", PreferredUsername: "alice"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.service.Unlink(context.Background(), user.ID, "test"); !errors.Is(err, ErrLastLoginMethod) {
+		t.Fatalf("Unlink error = %v, want %v", err, ErrLastLoginMethod)
+	}
+}
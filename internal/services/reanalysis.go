@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"sync"
+	"time"
+)
+
+const reanalysisBatchSize = 50
+
+var ErrReanalysisInProgress = errors.New("повторный анализ уже выполняется")
+
+// ReanalysisService пересчитывает исторические проверки текущей моделью.
+// Новая оценка сохраняется как следующая ревизия проверки, прежние остаются
+// в истории. Одновременно выполняется не больше одной задачи.
+type ReanalysisService struct {
+	checkRepo repository.CheckRepository
+	jobRepo   repository.ReanalysisJobRepository
+	analyzer  mlclient.Analyzer
+	bus       events.Bus
+
+	mu      sync.Mutex
+	running bool
+}
+
+func NewReanalysisService(checkRepo repository.CheckRepository, jobRepo repository.ReanalysisJobRepository, analyzer mlclient.Analyzer, bus events.Bus) *ReanalysisService {
+	return &ReanalysisService{
+		checkRepo: checkRepo,
+		jobRepo:   jobRepo,
+		analyzer:  analyzer,
+		bus:       bus,
+	}
+}
+
+// RecoverInterrupted закрывает задачи, оставшиеся незавершёнными после
+// перезапуска сервера.
+func (s *ReanalysisService) RecoverInterrupted(ctx context.Context) {
+	if n, err := s.jobRepo.FailInterrupted(ctx, time.Now()); err != nil {
+		log.Printf("reanalysis: %v", err)
+	} else if n > 0 {
+		log.Printf("reanalysis: прервано перезапуском задач: %d", n)
+	}
+}
+
+func (s *ReanalysisService) Start(ctx context.Context, adminID uint, filter models.CheckFilter) (*models.ReanalysisJob, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrReanalysisInProgress
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	job, err := s.createJob(ctx, adminID, filter)
+	if err != nil {
+		s.finish()
+		return nil, err
+	}
+
+	go func() {
+		defer s.finish()
+		s.run(context.Background(), job, filter)
+	}()
+
+	return job, nil
+}
+
+func (s *ReanalysisService) Get(ctx context.Context, id uint) (*models.ReanalysisJob, error) {
+	return s.jobRepo.GetByID(ctx, id)
+}
+
+func (s *ReanalysisService) List(ctx context.Context, limit, offset int) ([]models.ReanalysisJob, int64, error) {
+	return s.jobRepo.List(ctx, limit, offset)
+}
+
+func (s *ReanalysisService) createJob(ctx context.Context, adminID uint, filter models.CheckFilter) (*models.ReanalysisJob, error) {
+	total, err := s.checkRepo.CountForReanalysis(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && total > int64(filter.Limit) {
+		total = int64(filter.Limit)
+	}
+
+	rawFilter, _ := json.Marshal(filter)
+	job := &models.ReanalysisJob{
+		Status:    models.ReanalysisPending,
+		Filter:    string(rawFilter),
+		CreatedBy: adminID,
+		Total:     total,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *ReanalysisService) finish() {
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+}
+
+func (s *ReanalysisService) run(ctx context.Context, job *models.ReanalysisJob, filter models.CheckFilter) {
+	startedAt := time.Now()
+	job.Status = models.ReanalysisRunning
+	job.StartedAt = &startedAt
+	s.saveJob(ctx, job)
+
+	err := s.process(ctx, job, filter)
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = models.ReanalysisCompleted
+	if err != nil {
+		job.Status = models.ReanalysisFailed
+		job.Error = err.Error()
+	}
+	s.saveJob(ctx, job)
+}
+
+func (s *ReanalysisService) process(ctx context.Context, job *models.ReanalysisJob, filter models.CheckFilter) error {
+	var afterID uint
+	for filter.Limit <= 0 || job.Processed+job.Failed < int64(filter.Limit) {
+		batchSize := reanalysisBatchSize
+		if filter.Limit > 0 {
+			batchSize = min(batchSize, filter.Limit-int(job.Processed+job.Failed))
+		}
+
+		checks, err := s.checkRepo.ListForReanalysis(ctx, filter, afterID, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(checks) == 0 {
+			return nil
+		}
+		afterID = checks[len(checks)-1].ID
+
+		texts := make([]string, len(checks))
+		for i, check := range checks {
+			texts[i] = check.Content
+		}
+
+		result, err := s.analyzer.AnalyzeBatch(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("ML service: %w", err)
+		}
+		if len(result.Predictions) != len(checks) {
+			return fmt.Errorf("ML service returned %d predictions for %d texts", len(result.Predictions), len(checks))
+		}
+
+		for i, check := range checks {
+			pred := result.Predictions[i]
+			dangerScore := CombinedScore(pred, check.Content)
+			jobID := job.ID
+
+			revision := &models.CheckRevision{
+				CheckID:      check.ID,
+				DangerScore:  dangerScore,
+				DangerLevel:  DangerLevel(dangerScore),
+				ModelName:    pred.ModelName,
+				ModelVersion: pred.ModelVersion,
+				Source:       models.RevisionSourceReanalysis,
+				JobID:        &jobID,
+			}
+
+			if err := s.checkRepo.ApplyRevision(ctx, revision, PredictionDetails(check.ID, pred)); err != nil {
+				log.Printf("reanalysis: проверка %d: %v", check.ID, err)
+				job.Failed++
+				continue
+			}
+
+			s.bus.Publish(ctx, events.CheckRevisedBy(check.UserID, revision))
+
+			job.Processed++
+			job.ModelName, job.ModelVersion = pred.ModelName, pred.ModelVersion
+		}
+
+		s.saveJob(ctx, job)
+	}
+
+	return nil
+}
+
+func (s *ReanalysisService) saveJob(ctx context.Context, job *models.ReanalysisJob) {
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("reanalysis: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const rescoreBatchSize = 50
+
+// RescoreJob пересчитывает проверки, оценённые только правилами во время
+// недоступности ML сервиса, как только сервис снова отвечает.
+type RescoreJob struct {
+	checkRepo repository.CheckRepository
+	analyzer  mlclient.Analyzer
+	bus       events.Bus
+	interval  time.Duration
+}
+
+func NewRescoreJob(checkRepo repository.CheckRepository, analyzer mlclient.Analyzer, bus events.Bus, interval time.Duration) *RescoreJob {
+	return &RescoreJob{
+		checkRepo: checkRepo,
+		analyzer:  analyzer,
+		bus:       bus,
+		interval:  interval,
+	}
+}
+
+func (j *RescoreJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if rescored, err := j.RescoreDegraded(ctx); err != nil {
+			log.Printf("rescore: %v", err)
+		} else if rescored > 0 {
+			log.Printf("rescore: пересчитано проверок: %d", rescored)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RescoreDegraded обрабатывает очередь, пока она не опустеет или ML сервис
+// снова не откажет. Недоступный сервис не считается ошибкой.
+func (j *RescoreJob) RescoreDegraded(ctx context.Context) (int, error) {
+	if _, err := j.analyzer.HealthCheck(ctx); err != nil {
+		return 0, nil
+	}
+
+	rescored := 0
+	for {
+		checks, err := j.checkRepo.ListDegradedChecks(ctx, rescoreBatchSize)
+		if err != nil {
+			return rescored, err
+		}
+		if len(checks) == 0 {
+			return rescored, nil
+		}
+
+		for _, check := range checks {
+			result, err := j.analyzer.AnalyzeText(ctx, check.Content)
+			if err != nil {
+				return rescored, nil
+			}
+
+			dangerScore := CombinedScore(result.Prediction, check.Content)
+			details := PredictionDetails(check.ID, result.Prediction)
+
+			if err := j.checkRepo.ApplyRescore(ctx, check.ID, dangerScore, DangerLevel(dangerScore), result.Prediction.ModelName, result.Prediction.ModelVersion, details); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return rescored, err
+			}
+			rescored++
+
+			check.Degraded = false
+			check.DangerScore, check.DangerLevel = dangerScore, DangerLevel(dangerScore)
+			check.ModelName, check.ModelVersion = result.Prediction.ModelName, result.Prediction.ModelVersion
+			// Проверка уже завершена и вебхуки о ней отправлены: пересчёт
+			// публикуется отдельным событием, а не повторным check.status
+			j.bus.Publish(ctx, events.CheckRescoredBy(&check))
+		}
+
+		if len(checks) < rescoreBatchSize {
+			return rescored, nil
+		}
+	}
+}
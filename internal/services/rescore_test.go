@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"testing"
+)
+
+// degradedChecks - очередь пересчёта в памяти: ApplyRescore снимает пометку
+// degraded, и проверка больше не возвращается.
+type degradedChecks struct {
+	repository.CheckRepository
+	checks []models.Check
+}
+
+func (r *degradedChecks) ListDegradedChecks(ctx context.Context, limit int) ([]models.Check, error) {
+	var degraded []models.Check
+	for _, check := range r.checks {
+		if check.Degraded && len(degraded) < limit {
+			degraded = append(degraded, check)
+		}
+	}
+	return degraded, nil
+}
+
+func (r *degradedChecks) ApplyRescore(ctx context.Context, id uint, dangerScore float64, dangerLevel, modelName, modelVersion string, details []*models.CheckDetail) error {
+	for i := range r.checks {
+		if r.checks[i].ID == id {
+			r.checks[i].Degraded = false
+			r.checks[i].DangerScore, r.checks[i].DangerLevel = dangerScore, dangerLevel
+		}
+	}
+	return nil
+}
+
+func TestRescoreDegradedSkipsWebhooks(t *testing.T) {
+	checks := &degradedChecks{checks: []models.Check{{
+		ID:          7,
+		UserID:      1,
+		Content:     "Ваша карта заблокирована, назовите CVV",
+		Status:      "completed",
+		Degraded:    true,
+		DangerScore: 0.3,
+		DangerLevel: "medium",
+		ModelName:   models.RulesModelName,
+	}}}
+
+	hub := events.NewHub(0)
+	webhooks := NewWebhookService(idleWebhooks{}, WebhookConfig{})
+	hub.AddListener(webhooks)
+	sub := hub.Subscribe(1)
+	defer sub.Close()
+
+	job := NewRescoreJob(checks, mlclient.NewFakeAnalyzer(), hub, 0)
+	rescored, err := job.RescoreDegraded(context.Background())
+	if err != nil || rescored != 1 {
+		t.Fatalf("RescoreDegraded = %d, %v; want 1, nil", rescored, err)
+	}
+
+	select {
+	case event := <-sub.C:
+		data, ok := event.Data.(events.CheckRescoredData)
+		if event.Type != events.CheckRescored || !ok {
+			t.Fatalf("event = %s %T, want %s", event.Type, event.Data, events.CheckRescored)
+		}
+		if data.CheckID != 7 || data.DangerScore <= 0.3 || data.ModelName != "fake" {
+			t.Fatalf("rescored data = %+v", data)
+		}
+	default:
+		t.Fatal("rescore published no event")
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("rescore published %d extra events", len(sub.C))
+	}
+
+	// Завершение проверки уже отправлено в вебхуки, пересчёт их не повторяет
+	if len(webhooks.queue) != 0 || webhooks.DroppedEvents() != 0 {
+		t.Fatalf("webhooks got the rescore: queued %d, dropped %d", len(webhooks.queue), webhooks.DroppedEvents())
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"scam-detection-backend/internal/cache"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CachedAnalyzer кэширует ML предсказания по нормализованному тексту.
+// Ключ включает версию модели и версию правил, поэтому после переобучения
+// или изменения правил старые записи просто перестают находиться. Если
+// версию модели узнать нельзя или кэш недоступен, запрос идёт в ML сервис.
+type CachedAnalyzer struct {
+	analyzer mlclient.Analyzer
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+var _ mlclient.Analyzer = (*CachedAnalyzer)(nil)
+
+func NewCachedAnalyzer(analyzer mlclient.Analyzer, c cache.Cache, ttl time.Duration) *CachedAnalyzer {
+	return &CachedAnalyzer{
+		analyzer: analyzer,
+		cache:    c,
+		ttl:      ttl,
+	}
+}
+
+func (a *CachedAnalyzer) AnalyzeText(ctx context.Context, text string) (*mlclient.TextAnalysisResponse, error) {
+	version := a.modelVersion(ctx)
+	if version == "" {
+		return a.analyzer.AnalyzeText(ctx, text)
+	}
+
+	key := ResultCacheKey(text, version)
+	if pred, ok := a.lookup(ctx, key); ok {
+		return &mlclient.TextAnalysisResponse{
+			Success:    true,
+			Prediction: pred,
+		}, nil
+	}
+
+	result, err := a.analyzer.AnalyzeText(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	a.store(ctx, key, result.Prediction)
+	return result, nil
+}
+
+// AnalyzeBatch отправляет в ML сервис только тексты, которых нет в кэше.
+func (a *CachedAnalyzer) AnalyzeBatch(ctx context.Context, texts []string) (*mlclient.BatchTextAnalysisResponse, error) {
+	version := a.modelVersion(ctx)
+	if version == "" {
+		return a.analyzer.AnalyzeBatch(ctx, texts)
+	}
+
+	predictions := make([]mlclient.PredictionResult, len(texts))
+	keys := make([]string, len(texts))
+	var missTexts []string
+	var missIdxs []int
+
+	for i, text := range texts {
+		keys[i] = ResultCacheKey(text, version)
+		if pred, ok := a.lookup(ctx, keys[i]); ok {
+			predictions[i] = pred
+			continue
+		}
+		missTexts = append(missTexts, text)
+		missIdxs = append(missIdxs, i)
+	}
+
+	response := &mlclient.BatchTextAnalysisResponse{Success: true, Predictions: predictions}
+	if len(missTexts) == 0 {
+		return response, nil
+	}
+
+	result, err := a.analyzer.AnalyzeBatch(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, idx := range missIdxs {
+		if j >= len(result.Predictions) {
+			break
+		}
+		predictions[idx] = result.Predictions[j]
+		a.store(ctx, keys[idx], result.Predictions[j])
+	}
+	response.ProcessingTime = result.ProcessingTime
+
+	return response, nil
+}
+
+func (a *CachedAnalyzer) HealthCheck(ctx context.Context) (*mlclient.HealthResponse, error) {
+	return a.analyzer.HealthCheck(ctx)
+}
+
+func (a *CachedAnalyzer) modelVersion(ctx context.Context) string {
+	versioner, ok := a.analyzer.(mlclient.ModelVersioner)
+	if !ok {
+		return ""
+	}
+
+	version, err := versioner.ModelVersion(ctx)
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
+func (a *CachedAnalyzer) lookup(ctx context.Context, key string) (mlclient.PredictionResult, bool) {
+	var pred mlclient.PredictionResult
+
+	raw, ok, err := a.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("result cache: %v", err)
+		return pred, false
+	}
+	if !ok || json.Unmarshal(raw, &pred) != nil {
+		return pred, false
+	}
+
+	pred.Cached = true
+	return pred, true
+}
+
+func (a *CachedAnalyzer) store(ctx context.Context, key string, pred mlclient.PredictionResult) {
+	pred.Cached = false
+
+	raw, err := json.Marshal(pred)
+	if err != nil {
+		return
+	}
+	if err := a.cache.Set(ctx, key, raw, a.ttl); err != nil {
+		log.Printf("result cache: %v", err)
+	}
+}
+
+// ResultCacheKey - SHA-256 от нормализованного текста, версии модели и
+// версии правил.
+func ResultCacheKey(text, modelVersion string) string {
+	h := sha256.New()
+	h.Write([]byte(NormalizeText(text)))
+	h.Write([]byte{0})
+	h.Write([]byte(modelVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(models.RulesModelVersion))
+	return "analysis:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// NormalizeText сводит к одному виду варианты одного и того же сообщения:
+// регистр, пробелы, "ё" и невидимые символы, которыми рассылки обходят
+// фильтры.
+func NormalizeText(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\u2060' || r == '\ufeff':
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == 'ё':
+			r = 'е'
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scam-detection-backend/internal/cache"
+	"scam-detection-backend/internal/mlclient"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingAnalyzer запоминает тексты каждого пакетного запроса.
+type recordingAnalyzer struct {
+	*mlclient.FakeAnalyzer
+
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func newRecordingAnalyzer() *recordingAnalyzer {
+	return &recordingAnalyzer{FakeAnalyzer: mlclient.NewFakeAnalyzer()}
+}
+
+func (a *recordingAnalyzer) AnalyzeBatch(ctx context.Context, texts []string) (*mlclient.BatchTextAnalysisResponse, error) {
+	a.mu.Lock()
+	a.batches = append(a.batches, slices.Clone(texts))
+	a.mu.Unlock()
+	return a.FakeAnalyzer.AnalyzeBatch(ctx, texts)
+}
+
+// brokenCache - недоступное хранилище.
+type brokenCache struct{}
+
+func (brokenCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (brokenCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+// unversionedAnalyzer скрывает ModelVersion анализатора.
+type unversionedAnalyzer struct {
+	mlclient.Analyzer
+}
+
+func TestCachedAnalyzerText(t *testing.T) {
+	ctx := context.Background()
+	fake := mlclient.NewFakeAnalyzer()
+	analyzer := NewCachedAnalyzer(fake, cache.NewMemory(100), time.Hour)
+
+	first, err := analyzer.AnalyzeText(ctx, "Ваша карта заблокирована")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Prediction.Cached {
+		t.Fatal("first request marked as cached")
+	}
+
+	// Тот же текст с другим регистром и пробелами - попадание в кэш
+	second, err := analyzer.AnalyzeText(ctx, "  ВАША карта\tзаблокирована ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Prediction.Cached || second.Prediction.Label != first.Prediction.Label {
+		t.Fatalf("second prediction = %+v, want cached %s", second.Prediction, first.Prediction.Label)
+	}
+	if fake.TextCalls != 1 {
+		t.Fatalf("ML service called %d times, want 1", fake.TextCalls)
+	}
+
+	// Новая версия модели - промах
+	fake.Health.Version = "0.0.1"
+	third, err := analyzer.AnalyzeText(ctx, "Ваша карта заблокирована")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Prediction.Cached || fake.TextCalls != 2 {
+		t.Fatalf("prediction after model update = %+v, calls = %d", third.Prediction, fake.TextCalls)
+	}
+}
+
+func TestCachedAnalyzerTTL(t *testing.T) {
+	ctx := context.Background()
+	fake := mlclient.NewFakeAnalyzer()
+	analyzer := NewCachedAnalyzer(fake, cache.NewMemory(100), 20*time.Millisecond)
+
+	analyzer.AnalyzeText(ctx, "текст")
+	time.Sleep(50 * time.Millisecond)
+	resp, err := analyzer.AnalyzeText(ctx, "текст")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Prediction.Cached || fake.TextCalls != 2 {
+		t.Fatalf("expired entry served from cache: %+v, calls = %d", resp.Prediction, fake.TextCalls)
+	}
+}
+
+func TestCachedAnalyzerBatchPartialMiss(t *testing.T) {
+	ctx := context.Background()
+	fake := newRecordingAnalyzer()
+	analyzer := NewCachedAnalyzer(fake, cache.NewMemory(100), time.Hour)
+
+	if _, err := analyzer.AnalyzeBatch(ctx, []string{"первый", "перейдите по ссылке"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := analyzer.AnalyzeBatch(ctx, []string{"новый", "первый", "ещё один", "перейдите по ссылке"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := fake.batches[len(fake.batches)-1], []string{"новый", "ещё один"}; !slices.Equal(got, want) {
+		t.Fatalf("sent %q to ML service, want only misses %q", got, want)
+	}
+
+	wantCached := []bool{false, true, false, true}
+	for i, pred := range resp.Predictions {
+		if pred.Cached != wantCached[i] {
+			t.Errorf("prediction %d cached = %v, want %v", i, pred.Cached, wantCached[i])
+		}
+	}
+	if !resp.Predictions[3].IsScam || resp.Predictions[1].IsScam {
+		t.Fatalf("predictions mixed up between texts: %+v", resp.Predictions)
+	}
+
+	// Всё в кэше - ML сервис не вызывается
+	calls := len(fake.batches)
+	if _, err := analyzer.AnalyzeBatch(ctx, []string{"новый", "первый"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.batches) != calls {
+		t.Fatal("ML service called for a fully cached batch")
+	}
+}
+
+func TestCachedAnalyzerBypass(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		analyzer func(fake *mlclient.FakeAnalyzer) mlclient.Analyzer
+		cache    cache.Cache
+	}{
+		{
+			name:     "cache unavailable",
+			analyzer: func(fake *mlclient.FakeAnalyzer) mlclient.Analyzer { return fake },
+			cache:    brokenCache{},
+		},
+		{
+			name:     "model version unknown",
+			analyzer: func(fake *mlclient.FakeAnalyzer) mlclient.Analyzer { return unversionedAnalyzer{fake} },
+			cache:    cache.NewMemory(100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := mlclient.NewFakeAnalyzer()
+			analyzer := NewCachedAnalyzer(tt.analyzer(fake), tt.cache, time.Hour)
+
+			for range 2 {
+				resp, err := analyzer.AnalyzeText(ctx, "текст")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Prediction.Cached {
+					t.Fatal("prediction served from cache")
+				}
+			}
+			if fake.TextCalls != 2 {
+				t.Fatalf("ML service called %d times, want 2", fake.TextCalls)
+			}
+		})
+	}
+}
+
+func TestPredictionDetailsCacheHit(t *testing.T) {
+	ctx := context.Background()
+	analyzer := NewCachedAnalyzer(mlclient.NewFakeAnalyzer(), cache.NewMemory(100), time.Hour)
+
+	hasCacheHit := func(pred mlclient.PredictionResult) bool {
+		for _, detail := range PredictionDetails(1, pred) {
+			if detail.FeatureName == "cache_hit" {
+				return detail.FeatureValue == "true" && detail.ModelName == pred.ModelName
+			}
+		}
+		return false
+	}
+
+	miss, _ := analyzer.AnalyzeText(ctx, "текст")
+	if hasCacheHit(miss.Prediction) {
+		t.Fatal("cache_hit detail on a fresh prediction")
+	}
+
+	hit, _ := analyzer.AnalyzeText(ctx, "текст")
+	if !hasCacheHit(hit.Prediction) {
+		t.Fatal("no cache_hit detail on a cached prediction")
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"encoding/json"
+	"scam-detection-backend/internal/mlclient"
+	"scam-detection-backend/internal/models"
+	"sort"
+	"strings"
+)
+
+// Вес ML предсказания в итоговой оценке; остальное - вклад правил.
+const mlScoreWeight = 0.7
+
+// degradedConfidenceFactor занижает уверенность вердикта без ML: правила
+// ловят лишь часть мошеннических текстов.
+const degradedConfidenceFactor = 0.6
+
+// CombinedScore объединяет предсказание модели с оценкой по правилам.
+func CombinedScore(pred mlclient.PredictionResult, text string) float64 {
+	var dangerScore float64
+	if pred.IsScam {
+		dangerScore = pred.Confidence
+	} else {
+		dangerScore = 1.0 - pred.Confidence
+	}
+
+	dangerScore = dangerScore*mlScoreWeight + RuleScore(text)*(1-mlScoreWeight)
+	if dangerScore > 1.0 {
+		dangerScore = 1.0
+	}
+
+	return dangerScore
+}
+
+// RulePrediction строит вердикт только по правилам, когда ML сервис
+// недоступен.
+func RulePrediction(ruleScore float64) mlclient.PredictionResult {
+	if ruleScore >= 0.5 {
+		return mlclient.PredictionResult{
+			Label:      "phishing",
+			Confidence: ruleScore * degradedConfidenceFactor,
+			IsScam:     true,
+		}
+	}
+
+	return mlclient.PredictionResult{
+		Label:      "legitimate",
+		Confidence: (1.0 - ruleScore) * degradedConfidenceFactor,
+		IsScam:     false,
+	}
+}
+
+// PredictionDetails превращает ML предсказание в детали проверки: итоговое
+// (ml_prediction) и, для ансамбля, ответ каждой модели (model_prediction).
+func PredictionDetails(checkID uint, pred mlclient.PredictionResult) []*models.CheckDetail {
+	detailValue, _ := json.Marshal(map[string]interface{}{
+		"label":   pred.Label,
+		"is_scam": pred.IsScam,
+	})
+
+	details := []*models.CheckDetail{{
+		CheckID:         checkID,
+		FeatureName:     "ml_prediction",
+		FeatureValue:    string(detailValue),
+		ConfidenceScore: pred.Confidence,
+		ModelName:       pred.ModelName,
+		ModelVersion:    pred.ModelVersion,
+	}}
+
+	for _, m := range pred.Models {
+		modelValue, _ := json.Marshal(m)
+		details = append(details, &models.CheckDetail{
+			CheckID:         checkID,
+			FeatureName:     "model_prediction",
+			FeatureValue:    string(modelValue),
+			ConfidenceScore: m.Confidence,
+			ModelName:       m.ModelName,
+			ModelVersion:    m.ModelVersion,
+		})
+	}
+
+	// Результат взят из кэша: ML сервис для этой проверки не вызывался
+	if pred.Cached {
+		details = append(details, &models.CheckDetail{
+			CheckID:         checkID,
+			FeatureName:     "cache_hit",
+			FeatureValue:    "true",
+			ConfidenceScore: pred.Confidence,
+			ModelName:       pred.ModelName,
+			ModelVersion:    pred.ModelVersion,
+		})
+	}
+
+	return details
+}
+
+// RuleDetail - деталь проверки, оценённой только правилами.
+func RuleDetail(checkID uint, ruleScore float64, prediction mlclient.PredictionResult) *models.CheckDetail {
+	detailValue, _ := json.Marshal(map[string]interface{}{
+		"label":      prediction.Label,
+		"is_scam":    prediction.IsScam,
+		"rule_score": ruleScore,
+	})
+
+	return &models.CheckDetail{
+		CheckID:         checkID,
+		FeatureName:     "rule_prediction",
+		FeatureValue:    string(detailValue),
+		ConfidenceScore: prediction.Confidence,
+		ModelName:       models.RulesModelName,
+		ModelVersion:    models.RulesModelVersion,
+	}
+}
+
+func DangerLevel(confidence float64) string {
+	if confidence < 0.3 {
+		return "low"
+	} else if confidence < 0.6 {
+		return "medium"
+	} else if confidence < 0.85 {
+		return "high"
+	}
+	return "critical"
+}
+
+// Шаблоны правил и их вклад в оценку. Критичные - прямые запросы
+// реквизитов, остальные - типичные приёмы давления.
+var (
+	criticalPatterns = map[string]float64{
+		"cvv":                      0.4,
+		"код из смс":               0.4,
+		"код из сообщения":         0.4,
+		"назовите пароль":          0.4,
+		"введите пароль":           0.4,
+		"данные карты":             0.35,
+		"номер карты":              0.35,
+		"срок действия карты":      0.4,
+		"служба безопасности банк": 0.3,
+		"техподдержка банк":        0.3,
+		"администратор банк":       0.3,
+	}
+
+	highRiskPatterns = map[string]float64{
+		"перейдите по ссылке": 0.25,
+		"подтвердите данные":  0.25,
+		"заблокирован":        0.2,
+		"восстановление":      0.15,
+		"отправьте код":       0.25,
+		"назовите код":        0.25,
+		"переведите":          0.2,
+		"выиграли":            0.2,
+		"приз":                0.15,
+		"срочно обновить":     0.2,
+		"аккаунт удален":      0.2,
+	}
+)
+
+// MatchedRules возвращает шаблоны правил, найденные в тексте, в
+// алфавитном порядке.
+func MatchedRules(text string) []string {
+	textLower := strings.ToLower(text)
+
+	var matched []string
+	for _, patterns := range []map[string]float64{criticalPatterns, highRiskPatterns} {
+		for pattern := range patterns {
+			if strings.Contains(textLower, pattern) {
+				matched = append(matched, pattern)
+			}
+		}
+	}
+
+	sort.Strings(matched)
+	return matched
+}
+
+// RuleMatchDetails сохраняет сработавшие правила деталями rule_match, чтобы
+// статистика могла считать их в SQL. Текст проверки не меняется при
+// повторном анализе, поэтому детали пишутся только при первом.
+func RuleMatchDetails(checkID uint, text string) []*models.CheckDetail {
+	matched := MatchedRules(text)
+
+	details := make([]*models.CheckDetail, 0, len(matched))
+	for _, pattern := range matched {
+		details = append(details, &models.CheckDetail{
+			CheckID:         checkID,
+			FeatureName:     models.FeatureRuleMatch,
+			FeatureValue:    pattern,
+			ConfidenceScore: ruleWeight(pattern),
+			ModelName:       models.RulesModelName,
+			ModelVersion:    models.RulesModelVersion,
+		})
+	}
+	return details
+}
+
+func ruleWeight(pattern string) float64 {
+	if weight, ok := criticalPatterns[pattern]; ok {
+		return weight
+	}
+	return highRiskPatterns[pattern]
+}
+
+func RuleScore(text string) float64 {
+	score := 0.0
+	for _, pattern := range MatchedRules(text) {
+		score += ruleWeight(pattern)
+	}
+
+	if score > 1.0 {
+		score = 1.0
+	}
+
+	return score
+}
@@ -19,6 +19,7 @@ var (
 
 type sessionService struct {
 	sessionRepo   repository.SessionRepository
+	auditService  AuditService
 	jwtSecret     string
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
@@ -26,6 +27,7 @@ type sessionService struct {
 
 func NewSessionService(
 	sessionRepo repository.SessionRepository,
+	auditService AuditService,
 	jwtSecret, accessDur, refreshDur string,
 ) (*sessionService, error) {
 	accessExpiry, err := time.ParseDuration(accessDur)
@@ -40,6 +42,7 @@ func NewSessionService(
 
 	return &sessionService{
 		sessionRepo:   sessionRepo,
+		auditService:  auditService,
 		jwtSecret:     jwtSecret,
 		accessExpiry:  accessExpiry,
 		refreshExpiry: refreshExpiry,
@@ -109,10 +112,16 @@ func (s *sessionService) RefreshSession(ctx context.Context, refreshToken string
 
 	session, err := s.sessionRepo.GetActiveByHash(ctx, tokenHash, now)
 	if err != nil {
+		s.auditService.Record(ctx, models.AuditRefreshReuse, claims.UserID, map[string]interface{}{
+			"reason": "session_not_found_or_used",
+		})
 		return nil, ErrSessionNotFound
 	}
 
 	if session.UsedAt != nil {
+		s.auditService.Record(ctx, models.AuditRefreshReuse, claims.UserID, map[string]interface{}{
+			"session_id": session.ID,
+		})
 		return nil, ErrSessionUsed
 	}
 
@@ -124,7 +133,16 @@ func (s *sessionService) RefreshSession(ctx context.Context, refreshToken string
 		return nil, err
 	}
 
-	return s.GenerateSession(ctx, claims.UserID)
+	tokens, err := s.GenerateSession(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditTokenRefresh, claims.UserID, map[string]interface{}{
+		"previous_session_id": session.ID,
+	})
+
+	return tokens, nil
 }
 
 func (s *sessionService) InvalidateAllUserSessions(ctx context.Context, userID uint) error {
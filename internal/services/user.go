@@ -1,18 +1,31 @@
 package services
 
 import (
+	"context"
 	"scam-detection-backend/internal/models"
 	"scam-detection-backend/internal/repository"
+	"time"
 )
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	sessionService SessionService
+	accounts       *AccountGuard
+	gracePeriod    time.Duration
 }
 
-func NewUserService(userRepo repository.UserRepository) *userService {
-	return &userService{
-		userRepo: userRepo,
+func NewUserService(userRepo repository.UserRepository, sessionService SessionService, accounts *AccountGuard, gracePeriod string) (*userService, error) {
+	grace, err := time.ParseDuration(gracePeriod)
+	if err != nil {
+		return nil, err
 	}
+
+	return &userService{
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		accounts:       accounts,
+		gracePeriod:    grace,
+	}, nil
 }
 
 func (s *userService) GetByID(id uint) (*models.User, error) {
@@ -23,6 +36,39 @@ func (s *userService) Update(id uint, data *models.UpdateUserRequest) error {
 	return s.userRepo.Update(id, data)
 }
 
-func (s *userService) Delete(id uint) error {
-	return s.userRepo.Delete(id)
+// RequestDeletion помечает аккаунт на удаление через grace period и отзывает
+// все сессии и access токены. Данные стирает AccountPurgeJob, если
+// пользователь не войдёт до указанной даты.
+func (s *userService) RequestDeletion(ctx context.Context, id uint) (time.Time, error) {
+	scheduledAt := time.Now().Add(s.gracePeriod)
+
+	if err := s.userRepo.ScheduleDeletion(id, scheduledAt); err != nil {
+		return time.Time{}, err
+	}
+	s.accounts.Forget(id)
+
+	if err := s.sessionService.InvalidateAllUserSessions(ctx, id); err != nil {
+		return time.Time{}, err
+	}
+
+	return scheduledAt, nil
+}
+
+// restorePendingDeletion отменяет запланированное удаление при входе.
+// gorm.ErrRecordNotFound означает, что аккаунт уже удалён: вход нужно
+// отклонить.
+func restorePendingDeletion(ctx context.Context, userRepo repository.UserRepository, auditService AuditService, user *models.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+
+	if err := userRepo.CancelDeletion(user.ID); err != nil {
+		return err
+	}
+
+	auditService.Record(ctx, models.AuditAccountRestored, user.ID, map[string]interface{}{
+		"scheduled_at": user.DeletionScheduledAt,
+	})
+	user.DeletionScheduledAt = nil
+	return nil
 }
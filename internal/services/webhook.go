@@ -0,0 +1,520 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	webhookClaimBatch  = 100
+	webhookConcurrency = 8
+	webhookEventQueue  = 1024
+	// Ответ получателя сохраняется в журнал доставок в сокращённом виде
+	webhookMaxErrorBody = 512
+)
+
+var (
+	ErrWebhookNotFound      = errors.New("вебхук не найден")
+	ErrDeliveryNotFound     = errors.New("доставка не найдена")
+	ErrInvalidWebhookURL    = errors.New("URL вебхука должен быть абсолютным http(s) адресом")
+	ErrInvalidWebhookEvents = errors.New("неизвестное событие вебхука")
+	errPrivateAddress       = errors.New("адрес во внутренней сети запрещён")
+)
+
+var webhookEventTypes = []string{
+	models.WebhookEventCompleted,
+	models.WebhookEventHighRisk,
+	models.WebhookEventFeedback,
+}
+
+type WebhookConfig struct {
+	Timeout        time.Duration
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	PollInterval   time.Duration
+	// AllowPrivateNetworks разрешает отправку на адреса локальной сети и
+	// loopback. По умолчанию запрещено, чтобы вебхуком нельзя было
+	// обратиться к внутренним сервисам.
+	AllowPrivateNetworks bool
+}
+
+// WebhookPayload - тело запроса к получателю вебхука.
+type WebhookPayload struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// WebhookService отправляет вебхуки по событиям проверок. Доставки пишутся в
+// журнал и повторяются с экспоненциальной задержкой; после MaxAttempts
+// неудачных попыток доставка переходит в состояние dead.
+type WebhookService struct {
+	repo   repository.WebhookRepository
+	cfg    WebhookConfig
+	client *http.Client
+
+	queue chan events.Event
+	wake  chan struct{}
+	// stopped закрывается, когда Run завершается: после этого события
+	// некому разбирать
+	stopped chan struct{}
+	dropped atomic.Int64
+}
+
+var _ events.Listener = (*WebhookService)(nil)
+
+func NewWebhookService(repo repository.WebhookRepository, cfg WebhookConfig) *WebhookService {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = 30 * time.Second
+	}
+	if cfg.RetryMaxDelay < cfg.RetryBaseDelay {
+		cfg.RetryMaxDelay = cfg.RetryBaseDelay
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		// Проверяется адрес, к которому действительно идёт подключение, а
+		// не имя хоста: так не обойти запрет через DNS
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	return &WebhookService{
+		repo: repo,
+		cfg:  cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				// Без прокси: через него подключение шло бы к адресу прокси,
+				// и проверка адреса в dialer не видела бы настоящего получателя
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				MaxIdleConnsPerHost: 4,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// Редирект мог бы увести запрос на другой адрес в обход проверки URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queue:   make(chan events.Event, webhookEventQueue),
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Create регистрирует вебхук и возвращает секрет подписи. Секрет больше
+// нигде не показывается.
+func (s *WebhookService) Create(ctx context.Context, userID uint, rawURL string, eventTypes []string) (*models.Webhook, string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, "", ErrInvalidWebhookURL
+	}
+
+	var subscribed []string
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidWebhookEvents, eventType)
+		}
+		if !slices.Contains(subscribed, eventType) {
+			subscribed = append(subscribed, eventType)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil, "", ErrInvalidWebhookEvents
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", err
+	}
+	secret := "whsec_" + hex.EncodeToString(secretBytes)
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    parsed.String(),
+		Secret: secret,
+		Events: strings.Join(subscribed, ","),
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, "", err
+	}
+
+	return webhook, secret, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID, id uint) error {
+	err := s.repo.Delete(ctx, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, userID, id uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListDeliveries(ctx, id, limit, offset)
+}
+
+// SendTest отправляет тестовое событие сразу и возвращает результат попытки.
+// Неудачная тестовая доставка повторяется так же, как обычная.
+func (s *WebhookService) SendTest(ctx context.Context, userID, id uint) (*models.WebhookDelivery, error) {
+	webhook, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.newDelivery(webhook, models.WebhookEventTest, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "Тестовое событие",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateDeliveries(ctx, []*models.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+
+	delivery.Webhook = *webhook
+	s.attempt(ctx, delivery)
+	return delivery, nil
+}
+
+// Redeliver ставит доставку в очередь заново, в том числе из состояния dead.
+func (s *WebhookService) Redeliver(ctx context.Context, userID, id, deliveryID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, deliveryID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return delivery, nil
+}
+
+// HandleEvent ставит событие в очередь на разбор. Вызывается синхронно из
+// Hub.Publish, поэтому не ждёт: при переполненной очереди или после
+// остановки Run событие отбрасывается и учитывается в DroppedEvents.
+func (s *WebhookService) HandleEvent(ctx context.Context, event events.Event) {
+	if event.Type != events.CheckStatus && event.Type != events.CheckFeedback {
+		return
+	}
+
+	select {
+	case <-s.stopped:
+		s.drop(event, "сервис вебхуков остановлен")
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		s.drop(event, "очередь событий переполнена")
+	}
+}
+
+// DroppedEvents - число событий, отброшенных HandleEvent.
+func (s *WebhookService) DroppedEvents() int64 {
+	return s.dropped.Load()
+}
+
+func (s *WebhookService) drop(event events.Event, reason string) {
+	n := s.dropped.Add(1)
+	log.Printf("webhooks: %s, событие %s пользователя %d отброшено (всего отброшено: %d)", reason, event.Type, event.UserID, n)
+}
+
+// Run разбирает события в доставки и отправляет доставки, время попытки
+// которых наступило. Отмена ctx останавливает сервис.
+func (s *WebhookService) Run(ctx context.Context) {
+	defer close(s.stopped)
+	go s.consumeEvents(ctx)
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *WebhookService) consumeEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.queue:
+			if err := s.enqueue(ctx, event); err != nil {
+				log.Printf("webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// enqueue создаёт доставки для вебхуков пользователя, подписанных на
+// событие. Завершённая проверка высокого риска отправляется как
+// check.high_risk, если вебхук на него подписан, иначе как check.completed.
+func (s *WebhookService) enqueue(ctx context.Context, event events.Event) error {
+	var candidates []string
+	switch data := event.Data.(type) {
+	case events.CheckStatusData:
+		if data.Status != "completed" {
+			return nil
+		}
+		if data.DangerLevel == "high" || data.DangerLevel == "critical" {
+			candidates = append(candidates, models.WebhookEventHighRisk)
+		}
+		candidates = append(candidates, models.WebhookEventCompleted)
+	case events.CheckFeedbackData:
+		candidates = append(candidates, models.WebhookEventFeedback)
+	default:
+		return nil
+	}
+
+	webhooks, err := s.repo.ListByUser(ctx, event.UserID)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	var deliveries []*models.WebhookDelivery
+	for i := range webhooks {
+		subscribed := strings.Split(webhooks[i].Events, ",")
+		for _, eventType := range candidates {
+			if !slices.Contains(subscribed, eventType) {
+				continue
+			}
+			delivery, err := s.newDelivery(&webhooks[i], eventType, event.Data)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+			break
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	// Lease больше времени всех попыток одной выборки, чтобы доставку не
+	// взял другой экземпляр, пока эта ещё отправляется
+	lease := s.cfg.Timeout * time.Duration(webhookClaimBatch/webhookConcurrency+1)
+
+	for {
+		deliveries, err := s.repo.ClaimDueDeliveries(ctx, time.Now(), lease, webhookClaimBatch)
+		if err != nil {
+			log.Printf("webhooks: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		sem := make(chan struct{}, webhookConcurrency)
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(delivery *models.WebhookDelivery) {
+				defer func() { <-sem; wg.Done() }()
+				s.attempt(ctx, delivery)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookClaimBatch {
+			return
+		}
+	}
+}
+
+// attempt выполняет одну попытку доставки и сохраняет её результат.
+func (s *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+
+	statusCode, err := s.send(ctx, delivery)
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Webhook.ID == 0 || delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = models.DeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(s.retryDelay(delivery.Attempts))
+	}
+
+	if err := s.repo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("webhooks: %v", err)
+	}
+}
+
+func (s *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	webhook := delivery.Webhook
+	if webhook.ID == 0 {
+		return 0, errors.New("вебхук удалён")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "scam-detection-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorBody))
+		return resp.StatusCode, fmt.Errorf("получатель ответил %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxErrorBody))
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhook - HMAC-SHA256 от "<timestamp>.<body>" в hex. Получатель
+// проверяет подпись тем же секретом и отклоняет запросы со старым
+// timestamp, чтобы исключить повтор перехваченного запроса.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.cfg.RetryBaseDelay
+	for i := 1; i < attempts && delay < s.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.RetryMaxDelay)
+}
+
+func (s *WebhookService) newDelivery(webhook *models.Webhook, eventType string, data interface{}) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(WebhookPayload{Type: eventType, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func (s *WebhookService) get(ctx context.Context, userID, id uint) (*models.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"scam-detection-backend/internal/events"
+	"scam-detection-backend/internal/models"
+	"scam-detection-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// idleWebhooks - журнал доставок без доставок.
+type idleWebhooks struct {
+	repository.WebhookRepository
+}
+
+func (idleWebhooks) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func checkCompleted(userID uint) events.Event {
+	return events.Event{Type: events.CheckStatus, UserID: userID, Data: events.CheckStatusData{Status: "completed"}}
+}
+
+func TestWebhookHandleEventDropsWhenQueueIsFull(t *testing.T) {
+	service := NewWebhookService(idleWebhooks{}, WebhookConfig{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range webhookEventQueue + 3 {
+			service.HandleEvent(context.Background(), checkCompleted(1))
+		}
+		// Остальные события вебхуки не разбирают и в очередь не ставят
+		service.HandleEvent(context.Background(), events.Event{Type: events.BatchProgress, UserID: 1})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("HandleEvent blocked on a full queue")
+	}
+	if n := service.DroppedEvents(); n != 3 {
+		t.Fatalf("dropped %d events, want 3", n)
+	}
+	if len(service.queue) != webhookEventQueue {
+		t.Fatalf("queue holds %d events, want %d", len(service.queue), webhookEventQueue)
+	}
+}
+
+func TestWebhookHandleEventAfterStop(t *testing.T) {
+	service := NewWebhookService(idleWebhooks{}, WebhookConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.Run(ctx)
+
+	service.HandleEvent(context.Background(), checkCompleted(1))
+	if len(service.queue) != 0 || service.DroppedEvents() != 1 {
+		t.Fatalf("event after stop: queued %d, dropped %d", len(service.queue), service.DroppedEvents())
+	}
+}
+
+func TestWebhookSendRejectsPrivateAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name         string
+		allowPrivate bool
+		wantErr      error
+	}{
+		{name: "private networks denied", wantErr: errPrivateAddress},
+		{name: "private networks allowed", allowPrivate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewWebhookService(idleWebhooks{}, WebhookConfig{AllowPrivateNetworks: tt.allowPrivate})
+			// Прокси из окружения обошёл бы проверку адреса получателя
+			if service.client.Transport.(*http.Transport).Proxy != nil {
+				t.Fatal("webhook transport uses a proxy")
+			}
+
+			delivery := &models.WebhookDelivery{
+				ID:        1,
+				EventType: models.WebhookEventTest,
+				Payload:   "{}",
+				Webhook:   models.Webhook{ID: 1, URL: receiver.URL, Secret: "whsec_test"},
+			}
+			status, err := service.send(context.Background(), delivery)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("send error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || status != http.StatusNoContent {
+				t.Fatalf("send = %d, %v", status, err)
+			}
+		})
+	}
+}